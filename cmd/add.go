@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/generator"
+	"github.com/yggai/aigo_hotreload/project"
+	"github.com/yggai/aigo_hotreload/templates"
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// addCommand 向已有项目添加组件命令
+func (h *CommandHandler) addCommand() *Command {
+	command := newCommand("add", "<component>...", "向已有项目添加组件: "+strings.Join(generator.ComponentNames(), ", "))
+	path := command.Flags.String("path", ".", "项目目录")
+	port := command.Flags.String("port", "", "服务端口（默认读取aigo.yaml）")
+	domain := command.Flags.String("domain", "", "nginx组件使用的域名（默认读取aigo.yaml）")
+	db := command.Flags.String("db", "", "db组件使用的数据库: "+strings.Join(templates.DatabaseNames(), ", ")+"（默认沿用go.mod中的驱动）")
+	force := command.Flags.Bool("force", false, "允许覆盖被手动修改的文件")
+	overwrite := command.Flags.String("overwrite", tools.DefaultOverwritePolicy(), "已有文件的处理策略: "+strings.Join(tools.OverwritePolicies, ", "))
+	dryRun := command.Flags.Bool("dry-run", false, "列出将要写入的文件、权限和大小，不写入磁盘")
+	diff := command.Flags.Bool("diff", false, "显示与磁盘上已有文件的统一diff，不写入磁盘")
+
+	command.Run = func(args []string) error {
+		if len(args) == 0 {
+			return usageErrorf("请指定要添加的组件（可选: %s）", strings.Join(generator.ComponentNames(), ", "))
+		}
+		for _, name := range args {
+			if _, err := generator.LookupComponent(name); err != nil {
+				return newUsageError(err.Error())
+			}
+		}
+		if *db != "" {
+			if _, err := templates.LookupDatabase(*db); err != nil {
+				return newUsageError(err.Error())
+			}
+		}
+
+		policy, err := tools.ParseOverwritePolicy(*overwrite)
+		if err != nil {
+			return newUsageError(err.Error())
+		}
+
+		return h.projectManager.AddComponents(project.AddOptions{
+			Dir:        *path,
+			Components: args,
+			Port:       *port,
+			Domain:     *domain,
+			Database:   *db,
+			Force:      *force,
+			DryRun:     *dryRun,
+			Diff:       *diff,
+			Overwrite:  policy,
+		})
+	}
+	return command
+}
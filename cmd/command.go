@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// 退出码
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// UsageError 参数用法错误，命令返回该错误时以ExitUsage退出并打印命令帮助
+type UsageError struct {
+	Message string
+}
+
+// Error 实现error接口
+func (e *UsageError) Error() string {
+	return e.Message
+}
+
+// newUsageError 创建参数用法错误
+func newUsageError(message string) error {
+	return &UsageError{Message: message}
+}
+
+// usageErrorf 按格式创建参数用法错误
+func usageErrorf(format string, args ...interface{}) error {
+	return &UsageError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Command 子命令定义
+type Command struct {
+	Name    string
+	Args    string
+	Summary string
+	Flags   *flag.FlagSet
+	Run     func(args []string) error
+}
+
+// newCommand 创建子命令，args为位置参数说明，summary为帮助列表中的一行说明
+func newCommand(name, args, summary string) *Command {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return &Command{
+		Name:    name,
+		Args:    args,
+		Summary: summary,
+		Flags:   fs,
+	}
+}
+
+// Parse 解析命令参数，允许位置参数与命名参数交替出现，"--" 之后全部视为位置参数
+func (c *Command) Parse(args []string) ([]string, error) {
+	var rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, rest = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	var positional []string
+	for {
+		if err := c.Flags.Parse(args); err != nil {
+			return nil, translateFlagError(err)
+		}
+		args = c.Flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return append(positional, rest...), nil
+}
+
+// Visited 判断命名参数是否在命令行中显式指定
+func (c *Command) Visited(name string) bool {
+	visited := false
+	c.Flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			visited = true
+		}
+	})
+	return visited
+}
+
+// PrintHelp 打印命令帮助
+func (c *Command) PrintHelp(w io.Writer) {
+	fmt.Fprintf(w, "用法: aigo_hotreload %s", c.Name)
+	if c.Args != "" {
+		fmt.Fprintf(w, " %s", c.Args)
+	}
+
+	hasFlags := false
+	c.Flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprint(w, " [参数]")
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.TrimSpace(c.Summary))
+
+	if hasFlags {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "参数:")
+		c.Flags.VisitAll(func(f *flag.Flag) {
+			name, usage := flag.UnquoteUsage(f)
+			line := "  --" + f.Name
+			if name != "" {
+				line += " " + name
+			}
+			line = fmt.Sprintf("%-28s %s", line, usage)
+			if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "[]" {
+				line += fmt.Sprintf(" (默认 %s)", f.DefValue)
+			}
+			fmt.Fprintln(w, line)
+		})
+	}
+}
+
+// varsFlag 可重复指定的 key=value 参数
+type varsFlag map[string]string
+
+// String 实现flag.Value接口
+func (v varsFlag) String() string {
+	pairs := make([]string, 0, len(v))
+	for key, value := range v {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set 实现flag.Value接口
+func (v varsFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("变量格式应为 key=value: %s", s)
+	}
+	v[strings.TrimSpace(key)] = value
+	return nil
+}
+
+// translateFlagError 将flag包的英文错误转换为中文提示
+func translateFlagError(err error) error {
+	const undefined = "flag provided but not defined: "
+	if msg := err.Error(); strings.HasPrefix(msg, undefined) {
+		name := strings.TrimLeft(strings.TrimPrefix(msg, undefined), "-")
+		return fmt.Errorf("未知参数 --%s", name)
+	}
+	return err
+}
+
+// isHelp 判断解析错误是否为请求帮助
+func isHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
+
+// listFlag 可重复指定的参数，按指定顺序保存
+type listFlag []string
+
+// String 实现flag.Value接口
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set 实现flag.Value接口
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
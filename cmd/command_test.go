@@ -0,0 +1,876 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/ssl"
+)
+
+// TestCommandParse 测试位置参数与命名参数交替解析
+func TestCommandParse(t *testing.T) {
+	command := newCommand("test", "<name>", "测试命令")
+	port := command.Flags.String("port", "8888", "端口")
+	force := command.Flags.Bool("force", false, "强制")
+
+	args, err := command.Parse([]string{"api.example.com", "--port", "9000", "./app", "--force"})
+	if err != nil {
+		t.Fatalf("解析参数失败: %v", err)
+	}
+
+	if !reflect.DeepEqual(args, []string{"api.example.com", "./app"}) {
+		t.Errorf("位置参数不正确: %v", args)
+	}
+	if *port != "9000" {
+		t.Errorf("port 期望 '9000', 实际得到 '%s'", *port)
+	}
+	if !*force {
+		t.Error("force 应该为 true")
+	}
+	if !command.Visited("port") || command.Visited("missing") {
+		t.Error("Visited 结果不正确")
+	}
+}
+
+// TestCommandParseDoubleDash 测试 "--" 之后的参数不作为命名参数
+func TestCommandParseDoubleDash(t *testing.T) {
+	command := newCommand("test", "", "测试命令")
+	command.Flags.Bool("force", false, "强制")
+
+	args, err := command.Parse([]string{"a", "--", "--force", "b"})
+	if err != nil {
+		t.Fatalf("解析参数失败: %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"a", "--force", "b"}) {
+		t.Errorf("位置参数不正确: %v", args)
+	}
+}
+
+// TestCommandParseUnknownFlag 测试未知参数
+func TestCommandParseUnknownFlag(t *testing.T) {
+	command := newCommand("test", "", "测试命令")
+
+	_, err := command.Parse([]string{"--bogus"})
+	if err == nil {
+		t.Fatal("未知参数应该返回错误")
+	}
+	if !strings.Contains(err.Error(), "--bogus") {
+		t.Errorf("错误信息应该包含参数名, 实际得到: %v", err)
+	}
+
+	if _, err := command.Parse([]string{"--help"}); !isHelp(err) {
+		t.Errorf("--help 应该返回 flag.ErrHelp, 实际得到: %v", err)
+	}
+}
+
+// TestPrintHelp 测试命令帮助输出
+func TestPrintHelp(t *testing.T) {
+	command := newCommand("nginx", "<domain>", "生成nginx配置")
+	command.Flags.String("port", "8888", "应用监听端口")
+
+	var buf strings.Builder
+	command.PrintHelp(&buf)
+	output := buf.String()
+
+	for _, expected := range []string{"aigo_hotreload nginx <domain>", "--port", "应用监听端口", "默认 8888"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("帮助输出应该包含 %s, 实际输出: %s", expected, output)
+		}
+	}
+}
+
+// TestHandleCommandsExitCodes 测试各种情况下的退出码
+func TestHandleCommandsExitCodes(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, "exists"), 0755)
+
+	cases := []struct {
+		name   string
+		args   []string
+		expect int
+	}{
+		{"无参数", nil, ExitUsage},
+		{"版本", []string{"version"}, ExitOK},
+		{"帮助", []string{"help"}, ExitOK},
+		{"子命令帮助", []string{"help", "nginx"}, ExitOK},
+		{"--help参数", []string{"create", "--help"}, ExitOK},
+		{"未知命令", []string{"frobnicate"}, ExitUsage},
+		{"未知子命令帮助", []string{"help", "frobnicate"}, ExitUsage},
+		{"未知参数", []string{"create", "demo", "--bogus"}, ExitUsage},
+		{"缺少项目名", []string{"create"}, ExitUsage},
+		{"缺少域名", []string{"nginx"}, ExitUsage},
+		{"目录已存在", []string{"create", "exists", "--path", tempDir}, ExitError},
+		{"dry-run", []string{"create", "demo", "--path", tempDir, "--dry-run"}, ExitOK},
+		{"变量缺少模板", []string{"create", "demo", "--path", tempDir, "--var", "a=b"}, ExitUsage},
+		{"变量格式错误", []string{"create", "demo", "--template", tempDir, "--var", "ab"}, ExitUsage},
+		{"未知框架", []string{"create", "demo", "--path", tempDir, "--framework", "beego"}, ExitUsage},
+		{"未知数据库", []string{"create", "demo", "--path", tempDir, "--db", "oracle"}, ExitUsage},
+		{"无效端口", []string{"create", "demo", "--path", tempDir, "--port", "http"}, ExitError},
+		{"无效域名", []string{"create", "demo", "--path", tempDir, "--domain", "../../evil", "--yes"}, ExitUsage},
+		{"缺少模板子命令", []string{"template"}, ExitUsage},
+		{"未知模板子命令", []string{"template", "frobnicate"}, ExitUsage},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := NewCommandHandler().HandleCommands(c.args); got != c.expect {
+				t.Errorf("HandleCommands(%v) 期望退出码 %d, 实际得到 %d", c.args, c.expect, got)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "demo")); !os.IsNotExist(err) {
+		t.Error("dry-run 不应该创建项目目录")
+	}
+}
+
+// TestHandleNginxCommand 测试nginx命令的命名参数
+func TestHandleNginxCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	handler := NewCommandHandler()
+
+	args := []string{"nginx", "api.example.com", "--path", tempDir, "--port", "9000"}
+	if code := handler.HandleCommands(args); code != ExitOK {
+		t.Fatalf("生成nginx配置应该成功, 退出码 %d", code)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "config", "api.example.com"))
+	if err != nil {
+		t.Fatalf("读取配置文件失败: %v", err)
+	}
+	if !strings.Contains(string(content), "proxy_pass http://localhost:9000") {
+		t.Error("配置文件应该使用 --port 指定的端口")
+	}
+
+	// 未修改的生成文件可以重复生成
+	if code := handler.HandleCommands(args); code != ExitOK {
+		t.Errorf("重复生成未修改的配置应该成功, 实际退出码 %d", code)
+	}
+
+	// 手动修改过的配置需要 --force，覆盖前会备份
+	configFile := filepath.Join(tempDir, "config", "api.example.com")
+	os.WriteFile(configFile, []byte("# 手动修改\n"), 0644)
+	if code := handler.HandleCommands(args); code != ExitError {
+		t.Errorf("配置被手动修改时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+	if code := handler.HandleCommands(append(args, "--force")); code != ExitOK {
+		t.Errorf("使用 --force 时应该成功, 实际退出码 %d", code)
+	}
+	backups, _ := filepath.Glob(filepath.Join(tempDir, ".aigo", "backups", "*", "config", "api.example.com"))
+	if len(backups) != 1 {
+		t.Errorf("覆盖前应该备份手动修改的配置, 找到 %v", backups)
+	}
+
+	if code := handler.HandleCommands(append(args, "--overwrite", "never")); code != ExitUsage {
+		t.Errorf("无效的覆盖策略应该返回 %d, 实际得到 %d", ExitUsage, code)
+	}
+
+	// 域名用作文件名和server_name，不能越出config目录或注入nginx指令
+	for _, domain := range []string{"../../evil", "a.com;return 200 x"} {
+		if code := handler.HandleCommands([]string{"nginx", domain, "--path", tempDir}); code != ExitUsage {
+			t.Errorf("无效的域名 %q 应该返回 %d, 实际得到 %d", domain, ExitUsage, code)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(tempDir), "evil")); !os.IsNotExist(err) {
+		t.Errorf("不应该在config目录之外写入文件: %v", err)
+	}
+}
+
+// TestCreateWithTemplate 测试使用自定义模板创建项目
+func TestCreateWithTemplate(t *testing.T) {
+	t.Setenv("AIGO_HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	manifest := "prompts:\n  - name: db\n    default: sqlite\n"
+	os.WriteFile(filepath.Join(templateDir, "template.yaml"), []byte(manifest), 0644)
+	os.WriteFile(filepath.Join(templateDir, "db.txt.tmpl"), []byte("{{.ProjectName}}:{{.Vars.db}}"), 0644)
+
+	handler := NewCommandHandler()
+	if code := handler.HandleCommands([]string{"template", "add", "mine", templateDir}); code != ExitOK {
+		t.Fatalf("注册模板应该成功, 退出码 %d", code)
+	}
+
+	projectsDir := t.TempDir()
+	args := []string{"create", "demo", "--path", projectsDir, "--template", "mine", "--var", "db=postgres"}
+	if code := handler.HandleCommands(args); code != ExitOK {
+		t.Fatalf("使用模板创建项目应该成功, 退出码 %d", code)
+	}
+
+	content, err := os.ReadFile(filepath.Join(projectsDir, "demo", "db.txt"))
+	if err != nil || string(content) != "demo:postgres" {
+		t.Errorf("模板变量未生效: %q %v", content, err)
+	}
+}
+
+// TestCreateWithFramework 测试使用 --framework 创建项目
+func TestCreateWithFramework(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--framework", "chi"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建chi项目应该成功, 退出码 %d", code)
+	}
+
+	goMod, _ := os.ReadFile(filepath.Join(tempDir, "demo", "go.mod"))
+	if !strings.Contains(string(goMod), "github.com/go-chi/chi/v5") || strings.Contains(string(goMod), "gin-gonic") {
+		t.Errorf("go.mod 应只包含chi依赖:\n%s", goMod)
+	}
+	main, _ := os.ReadFile(filepath.Join(tempDir, "demo", "main.go"))
+	if !strings.Contains(string(main), "chi.NewRouter()") {
+		t.Error("main.go 应使用chi路由器")
+	}
+}
+
+// TestNginxDiffAndDryRun 测试 --diff 与 --dry-run 不修改已有文件
+func TestNginxDiffAndDryRun(t *testing.T) {
+	tempDir := t.TempDir()
+	handler := NewCommandHandler()
+	configFile := filepath.Join(tempDir, "config", "api.example.com")
+
+	if code := handler.HandleCommands([]string{"nginx", "api.example.com", "--path", tempDir, "--dry-run"}); code != ExitOK {
+		t.Fatalf("dry-run 应该成功, 退出码 %d", code)
+	}
+	if _, err := os.Stat(configFile); !os.IsNotExist(err) {
+		t.Fatal("dry-run 不应该写入配置文件")
+	}
+
+	handler.HandleCommands([]string{"nginx", "api.example.com", "--path", tempDir})
+	before, _ := os.ReadFile(configFile)
+
+	// 已存在的配置无需 --force 即可查看diff
+	if code := handler.HandleCommands([]string{"nginx", "api.example.com", "--path", tempDir, "--port", "9000", "--diff"}); code != ExitOK {
+		t.Fatalf("diff 应该成功, 退出码 %d", code)
+	}
+	after, _ := os.ReadFile(configFile)
+	if string(before) != string(after) {
+		t.Error("diff 不应该修改配置文件")
+	}
+}
+
+// TestHandleAddCommand 测试向已有项目添加组件
+func TestHandleAddCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	handler := NewCommandHandler()
+	if code := handler.HandleCommands([]string{"create", "demo", "--path", tempDir, "--no-nginx"}); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+
+	args := []string{"add", "makefile", "db", "--path", projectPath, "--db", "postgres"}
+	if code := handler.HandleCommands(args); code != ExitOK {
+		t.Fatalf("添加组件应该成功, 退出码 %d", code)
+	}
+	for _, file := range []string{"Makefile", "internal/db/db.go"} {
+		if _, err := os.Stat(filepath.Join(projectPath, file)); err != nil {
+			t.Errorf("应该生成 %s", file)
+		}
+	}
+	goMod, _ := os.ReadFile(filepath.Join(projectPath, "go.mod"))
+	if !strings.Contains(string(goMod), "github.com/jackc/pgx/v5") {
+		t.Error("go.mod 应该添加数据库驱动")
+	}
+
+	for _, args := range [][]string{
+		{"add", "--path", projectPath},
+		{"add", "kubernetes", "--path", projectPath},
+		{"add", "db", "--path", projectPath, "--db", "oracle"},
+	} {
+		if code := NewCommandHandler().HandleCommands(args); code != ExitUsage {
+			t.Errorf("%v 应该返回 %d, 实际得到 %d", args, ExitUsage, code)
+		}
+	}
+
+	// 域名用作nginx配置文件名，不能越出项目目录
+	if code := NewCommandHandler().HandleCommands([]string{"add", "nginx", "--path", projectPath, "--domain", "../../evil"}); code == ExitOK {
+		t.Error("无效的域名不应该添加nginx组件")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(tempDir, "evil*")); len(matches) > 0 {
+		t.Errorf("不应该在项目目录之外写入文件: %v", matches)
+	}
+
+	// 不是Go项目的目录
+	if code := NewCommandHandler().HandleCommands([]string{"add", "ci", "--path", t.TempDir()}); code != ExitError {
+		t.Errorf("缺少go.mod时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+}
+
+// TestNginxReadsProjectFile 测试nginx命令从aigo.yaml读取域名和端口
+func TestNginxReadsProjectFile(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "api.example.com", "--port", "9100"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+
+	// 修改项目清单中的端口后重新生成
+	manifest := filepath.Join(projectPath, "aigo.yaml")
+	content, _ := os.ReadFile(manifest)
+	os.WriteFile(manifest, []byte(strings.Replace(string(content), `port: "9100"`, `port: "9200"`, 1)), 0644)
+
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath}); code != ExitOK {
+		t.Fatalf("未指定域名时应该使用aigo.yaml中的域名, 退出码 %d", code)
+	}
+	config, _ := os.ReadFile(filepath.Join(projectPath, "config", "api.example.com"))
+	if !strings.Contains(string(config), "proxy_pass http://localhost:9200") {
+		t.Error("nginx配置应该使用aigo.yaml中的端口")
+	}
+
+	// 新域名记录到项目清单
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "www.example.com", "--path", projectPath}); code != ExitOK {
+		t.Fatalf("生成新域名配置应该成功, 退出码 %d", code)
+	}
+	content, _ = os.ReadFile(manifest)
+	if !strings.Contains(string(content), "- www.example.com") {
+		t.Errorf("新域名应该记录到aigo.yaml:\n%s", content)
+	}
+
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", t.TempDir()}); code != ExitUsage {
+		t.Errorf("没有域名和项目清单时应该返回 %d, 实际得到 %d", ExitUsage, code)
+	}
+}
+
+// TestNginxTLS 测试 --tls、--cert 和 --key 参数
+func TestNginxTLS(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "api.example.com"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+	configPath := filepath.Join(projectPath, "config", "api.example.com")
+
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath, "--cert", "/etc/ssl/api.crt"}); code != ExitUsage {
+		t.Errorf("只指定 --cert 时应该返回 %d, 实际得到 %d", ExitUsage, code)
+	}
+
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath, "--tls"}); code != ExitOK {
+		t.Fatalf("生成HTTPS配置应该成功, 退出码 %d", code)
+	}
+	content, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(content), "ssl_certificate /etc/letsencrypt/live/api.example.com/fullchain.pem;") {
+		t.Errorf("--tls 应该使用Let's Encrypt证书路径:\n%s", content)
+	}
+
+	args = []string{"nginx", "--path", projectPath, "--cert", "/etc/ssl/api.crt", "--key", "/etc/ssl/api.key"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("使用自定义证书应该成功, 退出码 %d", code)
+	}
+	manifest, _ := os.ReadFile(filepath.Join(projectPath, "aigo.yaml"))
+	if !strings.Contains(string(manifest), "cert: /etc/ssl/api.crt") {
+		t.Errorf("证书配置应该记录到aigo.yaml:\n%s", manifest)
+	}
+
+	// 不带参数重新生成时保留aigo.yaml中的证书配置
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath}); code != ExitOK {
+		t.Fatalf("重新生成应该成功, 退出码 %d", code)
+	}
+	content, _ = os.ReadFile(configPath)
+	if !strings.Contains(string(content), "ssl_certificate_key /etc/ssl/api.key;") {
+		t.Errorf("重新生成时应该保留自定义证书:\n%s", content)
+	}
+}
+
+// TestNginxLint 测试检查生成的nginx配置
+func TestNginxLint(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "api.example.com"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", "--path", projectPath}); code != ExitOK {
+		t.Errorf("生成的HTTP配置应该通过检查, 退出码 %d", code)
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath, "--tls"}); code != ExitOK {
+		t.Fatalf("生成HTTPS配置应该成功, 退出码 %d", code)
+	}
+	configDir := filepath.Join(projectPath, "config")
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", configDir}); code != ExitOK {
+		t.Errorf("生成的HTTPS配置应该通过检查, 退出码 %d", code)
+	}
+
+	// 同一域名的第二份配置导致server_name重复只产生警告
+	content, _ := os.ReadFile(filepath.Join(configDir, "api.example.com"))
+	os.WriteFile(filepath.Join(configDir, "copy.conf"), content, 0644)
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", configDir}); code != ExitOK {
+		t.Errorf("只有警告时应该返回 %d, 实际得到 %d", ExitOK, code)
+	}
+
+	os.WriteFile(filepath.Join(configDir, "copy.conf"), []byte("server {\n    listen 80;\n"), 0644)
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", configDir}); code != ExitError {
+		t.Errorf("括号不匹配时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+}
+
+// TestNginxUpstream 测试多个负载均衡后端
+func TestNginxUpstream(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "api.example.com"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+	configPath := filepath.Join(projectPath, "config", "api.example.com")
+
+	usage := [][]string{
+		{"nginx", "--path", projectPath, "--balance", "least_conn"},
+		{"nginx", "--path", projectPath, "--upstream", "127.0.0.1:8001", "--balance", "random"},
+		{"nginx", "--path", projectPath, "--upstream", "127.0.0.1:8001,weight=x"},
+		{"nginx", "--path", projectPath, "--upstream", "127.0.0.1:8001", "--max-fails", "many"},
+	}
+	for _, args := range usage {
+		if code := NewCommandHandler().HandleCommands(args); code != ExitUsage {
+			t.Errorf("%v 应该返回 %d, 实际得到 %d", args, ExitUsage, code)
+		}
+	}
+
+	args = []string{"nginx", "--path", projectPath,
+		"--upstream", "127.0.0.1:8001,weight=3", "--upstream", "127.0.0.1:8002,max_fails=1",
+		"--balance", "least_conn", "--keepalive", "32", "--max-fails", "2", "--fail-timeout", "10s"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("生成负载均衡配置应该成功, 退出码 %d", code)
+	}
+	content, _ := os.ReadFile(configPath)
+	for _, want := range []string{
+		"server 127.0.0.1:8001 weight=3 max_fails=2 fail_timeout=10s;",
+		"server 127.0.0.1:8002 max_fails=1 fail_timeout=10s;",
+		"least_conn;",
+		"keepalive 32;",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("nginx配置应该包含 %q:\n%s", want, content)
+		}
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", "--path", projectPath}); code != ExitOK {
+		t.Errorf("负载均衡配置应该通过检查, 退出码 %d", code)
+	}
+
+	// 不带参数重新生成时保留aigo.yaml中的后端
+	manifest, _ := os.ReadFile(filepath.Join(projectPath, "aigo.yaml"))
+	if !strings.Contains(string(manifest), "method: least_conn") || !strings.Contains(string(manifest), "addr: 127.0.0.1:8002") {
+		t.Errorf("负载均衡配置应该记录到aigo.yaml:\n%s", manifest)
+	}
+	os.Remove(configPath)
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath}); code != ExitOK {
+		t.Fatalf("重新生成应该成功, 退出码 %d", code)
+	}
+	content, _ = os.ReadFile(configPath)
+	if !strings.Contains(string(content), "proxy_pass http://api_example_com_backend;") {
+		t.Errorf("重新生成时应该保留负载均衡配置:\n%s", content)
+	}
+}
+
+// TestNginxLocations 测试同一域名下按路径转发到多个服务
+func TestNginxLocations(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "api.example.com"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+	configPath := filepath.Join(projectPath, "config", "api.example.com")
+
+	for _, spec := range []string{"api", "/api,bogus", "/api=ftp://x"} {
+		if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath, "--location", spec}); code != ExitUsage {
+			t.Errorf("--location %s 应该返回 %d, 实际得到 %d", spec, ExitUsage, code)
+		}
+	}
+	args = []string{"nginx", "--path", projectPath, "--location", "/api", "--location", "/api=9001"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitUsage {
+		t.Errorf("重复的路由路径应该返回 %d, 实际得到 %d", ExitUsage, code)
+	}
+
+	args = []string{"nginx", "--path", projectPath,
+		"--location", "/api/=127.0.0.1:8081,strip_prefix,header=X-Service:api",
+		"--location", "/admin/=9001,websocket,read_timeout=1h"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("生成路由配置应该成功, 退出码 %d", code)
+	}
+	content, _ := os.ReadFile(configPath)
+	for _, want := range []string{"location /api/ {", "rewrite ^/api(/|$)(.*)$ /$2 break;", "location /admin/ {", "proxy_read_timeout 1h;", "proxy_pass http://localhost:8888;"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("nginx配置应该包含 %q:\n%s", want, content)
+		}
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", "--path", projectPath}); code != ExitOK {
+		t.Errorf("路由配置应该通过检查, 退出码 %d", code)
+	}
+
+	manifest, _ := os.ReadFile(filepath.Join(projectPath, "aigo.yaml"))
+	if !strings.Contains(string(manifest), "path: /admin/") || !strings.Contains(string(manifest), "X-Service: api") {
+		t.Errorf("路由应该记录到aigo.yaml:\n%s", manifest)
+	}
+
+	// 不带参数重新生成时保留aigo.yaml中的路由
+	os.Remove(configPath)
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath}); code != ExitOK {
+		t.Fatalf("重新生成应该成功, 退出码 %d", code)
+	}
+	content, _ = os.ReadFile(configPath)
+	if !strings.Contains(string(content), "location /admin/ {") {
+		t.Errorf("重新生成时应该保留路由:\n%s", content)
+	}
+}
+
+// TestNginxStatic 测试静态目录、压缩和缓存配置
+func TestNginxStatic(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "www.example.com"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+	configPath := filepath.Join(projectPath, "config", "www.example.com")
+
+	for _, spec := range []string{"js", "js=forever"} {
+		if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath, "--cache", spec}); code != ExitUsage {
+			t.Errorf("--cache %s 应该返回 %d, 实际得到 %d", spec, ExitUsage, code)
+		}
+	}
+
+	args = []string{"nginx", "--path", projectPath, "--gzip", "--brotli", "--cache", "js,css=30d",
+		"--location", "/,root=/srv/www,spa", "--location", "/api/=9001"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("生成静态配置应该成功, 退出码 %d", code)
+	}
+	content, _ := os.ReadFile(configPath)
+	for _, want := range []string{"root /srv/www;", "try_files $uri $uri/ /index.html;", "gzip on;", "include /etc/nginx/snippets/aigo-brotli*.conf;", "expires $www_example_com_expires;"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("nginx配置应该包含 %q:\n%s", want, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "config", "brotli.conf")); err != nil {
+		t.Errorf("启用brotli时应该生成配置片段: %v", err)
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", "--path", projectPath}); code != ExitOK {
+		t.Errorf("静态配置应该通过检查, 退出码 %d", code)
+	}
+
+	manifest, _ := os.ReadFile(filepath.Join(projectPath, "aigo.yaml"))
+	for _, want := range []string{"root: /srv/www", "spa: true", "gzip: true", "brotli: true", "expires: 30d"} {
+		if !strings.Contains(string(manifest), want) {
+			t.Errorf("aigo.yaml应该包含 %q:\n%s", want, manifest)
+		}
+	}
+
+	// 命令行关闭brotli时覆盖aigo.yaml中的设置，其余配置保留
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath, "--brotli=false"}); code != ExitOK {
+		t.Fatalf("重新生成应该成功, 退出码 %d", code)
+	}
+	content, _ = os.ReadFile(configPath)
+	if strings.Contains(string(content), "brotli") || !strings.Contains(string(content), "gzip on;") || !strings.Contains(string(content), "root /srv/www;") {
+		t.Errorf("应该只关闭brotli:\n%s", content)
+	}
+}
+
+// TestNginxAccess 测试速率限制、IP访问列表和basic auth
+func TestNginxAccess(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "dev.example.com"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+	configPath := filepath.Join(projectPath, "config", "dev.example.com")
+	htpasswdPath := configPath + ".htpasswd"
+
+	usage := [][]string{
+		{"--location", "/,rate=fast"},
+		{"--location", "/,auth", "--auth-user", "a:b", "--auth-hash", "md5"},
+		{"--location", "/", "--auth-user", "alice"},
+	}
+	for _, extra := range usage {
+		if code := NewCommandHandler().HandleCommands(append([]string{"nginx", "--path", projectPath}, extra...)); code != ExitUsage {
+			t.Errorf("%v 应该返回 %d, 实际得到 %d", extra, ExitUsage, code)
+		}
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath, "--location", "/,auth"}); code != ExitError {
+		t.Errorf("启用auth但没有用户时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+
+	args = []string{"nginx", "--path", projectPath, "--location", "/,auth,rate=5r/s,burst=10,allow=10.0.0.0/8",
+		"--auth-user", "alice:secret", "--auth-user", "bob"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("生成访问控制配置应该成功, 退出码 %d", code)
+	}
+	content, _ := os.ReadFile(configPath)
+	for _, want := range []string{"limit_req_zone $binary_remote_addr zone=dev_example_com_limit1:10m rate=5r/s;", "limit_req zone=dev_example_com_limit1 burst=10 nodelay;", "allow 10.0.0.0/8;", "deny all;", "auth_basic_user_file /etc/nginx/htpasswd/dev.example.com;"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("nginx配置应该包含 %q:\n%s", want, content)
+		}
+	}
+	htpasswd, err := os.ReadFile(htpasswdPath)
+	if err != nil || !strings.HasPrefix(string(htpasswd), "alice:$apr1$") || !strings.Contains(string(htpasswd), "\nbob:$apr1$") {
+		t.Fatalf("htpasswd文件错误: %s, %v", htpasswd, err)
+	}
+	if info, _ := os.Stat(htpasswdPath); info.Mode().Perm() != 0600 {
+		t.Errorf("htpasswd文件权限应该是0600, 实际得到 %o", info.Mode().Perm())
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", "--path", projectPath}); code != ExitOK {
+		t.Errorf("访问控制配置应该通过检查, 退出码 %d", code)
+	}
+
+	manifest, _ := os.ReadFile(filepath.Join(projectPath, "aigo.yaml"))
+	if !strings.Contains(string(manifest), "auth: true") || strings.Contains(string(manifest), "secret") {
+		t.Errorf("aigo.yaml应该记录auth但不能包含密码:\n%s", manifest)
+	}
+
+	// 不指定用户重新生成时保留已有的htpasswd文件，指定用户时只修改该用户
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath}); code != ExitOK {
+		t.Fatalf("重新生成应该成功, 退出码 %d", code)
+	}
+	if kept, _ := os.ReadFile(htpasswdPath); string(kept) != string(htpasswd) {
+		t.Errorf("未指定用户时不应该修改htpasswd文件:\n%s", kept)
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "--path", projectPath, "--auth-user", "bob:new", "--auth-hash", "bcrypt"}); code != ExitOK {
+		t.Fatalf("修改密码应该成功, 退出码 %d", code)
+	}
+	updated, _ := os.ReadFile(htpasswdPath)
+	lines := strings.Split(strings.TrimSpace(string(updated)), "\n")
+	if len(lines) != 2 || lines[0] != strings.Split(string(htpasswd), "\n")[0] || !strings.HasPrefix(lines[1], "bob:$2a$") {
+		t.Errorf("应该只修改bob的密码:\n%s", updated)
+	}
+}
+
+// TestProxyBackends 测试使用Caddy和Traefik代替nginx生成反向代理配置
+func TestProxyBackends(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "dev.example.com"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+	configDir := filepath.Join(projectPath, "config")
+
+	if code := NewCommandHandler().HandleCommands([]string{"proxy", "--path", projectPath, "--backend", "apache"}); code != ExitUsage {
+		t.Errorf("未知的反向代理应该返回 %d, 实际得到 %d", ExitUsage, code)
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"proxy", "--path", projectPath, "--backend", "traefik", "--location", "/,root=/srv/www"}); code != ExitError {
+		t.Errorf("Traefik不支持静态文件，应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"proxy", "--path", projectPath, "--backend", "caddy", "--location", "/,auth", "--auth-user", "a:b", "--auth-hash", "apr1"}); code != ExitError {
+		t.Errorf("Caddy不支持apr1，应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+
+	args = []string{"proxy", "--path", projectPath, "--backend", "caddy", "--tls", "--location", "/api/=9001,strip_prefix", "--location", "/,auth", "--auth-user", "alice:secret"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("生成Caddy配置应该成功, 退出码 %d", code)
+	}
+	content, _ := os.ReadFile(filepath.Join(configDir, "dev.example.com.caddy"))
+	for _, want := range []string{"dev.example.com {", "handle_path /api/* {", "reverse_proxy localhost:9001", "alice $2a$"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Caddy配置应该包含 %q:\n%s", want, content)
+		}
+	}
+	manifest, _ := os.ReadFile(filepath.Join(projectPath, "aigo.yaml"))
+	if !strings.Contains(string(manifest), "proxy: caddy") || !strings.Contains(string(manifest), "path: /api/") {
+		t.Errorf("aigo.yaml应该记录反向代理和路由:\n%s", manifest)
+	}
+
+	// 未指定 --backend 时使用aigo.yaml中记录的反向代理和路由
+	if code := NewCommandHandler().HandleCommands([]string{"proxy", "--path", projectPath, "--dry-run"}); code != ExitOK {
+		t.Errorf("读取aigo.yaml应该成功, 退出码 %d", code)
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"proxy", "--path", projectPath, "--backend", "traefik", "--auth-hash", "bcrypt"}); code != ExitOK {
+		t.Fatalf("生成Traefik配置应该成功, 退出码 %d", code)
+	}
+	content, _ = os.ReadFile(filepath.Join(configDir, "dev.example.com.traefik.yml"))
+	for _, want := range []string{"PathPrefix(`/api/`)", "certResolver: letsencrypt", "- \"alice:$2a$"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Traefik配置应该包含 %q:\n%s", want, content)
+		}
+	}
+	manifest, _ = os.ReadFile(filepath.Join(projectPath, "aigo.yaml"))
+	if !strings.Contains(string(manifest), "proxy: traefik") {
+		t.Errorf("aigo.yaml应该记录traefik:\n%s", manifest)
+	}
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "lint", "--path", projectPath}); code != ExitOK {
+		t.Errorf("nginx lint应该跳过Caddy和Traefik配置, 退出码 %d", code)
+	}
+}
+
+// TestNginxEnableDisable 测试使用模拟的nginx启用和禁用站点
+func TestNginxEnableDisable(t *testing.T) {
+	tempDir := t.TempDir()
+	args := []string{"create", "demo", "--path", tempDir, "--domain", "dev.example.com"}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("创建项目应该成功, 退出码 %d", code)
+	}
+	projectPath := filepath.Join(tempDir, "demo")
+	nginxRoot := filepath.Join(tempDir, "nginx")
+
+	// 模拟的nginx在配置中包含 invalid 时检查失败
+	binary := filepath.Join(tempDir, "fake-nginx")
+	script := "#!/bin/sh\ncat " + nginxRoot + "/sites-enabled/* | grep -q invalid && exit 1\nexit 0\n"
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	deploy := func(action string) int {
+		return NewCommandHandler().HandleCommands([]string{"nginx", action, "dev.example.com", "--path", projectPath,
+			"--nginx-root", nginxRoot, "--test-cmd", binary + " -t", "--reload-cmd", binary + " -s reload"})
+	}
+
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "enable", "--path", projectPath}); code != ExitUsage {
+		t.Errorf("缺少域名应该返回 %d, 实际得到 %d", ExitUsage, code)
+	}
+	if code := deploy("enable"); code != ExitOK {
+		t.Fatalf("启用站点应该成功, 退出码 %d", code)
+	}
+	link := filepath.Join(nginxRoot, "sites-enabled", "dev.example.com")
+	if target, err := os.Readlink(link); err != nil || !strings.HasSuffix(target, filepath.Join("config", "dev.example.com")) {
+		t.Fatalf("应该链接到项目的配置文件: %s, %v", target, err)
+	}
+
+	// 检查失败时恢复原有链接，备份保存在项目的 .aigo/backups
+	configPath := filepath.Join(projectPath, "config", "dev.example.com")
+	os.Rename(configPath, configPath+".orig")
+	os.WriteFile(configPath, []byte("invalid;\n"), 0644)
+	os.Remove(link)
+	os.Symlink(configPath+".orig", link)
+	if code := deploy("enable"); code != ExitError {
+		t.Errorf("检查失败时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+	if target, _ := os.Readlink(link); target != configPath+".orig" {
+		t.Errorf("应该恢复原有的软链接, 实际指向 %s", target)
+	}
+	backups, _ := filepath.Glob(filepath.Join(projectPath, ".aigo", "backups", "*", "nginx", "sites-enabled", "dev.example.com"))
+	if len(backups) != 1 {
+		t.Errorf("应该在项目中保留一份备份, 实际得到 %v", backups)
+	}
+
+	// 越出sites-enabled的域名在修改文件之前被拒绝
+	outside := filepath.Join(nginxRoot, "sites-available", "default")
+	os.MkdirAll(filepath.Dir(outside), 0755)
+	os.WriteFile(outside, []byte("server {}\n"), 0644)
+	if code := NewCommandHandler().HandleCommands([]string{"nginx", "disable", "../sites-available/default", "--path", projectPath,
+		"--nginx-root", nginxRoot, "--test-cmd", binary + " -t", "--reload-cmd", ""}); code != ExitUsage {
+		t.Errorf("包含 .. 的域名应该返回 %d, 实际得到 %d", ExitUsage, code)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("不应该删除sites-enabled之外的文件: %v", err)
+	}
+
+	if code := deploy("disable"); code != ExitOK {
+		t.Fatalf("禁用站点应该成功, 退出码 %d", code)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Errorf("禁用后应该删除软链接: %v", err)
+	}
+	if code := deploy("disable"); code != ExitError {
+		t.Errorf("禁用未启用的站点应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+}
+
+// TestSSLCommand 测试ssl命令的参数检查，申请流程由ssl包中的ACME测试服务覆盖
+func TestSSLCommand(t *testing.T) {
+	certDir := t.TempDir()
+	usage := [][]string{
+		{"ssl"},
+		{"ssl", "revoke"},
+		{"ssl", "issue", "a.com", "--staging", "--server", "https://acme.example.com/directory"},
+		{"ssl", "issue", "--path", t.TempDir()},
+		{"ssl", "issue", "../a.com"},
+		{"ssl", "local", "a.com;b"},
+	}
+	for _, args := range usage {
+		if code := NewCommandHandler().HandleCommands(args); code != ExitUsage {
+			t.Errorf("%v 应该返回 %d, 实际得到 %d", args, ExitUsage, code)
+		}
+	}
+
+	// 证书目录为空时没有需要续期的证书，不访问ACME服务
+	args := []string{"ssl", "renew", "--dir", certDir, "--server", "http://127.0.0.1:1/directory", "--reload-cmd", ""}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Errorf("没有证书时续期应该成功, 退出码 %d", code)
+	}
+	args = []string{"ssl", "issue", "a.com", "--dir", certDir, "--webroot", t.TempDir(), "--server", "http://127.0.0.1:1/directory", "--reload-cmd", ""}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitError {
+		t.Errorf("无法访问ACME服务时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+	if _, err := os.Stat(filepath.Join(certDir, "live", "a.com")); !os.IsNotExist(err) {
+		t.Errorf("申请失败时不应该创建证书目录: %v", err)
+	}
+}
+
+// TestSSLLocal 测试本地CA签发证书，dev命令可以找到项目中的证书
+func TestSSLLocal(t *testing.T) {
+	caDir, projectPath := t.TempDir(), t.TempDir()
+	if _, _, ok := localCertificate(projectPath); ok {
+		t.Error("没有签发证书时不应该找到本地证书")
+	}
+	for _, args := range [][]string{
+		{"ssl", "local", "--ca-dir", caDir, "--path", projectPath, "app.test", "*.app.test"},
+		{"ssl", "local", "--ca-dir", caDir, "--path", projectPath},
+	} {
+		if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+			t.Fatalf("%v 应该成功, 退出码 %d", args, code)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(caDir, ssl.LocalCAFile)); err != nil {
+		t.Errorf("应该生成本地CA: %v", err)
+	}
+	certFile, keyFile, ok := localCertificate(projectPath)
+	if !ok || filepath.Base(filepath.Dir(certFile)) != "localhost" {
+		t.Fatalf("应该优先使用localhost的证书: %s, %v", certFile, ok)
+	}
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		t.Errorf("本地证书和私钥不匹配: %v", err)
+	}
+}
+
+// TestSSLStatus 测试证书到期检查的退出码
+func TestSSLStatus(t *testing.T) {
+	projectPath, emptyDir := t.TempDir(), t.TempDir()
+	status := func(extra ...string) int {
+		args := append([]string{"ssl", "status", "--path", projectPath, "--dir", emptyDir}, extra...)
+		return NewCommandHandler().HandleCommands(args)
+	}
+	if code := status(); code != ExitOK {
+		t.Errorf("没有证书时应该返回 %d, 实际得到 %d", ExitOK, code)
+	}
+
+	args := []string{"ssl", "local", "--ca-dir", t.TempDir(), "--path", projectPath}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitOK {
+		t.Fatalf("签发本地证书失败, 退出码 %d", code)
+	}
+	if code := status("--days", "30"); code != ExitOK {
+		t.Errorf("证书未到期时应该返回 %d, 实际得到 %d", ExitOK, code)
+	}
+	if code := status("--days", "1000"); code != ExitError {
+		t.Errorf("证书在阈值内过期时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+
+	// nginx配置引用的证书不存在
+	confDir := filepath.Join(projectPath, "config")
+	if err := os.MkdirAll(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	conf := "server {\n    listen 443 ssl;\n    ssl_certificate " + filepath.Join(emptyDir, "missing.pem") + ";\n}\n"
+	if err := os.WriteFile(filepath.Join(confDir, "a.com"), []byte(conf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code := status(); code != ExitError {
+		t.Errorf("nginx配置引用的证书不存在时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+	if code := status("--conf", filepath.Join(t.TempDir())); code != ExitOK {
+		t.Errorf("指定 --conf 时不应该扫描项目的config目录, 退出码 %d", code)
+	}
+
+	// 无法解析的配置和无法读取的证书目录计为问题，其他来源继续检查
+	brokenDir := t.TempDir()
+	os.WriteFile(filepath.Join(brokenDir, "broken.com"), []byte("server {\n"), 0644)
+	if code := status("--conf", brokenDir); code != ExitError {
+		t.Errorf("nginx配置无法解析时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+	badStore := t.TempDir()
+	os.WriteFile(filepath.Join(badStore, "live"), nil, 0644)
+	args = []string{"ssl", "status", "--path", projectPath, "--dir", badStore, "--conf", t.TempDir()}
+	if code := NewCommandHandler().HandleCommands(args); code != ExitError {
+		t.Errorf("证书目录无法读取时应该返回 %d, 实际得到 %d", ExitError, code)
+	}
+}
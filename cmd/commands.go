@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 
 	"github.com/yggai/aigo_hotreload/config"
@@ -12,80 +13,116 @@ import (
 type CommandHandler struct {
 	projectManager *project.Manager
 	logger         *tools.Logger
+	commands       []*Command
 }
 
 // NewCommandHandler 创建新的命令处理器
 func NewCommandHandler() *CommandHandler {
-	return &CommandHandler{
+	h := &CommandHandler{
 		projectManager: project.NewManager(),
 		logger:         tools.NewLogger(),
 	}
+	h.commands = []*Command{
+		h.createCommand(),
+		h.addCommand(),
+		h.devCommand(),
+		h.nginxCommand(),
+		h.proxyCommand(),
+		h.sslCommand(),
+		h.templateCommand(),
+		h.versionCommand(),
+		h.helpCommand(),
+	}
+	return h
 }
 
-// HandleCommands 处理命令行命令
-func (h *CommandHandler) HandleCommands() {
-	if len(os.Args) < 2 {
+// HandleCommands 处理命令行命令，args不包含程序名，返回进程退出码
+func (h *CommandHandler) HandleCommands(args []string) int {
+	if len(args) < 1 {
 		h.printUsage()
-		return
+		return ExitUsage
 	}
 
-	command := os.Args[1]
-	switch command {
-	case "create":
-		h.handleCreate()
-	case "nginx":
-		h.handleNginx()
-	case "version":
-		h.handleVersion()
-	case "help":
+	name := args[0]
+	if name == "-h" || name == "--help" {
 		h.printUsage()
-	default:
-		h.logger.Error(config.Messages.Errors.UnknownCommand, command)
+		return ExitOK
+	}
+
+	command := h.lookup(name)
+	if command == nil {
+		h.logger.Error(config.Messages.Errors.UnknownCommand, name)
 		h.printUsage()
+		return ExitUsage
 	}
-}
 
-// handleCreate 处理创建项目命令
-func (h *CommandHandler) handleCreate() {
-	if len(os.Args) < 3 {
-		h.logger.Error(config.Messages.Errors.NoProjectName)
-		return
+	positional, err := command.Parse(args[1:])
+	if err != nil {
+		if isHelp(err) {
+			command.PrintHelp(os.Stdout)
+			return ExitOK
+		}
+		h.logger.Error(config.Messages.Errors.InvalidArgs, err)
+		command.PrintHelp(os.Stdout)
+		return ExitUsage
 	}
-	projectName := os.Args[2]
-	h.projectManager.CreateProject(projectName)
-}
 
-// handleVersion 处理版本命令
-func (h *CommandHandler) handleVersion() {
-	h.logger.Info("aigo_hotreload version %s", config.Version)
+	if err := command.Run(positional); err != nil {
+		var usageErr *UsageError
+		if errors.As(err, &usageErr) {
+			h.logger.Error("%v", err)
+			command.PrintHelp(os.Stdout)
+			return ExitUsage
+		}
+		h.logger.Error("%v", err)
+		return ExitError
+	}
+	return ExitOK
 }
 
-// handleNginx 处理nginx配置命令
-func (h *CommandHandler) handleNginx() {
-	if len(os.Args) < 4 {
-		h.logger.Error("用法: aigo_hotreload nginx <domain> <project-path> [port]")
-		return
+// lookup 按名称查找子命令
+func (h *CommandHandler) lookup(name string) *Command {
+	for _, command := range h.commands {
+		if command.Name == name {
+			return command
+		}
 	}
-	
-	domain := os.Args[2]
-	projectPath := os.Args[3]
-	port := ""
-	
-	if len(os.Args) >= 5 {
-		port = os.Args[4]
+	return nil
+}
+
+// versionCommand 版本命令
+func (h *CommandHandler) versionCommand() *Command {
+	command := newCommand("version", "", "显示版本信息")
+	command.Run = func(args []string) error {
+		if len(args) > 0 {
+			return usageErrorf(config.Messages.Errors.UnexpectedArgs, args)
+		}
+		h.logger.Info("aigo_hotreload version %s", config.Version)
+		return nil
 	}
-	
-	nginxManager := tools.NewNginxManager()
-	if err := nginxManager.GenerateAll(domain, projectPath, port); err != nil {
-		h.logger.Error("生成nginx配置失败: %v", err)
-		return
+	return command
+}
+
+// helpCommand 帮助命令，支持 help <command> 查看子命令帮助
+func (h *CommandHandler) helpCommand() *Command {
+	command := newCommand("help", "[command]", "显示帮助信息")
+	command.Run = func(args []string) error {
+		if len(args) == 0 {
+			h.printUsage()
+			return nil
+		}
+		if len(args) > 1 {
+			return usageErrorf(config.Messages.Errors.UnexpectedArgs, args[1:])
+		}
+
+		target := h.lookup(args[0])
+		if target == nil {
+			return usageErrorf(config.Messages.Errors.UnknownCommand, args[0])
+		}
+		target.PrintHelp(os.Stdout)
+		return nil
 	}
-	
-	h.logger.Success("nginx配置生成完成")
-	h.logger.Info("下一步:")
-	h.logger.Info("1. 编辑配置文件: vim %s/config/%s", projectPath, domain)
-	h.logger.Info("2. 运行配置脚本: %s/config/setup-nginx.sh %s", projectPath, domain)
-	h.logger.Info("3. 申请SSL证书: %s/scripts/apply-ssl.sh %s", projectPath, domain)
+	return command
 }
 
 // printUsage 打印使用说明
@@ -94,9 +131,16 @@ func (h *CommandHandler) printUsage() {
 	h.logger.PrintEmpty()
 	h.logger.Println(config.Messages.UsageHeader)
 	h.logger.Println(config.Messages.Commands.Create)
+	h.logger.Println(config.Messages.Commands.Add)
+	h.logger.Println(config.Messages.Commands.Dev)
 	h.logger.Println(config.Messages.Commands.Nginx)
+	h.logger.Println(config.Messages.Commands.NginxLint)
+	h.logger.Println(config.Messages.Commands.NginxSite)
+	h.logger.Println(config.Messages.Commands.Proxy)
+	h.logger.Println(config.Messages.Commands.SSL)
+	h.logger.Println(config.Messages.Commands.Template)
 	h.logger.Println(config.Messages.Commands.Version)
 	h.logger.Println(config.Messages.Commands.Help)
 	h.logger.PrintEmpty()
 	h.logger.Println(config.Messages.Example)
-} 
\ No newline at end of file
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/project"
+	"github.com/yggai/aigo_hotreload/templates"
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// createCommand 创建项目命令，未指定任何参数且标准输入为终端时启动交互向导
+func (h *CommandHandler) createCommand() *Command {
+	command := newCommand("create", "[project-name]", "创建新的热重载项目，在终端中不带参数运行时启动交互向导")
+	path := command.Flags.String("path", "", "项目所在的父目录（默认当前目录）")
+	module := command.Flags.String("module", "", "Go模块路径（默认与项目名称相同）")
+	framework := command.Flags.String("framework", templates.DefaultFramework, "HTTP框架: "+strings.Join(templates.FrameworkNames(), ", "))
+	port := command.Flags.String("port", config.DefaultPort, "服务端口")
+	domain := command.Flags.String("domain", config.DefaultDomain, "nginx配置使用的域名")
+	noNginx := command.Flags.Bool("no-nginx", false, "不生成nginx配置和SSL证书脚本")
+	docker := command.Flags.Bool("docker", false, "生成Dockerfile、.dockerignore和docker-compose.yml")
+	db := command.Flags.String("db", "", "数据库: "+strings.Join(templates.DatabaseNames(), ", "))
+	yes := command.Flags.Bool("yes", false, "不启动交互向导，全部使用默认值")
+	force := command.Flags.Bool("force", false, "目录已存在时仍然生成，并允许覆盖被手动修改的文件")
+	overwrite := command.Flags.String("overwrite", tools.DefaultOverwritePolicy(), "已有文件的处理策略: "+strings.Join(tools.OverwritePolicies, ", "))
+	dryRun := command.Flags.Bool("dry-run", false, "列出将要写入的文件、权限和大小，不写入磁盘")
+	diff := command.Flags.Bool("diff", false, "显示与磁盘上已有文件的统一diff，不写入磁盘")
+	template := command.Flags.String("template", "", "自定义模板：本地目录、git仓库地址或已注册的模板名称")
+	vars := varsFlag{}
+	command.Flags.Var(vars, "var", "模板变量 `key=value`，可重复指定")
+
+	command.Run = func(args []string) error {
+		if len(args) > 1 {
+			return usageErrorf(config.Messages.Errors.UnexpectedArgs, args[1:])
+		}
+
+		policy, err := tools.ParseOverwritePolicy(*overwrite)
+		if err != nil {
+			return newUsageError(err.Error())
+		}
+
+		opts := project.CreateOptions{
+			Dir:       *path,
+			Module:    *module,
+			Framework: *framework,
+			Port:      *port,
+			Domain:    *domain,
+			SkipNginx: *noNginx,
+			Docker:    *docker,
+			Database:  *db,
+			Force:     *force,
+			DryRun:    *dryRun,
+			Diff:      *diff,
+			Overwrite: policy,
+			Template:  *template,
+			Vars:      vars,
+		}
+		if len(args) == 1 {
+			opts.Name = args[0]
+		}
+
+		// 任何命名参数（包括 --yes）都会关闭向导，保证脚本中的调用不会阻塞
+		if !*yes && command.Flags.NFlag() == 0 && tools.IsTerminal(os.Stdin) {
+			var err error
+			opts, err = project.Wizard(tools.NewPrompter(os.Stdin, os.Stdout), opts)
+			if err != nil {
+				return err
+			}
+			h.logger.PrintEmpty()
+			h.logger.Info("等价的非交互命令:")
+			h.logger.Println("  " + opts.CommandLine())
+			h.logger.PrintEmpty()
+		}
+
+		if opts.Name == "" {
+			return newUsageError(config.Messages.Errors.NoProjectName)
+		}
+		if opts.Domain != "" {
+			if err := validateDomains([]string{opts.Domain}); err != nil {
+				return err
+			}
+		}
+		if _, err := templates.LookupFramework(opts.Framework); err != nil {
+			return newUsageError(err.Error())
+		}
+		if opts.Database == "none" {
+			opts.Database = ""
+		}
+		if opts.Database != "" {
+			if _, err := templates.LookupDatabase(opts.Database); err != nil {
+				return newUsageError(err.Error())
+			}
+		}
+		if len(vars) > 0 && *template == "" {
+			return newUsageError("--var 需要与 --template 一起使用")
+		}
+		return h.projectManager.CreateProject(opts)
+	}
+	return command
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/dev"
+)
+
+// devCommand 热重载开发命令
+func (h *CommandHandler) devCommand() *Command {
+	command := newCommand("dev", "[path]", "启动内置热重载开发服务，并在应用前面启动自动刷新浏览器的代理（HTML页面在重新构建后自动刷新）")
+	poll := command.Flags.Bool("poll", false, "使用轮询代替inotify监听文件")
+	delay := command.Flags.Duration("delay", config.DevBuildDelay, "文件变更后等待多久再构建")
+	useTLS := command.Flags.Bool("tls", true, "存在 ssl local 签发的证书时通过 TLS_CERT_FILE 和 TLS_KEY_FILE 传给应用")
+	proxyAddr := command.Flags.String("proxy", config.DevProxyAddr, "自动刷新浏览器的代理监听地址，为空时不启动代理")
+	holdTimeout := command.Flags.Duration("hold-timeout", dev.DefaultHoldTimeout, "应用重启期间代理暂停转发的请求最多等待多久")
+	readyPath := command.Flags.String("ready-path", dev.DefaultReadyPath, "应用重启后代理的就绪检查路径，返回5xx以外的状态码表示就绪")
+	socket := command.Flags.Bool("socket", false, "由开发服务监听应用端口，通过LISTEN_FDS把socket传给每个新进程，重启时端口不会断开")
+
+	command.Run = func(args []string) error {
+		if len(args) > 1 {
+			return usageErrorf(config.Messages.Errors.UnexpectedArgs, args[1:])
+		}
+
+		root := "."
+		if len(args) == 1 {
+			root = args[0]
+		}
+		root, err := filepath.Abs(root)
+		if err != nil {
+			return fmt.Errorf(config.Messages.Errors.GetCwd, err)
+		}
+
+		opts := dev.DefaultOptions(root)
+		opts.Poll = *poll
+		opts.Delay = *delay
+
+		// 按项目清单设置应用端口，环境中已有PORT时保持不变
+		project, err := config.LoadProject(root)
+		if err != nil && !errors.Is(err, config.ErrNoProject) {
+			return err
+		}
+		appPort := os.Getenv("PORT")
+		if project != nil && appPort == "" {
+			appPort = project.Port
+			opts.Env = append(opts.Env, "PORT="+project.Port)
+			h.logger.Info("使用 %s 中的端口 %s", config.ProjectFile, project.Port)
+		}
+
+		// 使用 ssl local 签发的证书，环境中已有证书时保持不变
+		certFile, keyFile := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+		if *useTLS && certFile == "" {
+			var ok bool
+			if certFile, keyFile, ok = localCertificate(root); ok {
+				opts.Env = append(opts.Env, "TLS_CERT_FILE="+certFile, "TLS_KEY_FILE="+keyFile)
+				h.logger.Info("使用本地证书 %s", certFile)
+			}
+		}
+
+		if *socket {
+			if appPort == "" {
+				return usageErrorf("--socket 需要 %s 中的端口或PORT环境变量", config.ProjectFile)
+			}
+			opts.ListenAddr = ":" + appPort
+		}
+
+		// 自动刷新代理转发到应用端口，应用使用HTTPS时代理也使用同一张证书
+		if *proxyAddr != "" {
+			_, proxyPort, _ := net.SplitHostPort(*proxyAddr)
+			switch {
+			case appPort == "":
+				h.logger.Warning("未找到 %s 且没有设置PORT，无法确定应用端口，不启动自动刷新代理", config.ProjectFile)
+			case proxyPort == appPort:
+				return usageErrorf("--proxy 的端口 %s 与应用端口相同", proxyPort)
+			default:
+				scheme := "http"
+				if certFile != "" && keyFile != "" {
+					scheme = "https"
+					opts.CertFile, opts.KeyFile = certFile, keyFile
+				}
+				opts.ProxyAddr = *proxyAddr
+				opts.AppURL = scheme + "://127.0.0.1:" + appPort
+				opts.HoldTimeout = *holdTimeout
+				opts.ReadyPath = *readyPath
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := dev.NewReloader(opts).Run(ctx); err != nil {
+			return fmt.Errorf("启动开发服务失败: %v", err)
+		}
+		return nil
+	}
+	return command
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/nginx"
+	"github.com/yggai/aigo_hotreload/templates"
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// nginxCommand nginx配置命令
+func (h *CommandHandler) nginxCommand() *Command {
+	command := newCommand("nginx", "[domain] | lint [path...] | enable|disable <domain>", "生成nginx配置文件、配置脚本和SSL证书申请脚本，未指定域名时使用aigo.yaml中的全部域名；\n"+
+		"lint 子命令检查配置文件的语法、重复的server_name、proxy_pass目标和listen冲突，默认检查项目的config目录；\n"+
+		"enable 将项目的域名配置链接到sites-enabled并安装htpasswd文件，disable 删除该链接，检查失败时自动恢复原有配置")
+	flags := addSiteFlags(command)
+	nginxRoot := command.Flags.String("nginx-root", nginx.DefaultRoot, "nginx配置目录，enable和disable在其中安装配置，生成的配置从其中引用htpasswd文件和brotli配置片段")
+	testCmd := command.Flags.String("test-cmd", nginx.DefaultTestCommand, "enable和disable检查配置的命令")
+	reloadCmd := command.Flags.String("reload-cmd", nginx.DefaultReloadCommand, "enable和disable检查通过后重新加载nginx的命令，为空时不重新加载")
+
+	command.Run = func(args []string) error {
+		if len(args) > 0 && args[0] == "lint" {
+			return h.nginxLint(*flags.path, args[1:])
+		}
+		if len(args) > 0 && (args[0] == "enable" || args[0] == "disable") {
+			if len(args) != 2 {
+				return newUsageError("用法: aigo_hotreload nginx " + args[0] + " <domain>")
+			}
+			if err := validateDomains(args[1:]); err != nil {
+				return err
+			}
+			deployer := &nginx.Deployer{
+				Root:          *nginxRoot,
+				TestCommand:   *testCmd,
+				ReloadCommand: *reloadCmd,
+				BackupDir:     filepath.Join(*flags.path, config.BackupDir, time.Now().Format(config.BackupTimeFormat), "nginx"),
+				Logf:          h.logger.Info,
+			}
+			return h.nginxDeploy(deployer, args[0], args[1], *flags.path)
+		}
+
+		// 兼容旧的位置参数写法: nginx <domain> <project-path> [port]
+		projectPath, appPort := *flags.path, *flags.port
+		if len(args) > 1 {
+			if len(args) > 3 || command.Visited("path") {
+				return usageErrorf(config.Messages.Errors.UnexpectedArgs, args[1:])
+			}
+			h.logger.Warning("位置参数写法已弃用，请使用 --path 和 --port")
+			projectPath = args[1]
+			if len(args) == 3 {
+				if command.Visited("port") {
+					return usageErrorf(config.Messages.Errors.UnexpectedArgs, args[2:])
+				}
+				appPort = args[2]
+			}
+		}
+
+		project, err := loadProject(projectPath)
+		if err != nil {
+			return err
+		}
+		backend, _ := tools.LookupProxyBackend("nginx")
+		opts, err := flags.resolve(project, args, projectPath, appPort, backend)
+		if err != nil {
+			return err
+		}
+		opts.site.NginxRoot = *nginxRoot
+		if done, err := h.generateSite(backend, opts, args); err != nil || done {
+			return err
+		}
+		h.nginxNextSteps(opts, opts.domains[0])
+		return nil
+	}
+	return command
+}
+
+// nginxNextSteps 输出生成nginx配置后的下一步操作
+func (h *CommandHandler) nginxNextSteps(opts *siteOptions, domain string) {
+	h.logger.Info("下一步:")
+	h.logger.Info("1. 编辑配置文件: vim %s/config/%s", opts.projectPath, domain)
+	h.logger.Info("2. 启用配置: aigo_hotreload nginx enable %s --path %s（或运行 %s/config/setup-nginx.sh %s）", domain, opts.projectPath, opts.projectPath, domain)
+	if opts.site.TLS == nil {
+		h.logger.Info("3. 申请SSL证书: aigo_hotreload ssl issue %s，然后使用 --tls 重新生成HTTPS配置", domain)
+	} else if opts.site.TLS.Cert == "" {
+		h.logger.Info("3. HTTPS配置使用 %s/%s 下的证书，启用前先运行 aigo_hotreload ssl issue %s 申请", templates.LetsEncryptDir, domain, domain)
+	}
+}
+
+// nginxDeploy 启用或禁用域名的nginx配置
+func (h *CommandHandler) nginxDeploy(deployer *nginx.Deployer, action, domain, projectPath string) error {
+	if action == "enable" {
+		if err := deployer.Enable(domain, projectPath); err != nil {
+			return err
+		}
+		h.logger.Success("已启用 %s 的nginx配置", domain)
+		return nil
+	}
+	if err := deployer.Disable(domain); err != nil {
+		return err
+	}
+	h.logger.Success("已禁用 %s 的nginx配置", domain)
+	return nil
+}
+
+// nginxLint 检查nginx配置文件，存在错误级别的问题时返回错误
+func (h *CommandHandler) nginxLint(projectPath string, paths []string) error {
+	if len(paths) == 0 {
+		paths = []string{filepath.Join(projectPath, "config")}
+	}
+	issues, err := nginx.LintPaths(paths...)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		if issue.Severity == nginx.SeverityError {
+			h.logger.Error("%s", issue)
+		} else {
+			h.logger.Warning("%s", issue)
+		}
+	}
+	if nginx.HasErrors(issues) {
+		return fmt.Errorf("nginx配置检查发现 %d 个问题", len(issues))
+	}
+	if len(issues) == 0 {
+		h.logger.Success("nginx配置检查通过: %s", strings.Join(paths, " "))
+	}
+	return nil
+}
+
+// parseUpstream 解析负载均衡参数，未指定 --upstream 时返回nil
+func parseUpstream(specs []string, method string, keepalive int, maxFails, failTimeout string) (*templates.Upstream, error) {
+	if len(specs) == 0 {
+		if method != "" || keepalive != 0 || maxFails != "" || failTimeout != "" {
+			return nil, fmt.Errorf("--balance、--keepalive、--max-fails 和 --fail-timeout 需要与 --upstream 一起使用")
+		}
+		return nil, nil
+	}
+
+	upstream := &templates.Upstream{Keepalive: keepalive}
+	if method != templates.BalanceRoundRobin {
+		upstream.Method = method
+	}
+	for _, spec := range specs {
+		server, err := templates.ParseUpstreamServer(spec)
+		if err != nil {
+			return nil, err
+		}
+		if server.MaxFails == nil && maxFails != "" {
+			if err := server.SetOption("max_fails", maxFails); err != nil {
+				return nil, err
+			}
+		}
+		if server.FailTimeout == "" && failTimeout != "" {
+			if err := server.SetOption("fail_timeout", failTimeout); err != nil {
+				return nil, err
+			}
+		}
+		upstream.Servers = append(upstream.Servers, server)
+	}
+	if err := upstream.Validate(); err != nil {
+		return nil, err
+	}
+	return upstream, nil
+}
+
+// parseLocations 解析路由参数，未指定 --location 时返回nil
+func parseLocations(specs []string) ([]templates.Location, error) {
+	var locations []templates.Location
+	for _, spec := range specs {
+		location, err := templates.ParseLocation(spec)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	if err := templates.ValidateLocations(locations); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// parseCache 解析缓存规则参数，未指定 --cache 时返回nil
+func parseCache(specs []string) ([]templates.CacheRule, error) {
+	var rules []templates.CacheRule
+	for _, spec := range specs {
+		rule, err := templates.ParseCacheRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseAuthUsers 解析basic auth用户，返回全部用户和其中随机生成密码的用户
+func parseAuthUsers(specs []string, hash string) (users, generated []tools.AuthUser, err error) {
+	if err := nginx.ValidateHash(hash); err != nil {
+		return nil, nil, err
+	}
+	for _, spec := range specs {
+		name, password, ok := strings.Cut(spec, ":")
+		if err := nginx.ValidateUser(name); err != nil {
+			return nil, nil, err
+		}
+		user := tools.AuthUser{Name: name, Password: password}
+		if !ok || password == "" {
+			if user.Password, err = nginx.GeneratePassword(); err != nil {
+				return nil, nil, err
+			}
+			generated = append(generated, user)
+		}
+		users = append(users, user)
+	}
+	return users, generated, nil
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/nginx"
+	"github.com/yggai/aigo_hotreload/templates"
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// proxyCommand 反向代理配置命令
+func (h *CommandHandler) proxyCommand() *Command {
+	command := newCommand("proxy", "[domain]", "使用nginx、Caddy或Traefik生成反向代理配置和配置脚本，参数与 nginx 命令相同；\n"+
+		"未指定 --backend 时使用aigo.yaml中记录的反向代理，否则为nginx")
+	backendName := command.Flags.String("backend", "", "反向代理服务器: "+strings.Join(tools.ProxyBackendNames(), ", "))
+	flags := addSiteFlags(command)
+
+	command.Run = func(args []string) error {
+		if len(args) > 1 {
+			return usageErrorf(config.Messages.Errors.UnexpectedArgs, args[1:])
+		}
+		project, err := loadProject(*flags.path)
+		if err != nil {
+			return err
+		}
+		name := *backendName
+		if name == "" {
+			name = tools.ProxyBackends[0].Name
+			if project != nil && project.Proxy != "" {
+				name = project.Proxy
+			}
+		}
+		backend, err := tools.LookupProxyBackend(name)
+		if err != nil {
+			return newUsageError(err.Error())
+		}
+
+		opts, err := flags.resolve(project, args, *flags.path, *flags.port, backend)
+		if err != nil {
+			return err
+		}
+		if done, err := h.generateSite(backend, opts, args); err != nil || done {
+			return err
+		}
+
+		domain := opts.domains[0]
+		switch backend.Name {
+		case "nginx":
+			h.nginxNextSteps(opts, domain)
+		case "caddy":
+			h.logger.Info("下一步: 运行配置脚本 %s/config/setup-caddy.sh %s", opts.projectPath, domain)
+			if opts.site.TLS != nil && opts.site.TLS.Cert == "" {
+				h.logger.Info("Caddy会自动申请和续期 %s 的证书，需要80和443端口可以从公网访问", domain)
+			}
+		case "traefik":
+			h.logger.Info("下一步: 运行配置脚本 %s/config/setup-traefik.sh %s [动态配置目录]", opts.projectPath, domain)
+		}
+		return nil
+	}
+	return command
+}
+
+// siteFlags nginx和proxy命令共用的站点参数
+type siteFlags struct {
+	command     *Command
+	path        *string
+	port        *string
+	force       *bool
+	overwrite   *string
+	tls         *bool
+	cert        *string
+	key         *string
+	upstreams   listFlag
+	balance     *string
+	keepalive   *int
+	maxFails    *string
+	failTimeout *string
+	locations   listFlag
+	authUsers   listFlag
+	authHash    *string
+	gzip        *bool
+	brotli      *bool
+	cacheRules  listFlag
+	dryRun      *bool
+	diff        *bool
+}
+
+// addSiteFlags 为命令添加站点参数
+func addSiteFlags(command *Command) *siteFlags {
+	f := &siteFlags{command: command}
+	f.path = command.Flags.String("path", ".", "项目目录")
+	f.port = command.Flags.String("port", "", "应用监听端口（默认读取aigo.yaml，否则为"+config.DefaultPort+"）")
+	f.force = command.Flags.Bool("force", false, "允许覆盖被手动修改的配置文件")
+	f.overwrite = command.Flags.String("overwrite", tools.DefaultOverwritePolicy(), "已有文件的处理策略: "+strings.Join(tools.OverwritePolicies, ", "))
+	f.tls = command.Flags.Bool("tls", false, "生成HTTPS配置（HTTP重定向到HTTPS），默认使用Let's Encrypt证书")
+	f.cert = command.Flags.String("cert", "", "自定义证书链文件路径，隐含 --tls")
+	f.key = command.Flags.String("key", "", "自定义私钥文件路径，隐含 --tls")
+	command.Flags.Var(&f.upstreams, "upstream", "负载均衡后端 `addr[,weight=N][,max_fails=N][,fail_timeout=T]`，可重复指定")
+	f.balance = command.Flags.String("balance", "", "负载均衡方式: "+strings.Join(templates.BalanceMethods, ", ")+"（默认 "+templates.BalanceRoundRobin+"）")
+	f.keepalive = command.Flags.Int("keepalive", 0, "每个worker保留的到后端的空闲长连接数")
+	f.maxFails = command.Flags.String("max-fails", "", "未单独指定时每个后端的 max_fails")
+	f.failTimeout = command.Flags.String("fail-timeout", "", "未单独指定时每个后端的 fail_timeout")
+	command.Flags.Var(&f.locations, "location", "按路径转发或提供静态文件的路由 `path[=target][,strip_prefix][,websocket][,timeout=T][,header=Name:Value]` 或 path,root=DIR|alias=DIR[,spa][,index=FILE]，"+
+		"均可加访问控制 rate=R、burst=N、allow=CIDR、deny=CIDR、auth，可重复指定")
+	command.Flags.Var(&f.authUsers, "auth-user", "写入 config/<domain>.htpasswd 的basic auth用户 `name[:password]`，省略密码时随机生成，可重复指定")
+	f.authHash = command.Flags.String("auth-hash", "", "htpasswd密码哈希算法: "+strings.Join(nginx.HashMethods, ", ")+"（默认caddy为bcrypt，其余为apr1）")
+	f.gzip = command.Flags.Bool("gzip", false, "启用gzip压缩")
+	f.brotli = command.Flags.Bool("brotli", false, "启用brotli压缩（nginx未安装brotli模块时由配置脚本跳过）")
+	command.Flags.Var(&f.cacheRules, "cache", "按扩展名设置浏览器缓存 `ext[,ext...]=expires`，如 js,css=30d，可重复指定")
+	f.dryRun = command.Flags.Bool("dry-run", false, "列出将要写入的文件、权限和大小，不写入磁盘")
+	f.diff = command.Flags.Bool("diff", false, "显示与磁盘上已有文件的统一diff，不写入磁盘")
+	return f
+}
+
+// siteOptions 合并命令行参数和项目清单后的站点配置
+type siteOptions struct {
+	projectPath string
+	port        string
+	project     *config.Project
+	domains     []string
+	site        templates.Data // 证书、负载均衡、路由、压缩和缓存配置
+	users       []tools.AuthUser
+	generated   []tools.AuthUser // 随机生成密码的用户
+	authHash    string
+	writer      *tools.FileWriter
+}
+
+// loadProject 读取项目清单，没有清单时返回nil
+func loadProject(projectPath string) (*config.Project, error) {
+	project, err := config.LoadProject(projectPath)
+	if err != nil && !errors.Is(err, config.ErrNoProject) {
+		return nil, err
+	}
+	return project, nil
+}
+
+// validateDomains 在读写任何文件之前检查域名，避免越出配置目录或注入配置指令
+func validateDomains(domains []string) error {
+	for _, domain := range domains {
+		if err := config.ValidateDomain(domain); err != nil {
+			return newUsageError(err.Error())
+		}
+	}
+	return nil
+}
+
+// resolve 合并命令行参数和项目清单: 命令行参数优先，其次为项目清单中记录的配置
+func (f *siteFlags) resolve(project *config.Project, args []string, projectPath, appPort string, backend tools.ProxyBackend) (*siteOptions, error) {
+	opts := &siteOptions{projectPath: projectPath, project: project}
+
+	// 域名和端口未在命令行指定时读取项目清单
+	switch {
+	case len(args) > 0:
+		opts.domains = args[:1]
+	case project != nil && len(project.Domains) > 0:
+		opts.domains = project.Domains
+	default:
+		return nil, newUsageError(config.Messages.Errors.NoDomain)
+	}
+	if err := validateDomains(opts.domains); err != nil {
+		return nil, err
+	}
+	opts.port = appPort
+	if opts.port == "" {
+		opts.port = config.DefaultPort
+		if project != nil {
+			opts.port = project.Port
+		}
+	}
+
+	// 证书配置
+	if (*f.cert == "") != (*f.key == "") {
+		return nil, newUsageError("--cert 和 --key 需要同时指定")
+	}
+	switch {
+	case *f.tls || *f.cert != "":
+		opts.site.TLS = &templates.TLS{Cert: *f.cert, Key: *f.key}
+	case project != nil && project.TLS != nil:
+		opts.site.TLS = &templates.TLS{Cert: project.TLS.Cert, Key: project.TLS.Key}
+	}
+
+	// 负载均衡
+	upstream, err := parseUpstream(f.upstreams, *f.balance, *f.keepalive, *f.maxFails, *f.failTimeout)
+	if err != nil {
+		return nil, newUsageError(err.Error())
+	}
+	if upstream == nil && project != nil && project.Upstream != nil {
+		if upstream, err = templates.NewUpstream(project.Upstream); err != nil {
+			return nil, err
+		}
+	}
+	opts.site.Upstream = upstream
+
+	// 路由
+	routes, err := parseLocations(f.locations)
+	if err != nil {
+		return nil, newUsageError(err.Error())
+	}
+	if routes == nil && project != nil && len(project.Locations) > 0 {
+		if routes, err = templates.NewLocations(project.Locations); err != nil {
+			return nil, err
+		}
+	}
+	opts.site.Locations = routes
+
+	// basic auth用户只写入htpasswd文件，需要至少一个路由启用auth
+	opts.authHash = *f.authHash
+	if opts.authHash == "" {
+		opts.authHash = backend.AuthHash
+	}
+	if opts.users, opts.generated, err = parseAuthUsers(f.authUsers, opts.authHash); err != nil {
+		return nil, newUsageError(err.Error())
+	}
+	if len(opts.users) > 0 && !opts.site.AuthEnabled() {
+		return nil, newUsageError("--auth-user 需要至少一个路由启用auth，如 --location /,auth")
+	}
+
+	// 压缩和缓存
+	if project != nil && project.Compression != nil {
+		opts.site.Compression = &templates.Compression{Gzip: project.Compression.Gzip, Brotli: project.Compression.Brotli}
+	}
+	if f.command.Visited("gzip") || f.command.Visited("brotli") {
+		if opts.site.Compression == nil {
+			opts.site.Compression = &templates.Compression{}
+		}
+		if f.command.Visited("gzip") {
+			opts.site.Compression.Gzip = *f.gzip
+		}
+		if f.command.Visited("brotli") {
+			opts.site.Compression.Brotli = *f.brotli
+		}
+	}
+	cache, err := parseCache(f.cacheRules)
+	if err != nil {
+		return nil, newUsageError(err.Error())
+	}
+	if cache == nil && project != nil && len(project.Cache) > 0 {
+		if cache, err = templates.NewCache(project.Cache); err != nil {
+			return nil, err
+		}
+	}
+	opts.site.Cache = cache
+
+	policy, err := tools.ParseOverwritePolicy(*f.overwrite)
+	if err != nil {
+		return nil, newUsageError(err.Error())
+	}
+	// 已被手动修改的配置（包括不是由本工具生成的）需要 --force 才会覆盖
+	if opts.writer, err = tools.NewProjectWriter(projectPath, tools.SelectWriteMode(*f.dryRun, *f.diff), policy, *f.force); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// generateSite 为全部域名生成反向代理配置并记录到项目清单，预览模式下返回done为true
+func (h *CommandHandler) generateSite(backend tools.ProxyBackend, opts *siteOptions, args []string) (done bool, err error) {
+	writer := opts.writer
+	if writer.Mode() == tools.WriteDryRun {
+		h.logger.Info("[dry-run] 将为 %s 生成%s配置 (端口 %s):", strings.Join(opts.domains, ", "), backend.Name, opts.port)
+	}
+
+	generator := backend.New()
+	generator.SetWriter(writer)
+	generator.SetSite(opts.site)
+	generator.SetAuthUsers(opts.users, opts.authHash)
+	for _, domain := range opts.domains {
+		if err := generator.GenerateAll(domain, opts.projectPath, opts.port); err != nil {
+			return false, fmt.Errorf("生成%s配置失败: %v", backend.Name, err)
+		}
+	}
+
+	// 已有项目清单时记录新域名、反向代理和站点配置
+	if opts.project != nil && recordSite(opts.project, args, backend.Name, opts.site) {
+		content, err := opts.project.Marshal()
+		if err != nil {
+			return false, err
+		}
+		if err := writer.Patch(filepath.Join(opts.projectPath, config.ProjectFile), content, config.FilePermission); err != nil {
+			return false, fmt.Errorf("更新%s失败: %v", config.ProjectFile, err)
+		}
+	}
+	if err := writer.Finish(); err != nil {
+		return false, err
+	}
+	if writer.Preview() {
+		return true, nil
+	}
+
+	h.logger.Success("%s配置生成完成", backend.Name)
+	for _, user := range opts.generated {
+		h.logger.Warning("已为用户 %s 生成随机密码: %s（只显示这一次，请妥善保存）", user.Name, user.Password)
+	}
+	return false, nil
+}
+
+// recordSite 在项目清单中记录命令行指定的域名、使用的反向代理以及site中的证书、负载均衡、路由、压缩和缓存配置，
+// 返回清单是否有变化；nginx记录为组件，其他反向代理记录在proxy字段
+func recordSite(project *config.Project, args []string, backend string, site templates.Data) bool {
+	changed := false
+	if backend == "nginx" {
+		changed = project.AddComponent("nginx")
+	}
+	if len(args) > 0 && project.AddDomain(args[0]) {
+		changed = true
+	}
+	before := *project
+	if backend != "nginx" || project.Proxy != "" {
+		project.Proxy = backend
+	}
+	recorded := site.Project()
+	if recorded.TLS != nil {
+		project.TLS = recorded.TLS
+	}
+	if recorded.Upstream != nil {
+		project.Upstream = recorded.Upstream
+	}
+	if recorded.Locations != nil {
+		project.Locations = recorded.Locations
+	}
+	if recorded.Compression != nil {
+		project.Compression = recorded.Compression
+	}
+	if recorded.Cache != nil {
+		project.Cache = recorded.Cache
+	}
+	return changed || !reflect.DeepEqual(before, *project)
+}
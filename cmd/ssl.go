@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/nginx"
+	"github.com/yggai/aigo_hotreload/ssl"
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// sslTimeout 一次申请或续期全部证书的超时时间
+const sslTimeout = 10 * time.Minute
+
+// sslCommand SSL证书命令
+func (h *CommandHandler) sslCommand() *Command {
+	command := newCommand("ssl", "<issue|renew|local|status> [domain...|cert-file...]", "使用内置的ACME客户端通过HTTP-01验证申请和续期证书，不需要安装certbot；\n"+
+		"未指定域名时 issue 使用aigo.yaml中的全部域名，renew 检查证书目录中的全部证书；\n"+
+		"local 使用本机的开发CA签发证书（默认为localhost），保存到项目的 "+config.LocalCertDir+" 目录，dev 命令自动使用；\n"+
+		"status 列出证书目录和nginx配置引用的证书，有证书在 --days 天内过期或无法读取时退出码为1，适合在cron中运行")
+	path := command.Flags.String("path", ".", "项目目录")
+	dir := command.Flags.String("dir", ssl.DefaultDir, "账户私钥和证书的保存目录，证书保存在 live/<domain>/ 下；local 默认为项目的 "+config.LocalCertDir)
+	caDir := command.Flags.String("ca-dir", "", "local 使用的本地CA目录（默认 ~/.aigo/ca）")
+	webroot := command.Flags.String("webroot", templates.ACMEWebroot, "nginx中 /.well-known/acme-challenge/ 的root目录")
+	email := command.Flags.String("email", "", "ACME账户的联系邮箱，用于接收证书过期提醒")
+	server := command.Flags.String("server", "", "ACME服务的目录地址（默认 "+ssl.LetsEncryptURL+"）")
+	staging := command.Flags.Bool("staging", false, "使用Let's Encrypt的测试环境，证书不受浏览器信任")
+	caCert := command.Flags.String("ca-cert", "", "额外信任的ACME服务根证书，用于Pebble等本地测试服务")
+	days := command.Flags.Int("days", int(ssl.DefaultRenewBefore.Hours()/24), "renew 只续期剩余有效期少于该天数的证书；status 的过期提醒天数")
+	var confs listFlag
+	command.Flags.Var(&confs, "conf", "status 扫描的nginx配置文件或目录，可重复指定（默认为项目的config目录和 "+nginx.DefaultRoot+"/sites-enabled）")
+	reloadCmd := command.Flags.String("reload-cmd", nginx.DefaultReloadCommand, "申请或续期证书后重新加载nginx的命令，为空时不重新加载")
+
+	command.Run = func(args []string) error {
+		if len(args) < 1 {
+			return newUsageError("错误: 请提供子命令 issue、renew、local 或 status")
+		}
+		action, domains := args[0], args[1:]
+		if action != "status" {
+			if err := validateDomains(domains); err != nil {
+				return err
+			}
+		}
+		if action == "local" {
+			certDir := filepath.Join(*path, config.LocalCertDir)
+			if command.Visited("dir") {
+				certDir = *dir
+			}
+			return h.sslLocal(*caDir, certDir, domains)
+		}
+		if action == "status" {
+			dirs := []string{*dir, filepath.Join(*path, config.LocalCertDir)}
+			if len(confs) == 0 {
+				for _, conf := range []string{filepath.Join(*path, "config"), filepath.Join(nginx.DefaultRoot, "sites-enabled")} {
+					if _, err := os.Stat(conf); err == nil {
+						confs = append(confs, conf)
+					}
+				}
+			}
+			return h.sslStatus(dirs, confs, domains, time.Duration(*days)*24*time.Hour)
+		}
+		if action != "issue" && action != "renew" {
+			return usageErrorf("未知的ssl子命令: %s", action)
+		}
+		if *staging && *server != "" {
+			return newUsageError("--staging 和 --server 不能同时指定")
+		}
+
+		issuer := ssl.NewIssuer(*dir, *webroot)
+		issuer.Email = *email
+		issuer.Logf = h.logger.Info
+		switch {
+		case *server != "":
+			issuer.DirectoryURL = *server
+		case *staging:
+			issuer.DirectoryURL = ssl.LetsEncryptStagingURL
+		}
+		if *caCert != "" {
+			client, err := ssl.HTTPClient(*caCert)
+			if err != nil {
+				return err
+			}
+			issuer.HTTPClient = client
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), sslTimeout)
+		defer cancel()
+		var changed []string
+		if action == "issue" {
+			if len(domains) == 0 {
+				project, err := loadProject(*path)
+				if err != nil {
+					return err
+				}
+				if project == nil || len(project.Domains) == 0 {
+					return newUsageError(config.Messages.Errors.NoDomain)
+				}
+				domains = project.Domains
+				if err := validateDomains(domains); err != nil {
+					return err
+				}
+			}
+			// 每个域名单独一张证书，与每个域名一份nginx配置对应
+			for _, domain := range domains {
+				h.logger.Info("正在为 %s 申请证书...", domain)
+				if _, err := issuer.Issue(ctx, []string{domain}); err != nil {
+					return err
+				}
+				changed = append(changed, domain)
+			}
+		} else {
+			renewed, err := issuer.Renew(ctx, time.Duration(*days)*24*time.Hour, domains)
+			changed = renewed
+			if err != nil {
+				h.reloadAfterSSL(*reloadCmd, changed)
+				return err
+			}
+		}
+
+		h.reloadAfterSSL(*reloadCmd, changed)
+		if action == "issue" {
+			h.logger.Success("证书申请完成: %s", strings.Join(changed, ", "))
+			h.logger.Info("下一步: aigo_hotreload nginx --tls 生成HTTPS配置；在cron中定期运行 aigo_hotreload ssl renew 续期")
+		} else if len(changed) > 0 {
+			h.logger.Success("证书续期完成: %s", strings.Join(changed, ", "))
+		}
+		return nil
+	}
+	return command
+}
+
+// reloadAfterSSL 有证书更新时重新加载nginx，失败只提示，证书已经保存
+func (h *CommandHandler) reloadAfterSSL(command string, changed []string) {
+	if len(changed) == 0 || command == "" {
+		return
+	}
+	if err := nginx.Reload(command); err != nil {
+		h.logger.Warning("证书已保存，但%v", err)
+		return
+	}
+	h.logger.Info("已重新加载nginx")
+}
+
+// sslLocal 使用本地开发CA签发证书，本地CA不存在时生成
+func (h *CommandHandler) sslLocal(caDir, certDir string, names []string) error {
+	if caDir == "" {
+		var err error
+		if caDir, err = config.LocalCADir(); err != nil {
+			return err
+		}
+	}
+	if len(names) == 0 {
+		names = ssl.DefaultLocalNames
+	}
+	ca, created, err := ssl.LoadOrCreateLocalCA(caDir)
+	if err != nil {
+		return err
+	}
+	if created {
+		h.logger.Success("已生成本地CA: %s", ca.CertFile())
+	}
+
+	chain, key, err := ca.Issue(names)
+	if err != nil {
+		return err
+	}
+	store := ssl.Store{Dir: certDir}
+	name := ssl.LocalCertName(names)
+	if err := store.Save(name, chain, key); err != nil {
+		return err
+	}
+	certFile, _ := filepath.Abs(filepath.Join(store.LiveDir(name), ssl.FullchainFile))
+	keyFile, _ := filepath.Abs(filepath.Join(store.LiveDir(name), ssl.PrivkeyFile))
+	h.logger.Success("已签发本地证书: %s", strings.Join(names, ", "))
+	h.logger.Info("证书: %s", certFile)
+	h.logger.Info("私钥: %s", keyFile)
+
+	h.logger.Info("浏览器需要信任本地CA（SHA-256 %s），只需运行一次:", ca.Fingerprint())
+	for _, line := range ssl.TrustInstructions(runtime.GOOS, ca.CertFile()) {
+		h.logger.Info("  %s", line)
+	}
+	h.logger.Info("下一步:")
+	h.logger.Info("1. aigo_hotreload dev 自动通过 TLS_CERT_FILE 和 TLS_KEY_FILE 把证书传给应用，应用改为HTTPS监听")
+	h.logger.Info("2. 使用nginx时: aigo_hotreload nginx %s --cert %s --key %s", names[0], certFile, keyFile)
+	for _, n := range names {
+		if n != "localhost" && !strings.HasSuffix(n, ".localhost") && net.ParseIP(n) == nil {
+			h.logger.Info("3. 在 /etc/hosts 中把 %s 等域名指向 127.0.0.1", n)
+			break
+		}
+	}
+	return nil
+}
+
+// sslStatus 检查证书文件、证书目录和nginx配置引用的证书，有证书在threshold内过期或无法读取时返回错误
+func (h *CommandHandler) sslStatus(dirs, confs, files []string, threshold time.Duration) error {
+	var certs []*ssl.CertificateInfo
+	index := map[string]*ssl.CertificateInfo{}
+	// 同一个证书文件只检查一次，记录全部来源
+	add := func(path, source string) {
+		key := filepath.Clean(path)
+		if abs, err := filepath.Abs(path); err == nil {
+			key = abs
+		}
+		info, ok := index[key]
+		if !ok {
+			inspected := ssl.Inspect(path)
+			info = &inspected
+			index[key] = info
+			certs = append(certs, info)
+		}
+		info.Sources = append(info.Sources, source)
+	}
+
+	// 无法读取的证书目录和无法解析的配置计为问题，继续检查其他来源
+	problems := 0
+	for _, file := range files {
+		add(file, "命令行")
+	}
+	for _, dir := range dirs {
+		store := ssl.Store{Dir: dir}
+		domains, err := store.Domains()
+		if err != nil {
+			problems++
+			h.logger.Error("无法读取证书目录 %s: %v", dir, err)
+		}
+		for _, domain := range domains {
+			add(filepath.Join(store.LiveDir(domain), ssl.FullchainFile), dir)
+		}
+	}
+	for _, conf := range confs {
+		refs, err := nginx.CertificatePaths(conf)
+		for _, err := range splitErrors(err) {
+			problems++
+			h.logger.Error("无法解析nginx配置: %v", err)
+		}
+		for _, ref := range refs {
+			add(ref.Path, ref.Directive.Position())
+		}
+	}
+	if len(certs) == 0 && problems == 0 {
+		h.logger.Warning("没有找到证书")
+		return nil
+	}
+
+	now := time.Now()
+	for _, cert := range certs {
+		sources := strings.Join(cert.Sources, ", ")
+		if cert.Err != nil {
+			problems++
+			h.logger.Error("%s: 无法读取证书: %v（来源: %s）", cert.Path, cert.Err, sources)
+			continue
+		}
+		days := cert.DaysLeft(now)
+		line := fmt.Sprintf("%s: %s，颁发者 %s，到期 %s", cert.Path, strings.Join(cert.Names, ", "), cert.Issuer, cert.NotAfter.Format(time.DateOnly))
+		switch {
+		case !cert.NotAfter.After(now):
+			problems++
+			h.logger.Error("%s，已过期 %d 天（来源: %s）", line, -days, sources)
+		case cert.Expiring(now, threshold):
+			problems++
+			h.logger.Warning("%s，剩余 %d 天（来源: %s）", line, days, sources)
+		default:
+			h.logger.Success("%s，剩余 %d 天", line, days)
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("发现 %d 个问题: 证书已过期、将在 %d 天内过期，或证书、证书目录、nginx配置无法读取", problems, int(threshold.Hours()/24))
+	}
+	return nil
+}
+
+// splitErrors 展开errors.Join合并的错误，err为nil时返回nil
+func splitErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	if err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+// localCertificate 返回项目中 ssl local 签发的证书，优先使用localhost的证书
+func localCertificate(root string) (certFile, keyFile string, ok bool) {
+	store := ssl.Store{Dir: filepath.Join(root, config.LocalCertDir)}
+	names, err := store.Domains()
+	if err != nil || len(names) == 0 {
+		return "", "", false
+	}
+	name := names[0]
+	for _, n := range names {
+		if n == "localhost" {
+			name = n
+		}
+	}
+	dir := store.LiveDir(name)
+	return filepath.Join(dir, ssl.FullchainFile), filepath.Join(dir, ssl.PrivkeyFile), true
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// templateCommand 自定义模板管理命令
+func (h *CommandHandler) templateCommand() *Command {
+	command := newCommand("template", "<list|add|remove> [name] [source]", "管理 ~/.aigo/templates 中的自定义项目模板")
+
+	command.Run = func(args []string) error {
+		if len(args) < 1 {
+			return newUsageError("错误: 请提供子命令 list、add 或 remove")
+		}
+
+		manager := tools.NewTemplateManager()
+		switch args[0] {
+		case "list":
+			if len(args) > 1 {
+				return usageErrorf(config.Messages.Errors.UnexpectedArgs, args[1:])
+			}
+			list, err := manager.List()
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				h.logger.Info("尚未注册任何模板，可使用 aigo_hotreload template add <name> <dir|git-url> 注册")
+				return nil
+			}
+			for _, custom := range list {
+				h.logger.Info("%-20s %s", filepath.Base(custom.Dir), custom.Manifest.Description)
+			}
+			return nil
+
+		case "add":
+			if len(args) != 3 {
+				return newUsageError("用法: aigo_hotreload template add <name> <dir|git-url>")
+			}
+			return manager.Add(args[1], args[2])
+
+		case "remove":
+			if len(args) != 2 {
+				return newUsageError("用法: aigo_hotreload template remove <name>")
+			}
+			return manager.Remove(args[1])
+		}
+		return usageErrorf("未知的模板子命令: %s", args[0])
+	}
+	return command
+}
@@ -9,20 +9,30 @@ var Messages = struct {
 	ToolDescription string
 	UsageHeader     string
 	Commands        struct {
-		Create  string
-		Nginx   string
-		Version string
-		Help    string
+		Create    string
+		Add       string
+		Dev       string
+		Nginx     string
+		NginxLint string
+		NginxSite string
+		Proxy     string
+		SSL       string
+		Template  string
+		Version   string
+		Help      string
 	}
 	Example         string
 	Errors          struct {
 		NoProjectName   string
+		NoDomain        string
 		EmptyName       string
 		GetCwd          string
 		DirExists       string
 		CreateDir       string
 		GenerateFiles   string
 		UnknownCommand  string
+		InvalidArgs     string
+		UnexpectedArgs  string
 	}
 	Success         struct {
 		Creating        string
@@ -31,7 +41,7 @@ var Messages = struct {
 		Commands        struct {
 			Cd      string
 			Tidy    string
-			Air     string
+			Dev     string
 			Access  string
 		}
 		Deployment      struct {
@@ -45,33 +55,53 @@ var Messages = struct {
 	ToolDescription: "aigo_hotreload - Go热重载项目脚手架工具",
 	UsageHeader:     "用法:",
 	Commands: struct {
-		Create  string
-		Nginx   string
-		Version string
-		Help    string
+		Create    string
+		Add       string
+		Dev       string
+		Nginx     string
+		NginxLint string
+		NginxSite string
+		Proxy     string
+		SSL       string
+		Template  string
+		Version   string
+		Help      string
 	}{
-		Create:  "  aigo_hotreload create <project-name>  创建新的热重载项目",
-		Nginx:   "  aigo_hotreload nginx <domain> <path> [port] 生成nginx配置",
-		Version: "  aigo_hotreload version               显示版本信息",
-		Help:    "  aigo_hotreload help                  显示帮助信息",
+		Create:    "  aigo_hotreload create [project-name]  创建新的热重载项目（终端中不带参数时启动交互向导）",
+		Add:       "  aigo_hotreload add <component>...     向已有项目添加组件（dockerfile、nginx、db等）",
+		Dev:       "  aigo_hotreload dev [path]             启动内置热重载开发服务",
+		Nginx:     "  aigo_hotreload nginx [domain] [--path dir] [--port 8888]  生成nginx配置（默认读取aigo.yaml）",
+		NginxLint: "  aigo_hotreload nginx lint [path...]  检查nginx配置（默认检查项目的config目录），无需安装nginx",
+		NginxSite: "  aigo_hotreload nginx enable|disable <domain>  启用或禁用站点，nginx -t 失败时自动恢复原有配置",
+		Proxy:     "  aigo_hotreload proxy [domain] --backend caddy|traefik  使用Caddy或Traefik代替nginx生成反向代理配置",
+		SSL:       "  aigo_hotreload ssl <issue|renew|local|status> [domain...]  使用内置ACME客户端申请和续期证书、签发本地开发证书或检查证书到期",
+		Template:  "  aigo_hotreload template <list|add|remove>  管理自定义项目模板",
+		Version:   "  aigo_hotreload version               显示版本信息",
+		Help:      "  aigo_hotreload help [command]        显示帮助信息",
 	},
-	Example: "示例:\n  aigo_hotreload create my-api",
+	Example: "示例:\n  aigo_hotreload create my-api\n  aigo_hotreload nginx api.example.com --path ./my-api --port 8888\n\n使用 aigo_hotreload help <command> 查看命令的全部参数",
 	Errors: struct {
 		NoProjectName   string
+		NoDomain        string
 		EmptyName       string
 		GetCwd          string
 		DirExists       string
 		CreateDir       string
 		GenerateFiles   string
 		UnknownCommand  string
+		InvalidArgs     string
+		UnexpectedArgs  string
 	}{
-		NoProjectName:  "错误: 请提供项目名称\n用法: aigo_hotreload create <project-name>",
+		NoProjectName:  "错误: 请提供项目名称",
+		NoDomain:       "错误: 请提供域名",
 		EmptyName:      "错误: 项目名称不能为空",
 		GetCwd:         "错误: 无法获取当前目录: %v",
-		DirExists:      "错误: 目录 %s 已存在",
+		DirExists:      "错误: 目录 %s 已存在，使用 --force 继续生成",
 		CreateDir:      "错误: 无法创建目录 %s: %v",
 		GenerateFiles:  "错误: 生成项目文件失败: %v",
 		UnknownCommand: "未知命令: %s",
+		InvalidArgs:    "参数错误: %v",
+		UnexpectedArgs: "多余的参数: %v",
 	},
 	Success: struct {
 		Creating        string
@@ -80,7 +110,7 @@ var Messages = struct {
 		Commands        struct {
 			Cd      string
 			Tidy    string
-			Air     string
+			Dev     string
 			Access  string
 		}
 		Deployment      struct {
@@ -95,22 +125,22 @@ var Messages = struct {
 		Commands: struct {
 			Cd      string
 			Tidy    string
-			Air     string
+			Dev     string
 			Access  string
 		}{
 			Cd:     "  cd %s",
 			Tidy:   "  go mod tidy",
-			Air:    "  air",
-			Access: "然后访问 " + BaseURL + " 查看效果",
+			Dev:    "  aigo_hotreload dev",
+			Access: "然后访问 http://" + DefaultHost + ":%s 查看效果",
 		},
 		Deployment: struct {
 			NginxSetup     string
 			SSLSetup       string
 			DomainAccess   string
 		}{
-			NginxSetup:   "  # 配置nginx: ./config/setup-nginx.sh your-domain.com",
-			SSLSetup:     "  # 申请SSL: ./scripts/apply-ssl.sh your-domain.com",
-			DomainAccess: "  # 域名访问: https://your-domain.com",
+			NginxSetup:   "  # 配置nginx: ./config/setup-nginx.sh %s",
+			SSLSetup:     "  # 申请SSL: ./scripts/apply-ssl.sh %s",
+			DomainAccess: "  # 域名访问: https://%s",
 		},
 	},
 }
\ No newline at end of file
@@ -1,9 +1,17 @@
 package config
 
+import "time"
+
 // 服务器相关常量
 const (
-	DefaultPort = "8888"
-	DefaultHost = "localhost"
+	DefaultPort   = "8888"
+	DefaultHost   = "localhost"
+	DefaultDomain = "your-domain.com"
+)
+
+// 生成项目相关常量
+const (
+	DefaultGoVersion = "1.24"
 )
 
 // URL相关常量
@@ -20,10 +28,20 @@ const (
 	AirCommand    = "air"
 )
 
+// 开发服务相关常量
+const (
+	DevBuildOutput = "tmp/main"
+	DevBuildDelay  = 1000 * time.Millisecond
+	DevKillDelay   = 2 * time.Second
+	DevProxyAddr   = "localhost:3000" // 自动刷新浏览器的代理地址
+)
+
 // 文件权限常量
 const (
-	DirPermission  = 0755
-	FilePermission = 0644
+	DirPermission    = 0755
+	FilePermission   = 0644
+	ScriptPermission = 0755
+	SecretPermission = 0600 // 密码等敏感文件
 )
 
 // 状态消息常量
@@ -34,6 +52,7 @@ const (
 
 // 时间格式常量
 const (
-	TimeFormat = "2006-01-02 15:04:05"
-	DateFormat = "2006-01-02"
+	TimeFormat       = "2006-01-02 15:04:05"
+	DateFormat       = "2006-01-02"
+	BackupTimeFormat = "20060102-150405.000000" // 微秒精度，同一秒内多次生成的备份不会互相覆盖
 ) 
\ No newline at end of file
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// HomeEnv 覆盖工具数据目录的环境变量
+const HomeEnv = "AIGO_HOME"
+
+// 项目内的工具状态目录
+const (
+	StateDir          = ".aigo"                // 项目内的状态目录
+	GeneratedManifest = ".aigo/generated.json" // 工具生成文件的清单
+	BackupDir         = ".aigo/backups"        // 覆盖文件前的备份目录
+	LocalCertDir      = ".aigo/certs"          // ssl local 签发的本地开发证书
+)
+
+// HomeDir 返回工具数据目录，默认为 ~/.aigo
+func HomeDir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("无法获取用户主目录: %v", err)
+	}
+	return filepath.Join(home, ".aigo"), nil
+}
+
+// TemplatesDir 返回已注册的自定义模板目录
+func TemplatesDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "templates"), nil
+}
+
+// LocalCADir 返回本地开发CA的目录，所有项目共用同一个根证书
+func LocalCADir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "ca"), nil
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile 项目清单文件名
+const ProjectFile = "aigo.yaml"
+
+// projectHeader 写入项目清单开头的说明
+const projectHeader = "# aigo_hotreload 项目清单，nginx、dev、add 等命令从这里读取项目配置\n" +
+	"# 修改端口或域名后重新运行相应命令即可同步到生成的文件\n"
+
+// ErrNoProject 项目目录中没有项目清单
+var ErrNoProject = errors.New("未找到项目清单 " + ProjectFile)
+
+// Project 项目清单 aigo.yaml，是后续命令读取项目配置的唯一来源
+type Project struct {
+	Name        string              `yaml:"name"`
+	Module      string              `yaml:"module"`
+	Port        string              `yaml:"port"`
+	Domains     []string            `yaml:"domains,omitempty"`
+	Proxy       string              `yaml:"proxy,omitempty"`       // 反向代理服务器: nginx、caddy 或 traefik，为空时为nginx
+	TLS         *ProjectTLS         `yaml:"tls,omitempty"`         // nginx启用HTTPS
+	Upstream    *ProjectUpstream    `yaml:"upstream,omitempty"`    // nginx负载均衡的后端
+	Locations   []ProjectLocation   `yaml:"locations,omitempty"`   // nginx按路径转发的路由
+	Compression *ProjectCompression `yaml:"compression,omitempty"` // nginx响应压缩
+	Cache       []ProjectCacheRule  `yaml:"cache,omitempty"`       // nginx按扩展名设置的浏览器缓存
+	Framework   string              `yaml:"framework"`
+	Database    string              `yaml:"database,omitempty"`
+	Components  []string            `yaml:"components,omitempty"` // 已启用的组件，与 add 命令的组件名称一致
+}
+
+// ProjectTLS nginx的HTTPS证书配置，路径为空时使用Let's Encrypt的目录结构
+type ProjectTLS struct {
+	Cert string `yaml:"cert,omitempty"`
+	Key  string `yaml:"key,omitempty"`
+}
+
+// ProjectUpstream nginx负载均衡配置
+type ProjectUpstream struct {
+	Method    string                  `yaml:"method,omitempty"` // round_robin、least_conn 或 ip_hash
+	Keepalive int                     `yaml:"keepalive,omitempty"`
+	Servers   []ProjectUpstreamServer `yaml:"servers"`
+}
+
+// ProjectUpstreamServer upstream中的一个后端
+type ProjectUpstreamServer struct {
+	Addr        string `yaml:"addr"`
+	Weight      int    `yaml:"weight,omitempty"`
+	MaxFails    *int   `yaml:"max_fails,omitempty"`
+	FailTimeout string `yaml:"fail_timeout,omitempty"`
+}
+
+// ProjectLocation nginx按路径转发的路由
+type ProjectLocation struct {
+	Path           string            `yaml:"path"`
+	Proxy          string            `yaml:"proxy,omitempty"` // 端口、host:port 或 http(s):// 地址，为空时使用默认后端
+	StripPrefix    bool              `yaml:"strip_prefix,omitempty"`
+	WebSocket      bool              `yaml:"websocket,omitempty"`
+	ConnectTimeout string            `yaml:"connect_timeout,omitempty"`
+	ReadTimeout    string            `yaml:"read_timeout,omitempty"`
+	SendTimeout    string            `yaml:"send_timeout,omitempty"`
+	Headers        map[string]string `yaml:"headers,omitempty"`
+	Root           string            `yaml:"root,omitempty"` // 静态目录，与proxy互斥
+	Alias          string            `yaml:"alias,omitempty"`
+	SPA            bool              `yaml:"spa,omitempty"`
+	Index          string            `yaml:"index,omitempty"`
+	Rate           string            `yaml:"rate,omitempty"` // 每个客户端IP的请求速率，如 10r/s
+	Burst          int               `yaml:"burst,omitempty"`
+	Allow          []string          `yaml:"allow,omitempty"`
+	Deny           []string          `yaml:"deny,omitempty"`
+	Auth           bool              `yaml:"auth,omitempty"` // 使用 config/<domain>.htpasswd 进行basic auth认证
+}
+
+// ProjectCompression nginx响应压缩设置
+type ProjectCompression struct {
+	Gzip   bool `yaml:"gzip,omitempty"`
+	Brotli bool `yaml:"brotli,omitempty"`
+}
+
+// ProjectCacheRule 按文件扩展名设置的浏览器缓存规则
+type ProjectCacheRule struct {
+	Extensions []string `yaml:"extensions"`
+	Expires    string   `yaml:"expires"` // 如 30d、epoch、max、off
+}
+
+// LoadProject 读取项目目录下的 aigo.yaml，文件不存在时返回 ErrNoProject
+func LoadProject(dir string) (*Project, error) {
+	content, err := os.ReadFile(filepath.Join(dir, ProjectFile))
+	if os.IsNotExist(err) {
+		return nil, ErrNoProject
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %v", ProjectFile, err)
+	}
+
+	var p Project
+	if err := yaml.Unmarshal(content, &p); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", ProjectFile, err)
+	}
+	if p.Port == "" {
+		p.Port = DefaultPort
+	}
+	// 域名用作配置文件名，手动编辑的清单同样需要检查
+	for _, domain := range p.Domains {
+		if err := ValidateDomain(domain); err != nil {
+			return nil, fmt.Errorf("%s: %v", ProjectFile, err)
+		}
+	}
+	return &p, nil
+}
+
+// Marshal 序列化项目清单
+func (p *Project) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(projectHeader)
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(p); err != nil {
+		return nil, fmt.Errorf("序列化 %s 失败: %v", ProjectFile, err)
+	}
+	return buf.Bytes(), encoder.Close()
+}
+
+// Domain 返回主域名，未配置时返回占位域名
+func (p *Project) Domain() string {
+	if len(p.Domains) == 0 {
+		return DefaultDomain
+	}
+	return p.Domains[0]
+}
+
+// AddDomain 添加域名，已存在时返回false
+func (p *Project) AddDomain(domain string) bool {
+	if contains(p.Domains, domain) {
+		return false
+	}
+	p.Domains = append(p.Domains, domain)
+	return true
+}
+
+// ValidateDomain 检查域名能否安全地用作文件名和nginx配置中的server_name:
+// 不能为空，不能包含路径分隔符、..、空白字符、;、{ 或 }
+func ValidateDomain(domain string) error {
+	if domain == "" || domain == "." || strings.Contains(domain, "..") ||
+		strings.ContainsAny(domain, "/\\;{}") || strings.IndexFunc(domain, unicode.IsSpace) >= 0 ||
+		strings.IndexFunc(domain, unicode.IsControl) >= 0 {
+		return fmt.Errorf("无效的域名 %q: 不能包含路径分隔符、..、空白字符、;、{ 或 }", domain)
+	}
+	return nil
+}
+
+// HasComponent 判断组件是否已启用
+func (p *Project) HasComponent(name string) bool {
+	return contains(p.Components, name)
+}
+
+// AddComponent 启用组件，已启用时返回false
+func (p *Project) AddComponent(name string) bool {
+	if p.HasComponent(name) {
+		return false
+	}
+	p.Components = append(p.Components, name)
+	sort.Strings(p.Components)
+	return true
+}
+
+// contains 判断字符串切片是否包含指定值
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
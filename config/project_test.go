@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestProjectRoundTrip 测试项目清单的序列化和读取
+func TestProjectRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	p := &Project{Name: "demo", Module: "github.com/acme/demo", Port: "9000", Framework: "chi"}
+	p.AddDomain("api.acme.com")
+	p.AddComponent("nginx")
+	p.AddComponent("dockerfile")
+
+	content, err := p.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "# ") || !strings.Contains(string(content), "  - api.acme.com") {
+		t.Errorf("序列化结果错误:\n%s", content)
+	}
+	os.WriteFile(filepath.Join(dir, ProjectFile), content, 0644)
+
+	loaded, err := LoadProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Port != "9000" || loaded.Domain() != "api.acme.com" || loaded.Framework != "chi" {
+		t.Errorf("读取结果错误: %+v", loaded)
+	}
+	if strings.Join(loaded.Components, ",") != "dockerfile,nginx" {
+		t.Errorf("组件应该排序: %v", loaded.Components)
+	}
+}
+
+// TestLoadProjectDefaults 测试缺少清单和省略字段时的默认值
+func TestLoadProjectDefaults(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadProject(dir); !errors.Is(err, ErrNoProject) {
+		t.Errorf("缺少清单时应该返回ErrNoProject, 实际: %v", err)
+	}
+
+	// 端口写成数字也可以读取
+	os.WriteFile(filepath.Join(dir, ProjectFile), []byte("name: demo\nport: 7000\n"), 0644)
+	p, err := LoadProject(dir)
+	if err != nil || p.Port != "7000" || p.Domain() != DefaultDomain {
+		t.Errorf("读取结果错误: %+v %v", p, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, ProjectFile), []byte("name: demo\n"), 0644)
+	if p, _ := LoadProject(dir); p.Port != DefaultPort {
+		t.Errorf("未配置端口时应该使用默认端口: %s", p.Port)
+	}
+
+	// 手动编辑的清单中的域名同样需要检查
+	os.WriteFile(filepath.Join(dir, ProjectFile), []byte("name: demo\ndomains: [a.com, ../../evil]\n"), 0644)
+	if _, err := LoadProject(dir); err == nil || !strings.Contains(err.Error(), "../../evil") {
+		t.Errorf("无效的域名应该返回错误, 实际: %v", err)
+	}
+}
+
+// TestProjectAddIdempotent 测试重复添加域名和组件
+func TestProjectAddIdempotent(t *testing.T) {
+	p := &Project{}
+	if !p.AddDomain("a.com") || p.AddDomain("a.com") {
+		t.Error("重复添加域名应该返回false")
+	}
+	if !p.AddComponent("db") || p.AddComponent("db") || !p.HasComponent("db") {
+		t.Error("重复添加组件应该返回false")
+	}
+}
+
+// TestValidateDomain 测试拒绝会越出配置目录或注入nginx指令的域名
+func TestValidateDomain(t *testing.T) {
+	for _, domain := range []string{"a.com", "api.a.com", "*.a.com", "localhost", "127.0.0.1", "::1"} {
+		if err := ValidateDomain(domain); err != nil {
+			t.Errorf("%s 应该是有效的域名: %v", domain, err)
+		}
+	}
+	for _, domain := range []string{"", ".", "..", "../evil", "../../evil", "../sites-available/default", "a/b", `a\b`, "a.com;return 200 x", "a .com", "a.com\n", "a{", "a}"} {
+		if err := ValidateDomain(domain); err == nil {
+			t.Errorf("%q 应该是无效的域名", domain)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package dev
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// Builder go build 构建器
+type Builder struct {
+	dir    string
+	output string
+	args   []string
+}
+
+// NewBuilder 创建新的构建器，output为相对项目目录的二进制输出路径
+func NewBuilder(dir, output string, args ...string) *Builder {
+	if runtime.GOOS == "windows" && filepath.Ext(output) != ".exe" {
+		output += ".exe"
+	}
+	return &Builder{
+		dir:    dir,
+		output: output,
+		args:   args,
+	}
+}
+
+// Output 返回二进制文件的绝对路径
+func (b *Builder) Output() string {
+	if filepath.IsAbs(b.output) {
+		return b.output
+	}
+	return filepath.Join(b.dir, b.output)
+}
+
+// Build 执行构建，失败时返回编译器输出
+func (b *Builder) Build(ctx context.Context) (string, error) {
+	args := append([]string{"build", "-o", b.Output()}, b.args...)
+	args = append(args, ".")
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = b.dir
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("构建失败: %v", err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,31 @@
+package dev
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestBuilder 测试构建成功与失败
+func TestBuilder(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "package main\n\nfunc main() {}\n")
+
+	builder := NewBuilder(dir, "tmp/main")
+	if _, err := builder.Build(context.Background()); err != nil {
+		t.Fatalf("构建失败: %v", err)
+	}
+	if _, err := os.Stat(builder.Output()); err != nil {
+		t.Errorf("应该生成二进制文件: %v", err)
+	}
+
+	writeModule(t, dir, "package main\n\nfunc main() { undefinedCall() }\n")
+	output, err := builder.Build(context.Background())
+	if err == nil {
+		t.Fatal("编译错误时应该返回错误")
+	}
+	if !strings.Contains(output, "undefinedCall") {
+		t.Errorf("构建输出应该包含编译错误, 实际输出: %s", output)
+	}
+}
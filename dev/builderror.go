@@ -0,0 +1,54 @@
+package dev
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BuildError go build输出中的一条编译错误，无法解析位置的输出只有Message
+type BuildError struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Position 返回 file:line:col 形式的位置，没有位置时返回空字符串
+func (e BuildError) Position() string {
+	if e.File == "" {
+		return ""
+	}
+	pos := e.File + ":" + strconv.Itoa(e.Line)
+	if e.Column > 0 {
+		pos += ":" + strconv.Itoa(e.Column)
+	}
+	return pos
+}
+
+// buildErrorPattern 匹配 file.go:line:col: message 和 file.go:line: message
+var buildErrorPattern = regexp.MustCompile(`^(.+?\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// ParseBuildErrors 解析go build的输出：跳过 # package 标题行，以制表符开头的行并入上一条错误
+func ParseBuildErrors(output string) []BuildError {
+	var errs []BuildError
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "# ") {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") && len(errs) > 0 {
+			errs[len(errs)-1].Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		m := buildErrorPattern.FindStringSubmatch(line)
+		if m == nil {
+			errs = append(errs, BuildError{Message: strings.TrimSpace(line)})
+			continue
+		}
+		e := BuildError{File: strings.TrimPrefix(m[1], "./"), Message: m[4]}
+		e.Line, _ = strconv.Atoi(m[2])
+		e.Column, _ = strconv.Atoi(m[3])
+		errs = append(errs, e)
+	}
+	return errs
+}
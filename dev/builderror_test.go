@@ -0,0 +1,32 @@
+package dev
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseBuildErrors 测试解析go build输出中的文件、行号、列号和错误信息
+func TestParseBuildErrors(t *testing.T) {
+	output := "# example.com/app\n" +
+		"./main.go:12:5: undefined: foo\n" +
+		"./handlers/user.go:3:2: \"strings\" imported and not used\n" +
+		"vet.go:7: missing return\n" +
+		"./main.go:20:9: cannot use x (variable of type int) as string value in return statement:\n" +
+		"\tint does not implement fmt.Stringer\n" +
+		"go: updates to go.mod needed\n"
+
+	want := []BuildError{
+		{File: "main.go", Line: 12, Column: 5, Message: "undefined: foo"},
+		{File: "handlers/user.go", Line: 3, Column: 2, Message: `"strings" imported and not used`},
+		{File: "vet.go", Line: 7, Message: "missing return"},
+		{File: "main.go", Line: 20, Column: 9, Message: "cannot use x (variable of type int) as string value in return statement:\nint does not implement fmt.Stringer"},
+		{Message: "go: updates to go.mod needed"},
+	}
+	got := ParseBuildErrors(output)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("解析结果错误:\n%+v\n期望:\n%+v", got, want)
+	}
+	if got[0].Position() != "main.go:12:5" || got[2].Position() != "vet.go:7" || got[4].Position() != "" {
+		t.Errorf("位置格式错误: %s %s %s", got[0].Position(), got[2].Position(), got[4].Position())
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package dev
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 让子进程拥有独立进程组，便于连同其子进程一起终止
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// interruptProcess 向进程组发送SIGINT
+func interruptProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
+
+// killProcess 向进程组发送SIGKILL
+func killProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// listenCommand 通过sh把LISTEN_PID设置为自身的pid后exec应用，exec前后pid不变，
+// 应用看到的LISTEN_PID等于自己的pid，符合sd_listen_fds的约定
+func listenCommand(bin string) *exec.Cmd {
+	return exec.Command("/bin/sh", "-c", `LISTEN_PID=$$; export LISTEN_PID; exec "$0"`, bin)
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package dev
+
+import "os/exec"
+
+// setProcessGroup Windows下无需设置进程组
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// interruptProcess Windows不支持向子进程发送中断信号，直接结束进程
+func interruptProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// killProcess 强制结束进程
+func killProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// listenCommand Windows不支持继承socket，直接启动应用
+func listenCommand(bin string) *exec.Cmd {
+	return exec.Command(bin)
+}
@@ -0,0 +1,488 @@
+package dev
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 代理自身使用的路径，以 /__aigo/ 开头避免与应用的路由冲突
+const (
+	LiveReloadPath   = "/__aigo/livereload"    // 推送刷新事件的SSE事件流
+	LiveReloadScript = "/__aigo/livereload.js" // 注入HTML响应的脚本
+)
+
+// 应用重启时暂停转发请求的默认配置
+const (
+	DefaultHoldTimeout = 30 * time.Second // 请求最多等待新进程就绪的时间
+	DefaultReadyPath   = "/health"        // 就绪检查的路径
+)
+
+// maxReplayBody 为了在应用重启后重放而缓存的请求体上限，更大的请求不重放
+const maxReplayBody = 10 << 20
+
+// errHoldTimeout 等待应用重启超时
+var errHoldTimeout = errors.New("等待应用重启超时")
+
+// liveReloadJS 连接事件流，收到reload事件后刷新页面；连接断开时EventSource自动重连
+const liveReloadJS = `(function () {
+  if (!window.EventSource) return;
+  var source = new EventSource("` + LiveReloadPath + `");
+  source.addEventListener("reload", function () { location.reload(); });
+})();
+`
+
+// scriptTag 注入HTML的脚本标签
+const scriptTag = `<script src="` + LiveReloadScript + `"></script>`
+
+// unavailablePage 应用未运行时返回的页面，包含自动刷新脚本，应用启动后自动刷新
+const unavailablePage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>aigo_hotreload</title></head>
+<body><p>应用暂时不可用（%s），构建成功并重启后页面会自动刷新。</p></body></html>
+`
+
+// buildErrorPage 构建失败时的错误页面，包含自动刷新脚本，下一次构建成功后自动刷新
+var buildErrorPage = template.Must(template.New("build-errors").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>构建失败 - aigo_hotreload</title>
+<style>
+body { margin: 0; padding: 2em; background: #1e1e1e; color: #ddd; font-family: -apple-system, "Segoe UI", sans-serif; }
+h1 { color: #ff6b6b; font-size: 1.4em; }
+ul { list-style: none; padding: 0; }
+li { margin: 1em 0; padding: 0.8em 1em; background: #2a2a2a; border-left: 4px solid #ff6b6b; }
+code { color: #8ec7ff; }
+pre { margin: 0.4em 0 0; white-space: pre-wrap; font-size: 0.95em; }
+</style>
+</head>
+<body>
+<h1>构建失败</h1>
+<p>修改代码后会自动重新构建，构建成功后页面自动刷新。</p>
+<ul>
+{{- range .Errors}}
+<li>{{with .Position}}<code>{{.}}</code>{{end}}<pre>{{.Message}}</pre></li>
+{{- end}}
+</ul>
+<script src="{{.Script}}"></script>
+</body>
+</html>
+`))
+
+// Proxy 开发时在应用前面的反向代理：在HTML响应中注入自动刷新脚本，应用重启后通过SSE通知浏览器刷新；
+// 重启期间暂停转发请求，新进程通过就绪检查后继续转发，被旧进程中断的请求重新发送
+type Proxy struct {
+	HoldTimeout time.Duration // 请求等待应用重启的最长时间，也是就绪检查的最长时间
+	ReadyPath   string        // 就绪检查的路径，返回5xx以外的状态码表示就绪
+
+	addr      string
+	target    *url.URL
+	certFile  string
+	keyFile   string
+	proxy     *httputil.ReverseProxy
+	transport *http.Transport
+	server    *http.Server
+
+	mu          sync.Mutex
+	clients     map[chan struct{}]struct{}
+	buildErrors []BuildError  // 最近一次构建失败的错误，构建成功后清空
+	held        chan struct{} // 应用重启期间不为nil，新进程就绪后关闭
+	startErr    error         // 应用启动失败的错误，下一次Reload时清空
+}
+
+// NewProxy 创建转发到target的代理；certFile和keyFile不为空时代理和应用都使用HTTPS
+func NewProxy(addr string, target *url.URL, certFile, keyFile string) *Proxy {
+	p := &Proxy{
+		HoldTimeout: DefaultHoldTimeout,
+		ReadyPath:   DefaultReadyPath,
+		addr:        addr,
+		target:      target,
+		certFile:    certFile,
+		keyFile:     keyFile,
+		clients:     make(map[chan struct{}]struct{}),
+	}
+
+	p.proxy = httputil.NewSingleHostReverseProxy(target)
+	director := p.proxy.Director
+	p.proxy.Director = func(r *http.Request) {
+		director(r)
+		// 不接收压缩的响应，才能在HTML中注入脚本
+		r.Header.Del("Accept-Encoding")
+	}
+	p.proxy.ModifyResponse = injectResponse
+	p.proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		status := http.StatusBadGateway
+		if errors.Is(err, errHoldTimeout) {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		fmt.Fprint(w, string(InjectScript([]byte(fmt.Sprintf(unavailablePage, html.EscapeString(err.Error()))))))
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if target.Scheme == "https" {
+		// 应用使用本地开发证书，只在本机之间转发
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	p.transport = transport
+	p.proxy.Transport = &holdTransport{proxy: p, base: transport}
+	p.server = &http.Server{Handler: p}
+	return p
+}
+
+// URL 返回浏览器访问代理的地址
+func (p *Proxy) URL() string {
+	scheme := "http"
+	if p.certFile != "" {
+		scheme = "https"
+	}
+	host, port, err := net.SplitHostPort(p.addr)
+	if err != nil {
+		return scheme + "://" + p.addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+// Start 监听地址并在后台处理请求，监听失败时返回错误
+func (p *Proxy) Start() error {
+	ln, err := net.Listen("tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("启动自动刷新代理失败: %v", err)
+	}
+	// 端口为0时记录实际监听的端口
+	p.addr = ln.Addr().String()
+	go func() {
+		if p.certFile != "" {
+			p.server.ServeTLS(ln, p.certFile, p.keyFile)
+		} else {
+			p.server.Serve(ln)
+		}
+	}()
+	return nil
+}
+
+// Close 关闭代理，断开全部事件流
+func (p *Proxy) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p.mu.Lock()
+	for ch := range p.clients {
+		close(ch)
+		delete(p.clients, ch)
+	}
+	p.mu.Unlock()
+	return p.server.Shutdown(ctx)
+}
+
+// Hold 应用即将重启，之后的请求等待Reload
+func (p *Proxy) Hold() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.held == nil {
+		p.held = make(chan struct{})
+	}
+}
+
+// Reload 清除构建错误，等待应用通过就绪检查后继续转发暂停的请求，并通知全部浏览器刷新；
+// 超过HoldTimeout仍未就绪时同样继续转发
+func (p *Proxy) Reload() {
+	p.mu.Lock()
+	p.buildErrors = nil
+	p.startErr = nil
+	p.mu.Unlock()
+
+	p.waitReady()
+
+	p.mu.Lock()
+	p.release()
+	p.mu.Unlock()
+	p.notify()
+}
+
+// StartFailed 应用启动失败，不再等待就绪检查：暂停的请求和之后的请求立即返回启动错误，直到下一次Reload
+func (p *Proxy) StartFailed(err error) {
+	p.mu.Lock()
+	p.buildErrors = nil
+	p.startErr = err
+	p.release()
+	p.mu.Unlock()
+	p.notify()
+}
+
+// release 继续转发暂停的请求，调用时需要持有mu
+func (p *Proxy) release() {
+	if p.held != nil {
+		close(p.held)
+		p.held = nil
+	}
+}
+
+// waitReady 轮询就绪检查路径，直到返回5xx以外的状态码或超时
+func (p *Proxy) waitReady() bool {
+	client := &http.Client{Transport: p.transport, Timeout: time.Second}
+	ready := *p.target
+	ready.Path = p.ReadyPath
+	deadline := time.Now().Add(p.HoldTimeout)
+	for time.Now().Before(deadline) {
+		if resp, err := client.Get(ready.String()); err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode < http.StatusInternalServerError {
+				return true
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+// wait 应用重启期间等待新进程就绪，超过HoldTimeout时返回errHoldTimeout
+func (p *Proxy) wait(ctx context.Context) error {
+	p.mu.Lock()
+	held := p.held
+	p.mu.Unlock()
+	if held == nil {
+		return nil
+	}
+	timer := time.NewTimer(p.HoldTimeout)
+	defer timer.Stop()
+	select {
+	case <-held:
+		return nil
+	case <-timer.C:
+		return errHoldTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startError 返回应用启动失败的错误
+func (p *Proxy) startError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.startErr
+}
+
+// holding 判断应用是否正在重启
+func (p *Proxy) holding() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.held != nil
+}
+
+// BuildFailed 记录go build的输出，之后的请求返回错误页面，直到下一次构建成功后调用Reload
+func (p *Proxy) BuildFailed(output string) {
+	errs := ParseBuildErrors(output)
+	if len(errs) == 0 {
+		errs = []BuildError{{Message: "构建失败"}}
+	}
+	p.mu.Lock()
+	p.buildErrors = errs
+	p.mu.Unlock()
+	p.notify()
+}
+
+// notify 通知全部浏览器刷新
+func (p *Proxy) notify() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for ch := range p.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// 上一次通知还没有发送，不需要重复通知
+		}
+	}
+}
+
+// ServeHTTP 处理自动刷新的脚本和事件流，其他请求转发给应用
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case LiveReloadScript:
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		io.WriteString(w, liveReloadJS)
+	case LiveReloadPath:
+		p.serveEvents(w, r)
+	default:
+		p.mu.Lock()
+		errs := p.buildErrors
+		p.mu.Unlock()
+		if errs != nil {
+			serveBuildErrors(w, r, errs)
+			return
+		}
+		if err := bufferBody(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.proxy.ServeHTTP(w, r)
+	}
+}
+
+// holdTransport 应用重启期间暂停发送请求；请求被重启的旧进程中断时，等待新进程就绪后重新发送
+type holdTransport struct {
+	proxy *Proxy
+	base  http.RoundTripper
+}
+
+// RoundTrip 实现http.RoundTripper
+func (t *holdTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.proxy.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	if err := t.proxy.startError(); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil || !t.proxy.holding() || req.Context().Err() != nil {
+		return resp, err
+	}
+	// 没有缓存请求体的请求无法重新发送
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return nil, err
+	}
+	if err := t.proxy.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	if err := t.proxy.startError(); err != nil {
+		return nil, err
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// bufferBody 把不超过maxReplayBody的请求体读入内存，使请求可以重新发送；WebSocket等升级请求不处理
+func bufferBody(r *http.Request) error {
+	if r.Body == nil || r.Body == http.NoBody || r.Header.Get("Upgrade") != "" || r.ContentLength > maxReplayBody {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxReplayBody+1))
+	if err != nil {
+		return fmt.Errorf("读取请求体失败: %v", err)
+	}
+	if len(body) > maxReplayBody {
+		// 超过上限的分块请求不重放，已读取的部分放回请求体
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		return nil
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
+
+// serveBuildErrors 返回构建错误页面，请求只接受JSON时返回JSON
+func serveBuildErrors(w http.ResponseWriter, r *http.Request, errs []BuildError) {
+	w.Header().Set("Cache-Control", "no-store")
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html") {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": "构建失败", "errors": errs})
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	buildErrorPage.Execute(w, struct {
+		Errors []BuildError
+		Script string
+	}{errs, LiveReloadScript})
+}
+
+// serveEvents 保持SSE连接，收到通知时发送reload事件
+func (p *Proxy) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "不支持事件流", http.StatusInternalServerError)
+		return
+	}
+	ch := make(chan struct{}, 1)
+	p.mu.Lock()
+	p.clients[ch] = struct{}{}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.clients, ch)
+		p.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	io.WriteString(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			io.WriteString(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// injectResponse 在未压缩的HTML响应中注入自动刷新脚本
+func injectResponse(resp *http.Response) error {
+	if resp.Request.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	body = InjectScript(body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// InjectScript 在最后一个 </body> 之前插入自动刷新脚本，没有 </body> 时追加到末尾
+func InjectScript(page []byte) []byte {
+	end := []byte("</body>")
+	i := len(page) - len(end)
+	for ; i >= 0 && !bytes.EqualFold(page[i:i+len(end)], end); i-- {
+	}
+	if i < 0 {
+		return append(page, scriptTag...)
+	}
+	out := make([]byte, 0, len(page)+len(scriptTag))
+	out = append(out, page[:i]...)
+	out = append(out, scriptTag...)
+	return append(out, page[i:]...)
+}
@@ -0,0 +1,262 @@
+package dev
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestInjectScript 测试在HTML中插入自动刷新脚本
+func TestInjectScript(t *testing.T) {
+	cases := map[string]string{
+		"<html><body><p>hi</p></body></html>":   "<html><body><p>hi</p>" + scriptTag + "</body></html>",
+		"<BODY>a</BODY><!-- </body> --></BODY>": "<BODY>a</BODY><!-- </body> -->" + scriptTag + "</BODY>",
+		"<p>片段</p>":                             "<p>片段</p>" + scriptTag,
+		"":                                      scriptTag,
+	}
+	for in, want := range cases {
+		if got := string(InjectScript([]byte(in))); got != want {
+			t.Errorf("InjectScript(%q) = %q, 期望 %q", in, got, want)
+		}
+	}
+}
+
+// TestProxy 测试代理注入脚本、推送刷新事件和应用不可用时的页面
+func TestProxy(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 浏览器的Accept-Encoding不转发给应用，压缩的响应无法注入脚本
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "br") {
+			t.Errorf("代理不应该转发浏览器的Accept-Encoding: %s", r.Header.Get("Accept-Encoding"))
+		}
+		if r.URL.Path == "/api" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"ok":true}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html><body>home</body></html>")
+	}))
+	target, _ := url.Parse(app.URL)
+	proxy := NewProxy("127.0.0.1:0", target, "", "")
+	if err := proxy.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, proxy.URL()+path, nil)
+		req.Header.Set("Accept-Encoding", "br")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if _, body := get("/"); body != "<html><body>home"+scriptTag+"</body></html>" {
+		t.Errorf("HTML响应应该注入脚本: %s", body)
+	}
+	if _, body := get("/api"); body != `{"ok":true}` {
+		t.Errorf("非HTML响应不应该修改: %s", body)
+	}
+	if _, body := get(LiveReloadScript); !strings.Contains(body, LiveReloadPath) {
+		t.Errorf("脚本应该连接事件流: %s", body)
+	}
+
+	resp, err := http.Get(proxy.URL() + LiveReloadPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("事件流的Content-Type错误: %s", resp.Header.Get("Content-Type"))
+	}
+	lines := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			// 只关心注释行和事件名
+			if line := scanner.Text(); strings.HasPrefix(line, ":") || strings.HasPrefix(line, "event:") {
+				lines <- line
+			}
+		}
+		close(lines)
+	}()
+	if line := <-lines; line != ": connected" {
+		t.Fatalf("连接后应该先收到注释行, 实际得到 %q", line)
+	}
+	proxy.Reload()
+	select {
+	case line := <-lines:
+		if line != "event: reload" {
+			t.Errorf("应该收到reload事件, 实际得到 %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待reload事件超时")
+	}
+
+	// 构建失败时返回错误页面并通知浏览器刷新
+	proxy.BuildFailed("# example.com/app\n./main.go:3:1: syntax error: unexpected <-\n")
+	select {
+	case line := <-lines:
+		if line != "event: reload" {
+			t.Errorf("构建失败时应该收到reload事件, 实际得到 %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待构建失败的reload事件超时")
+	}
+	status, body := get("/api")
+	if status != http.StatusInternalServerError || !strings.Contains(body, "<code>main.go:3:1</code>") ||
+		!strings.Contains(body, "unexpected &lt;-") || !strings.Contains(body, LiveReloadScript) {
+		t.Errorf("构建失败时应该返回错误页面: %d %s", status, body)
+	}
+	req, _ := http.NewRequest(http.MethodGet, proxy.URL()+"/api", nil)
+	req.Header.Set("Accept", "application/json")
+	jsonResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Errors []BuildError `json:"errors"`
+	}
+	json.NewDecoder(jsonResp.Body).Decode(&result)
+	jsonResp.Body.Close()
+	if want := (BuildError{File: "main.go", Line: 3, Column: 1, Message: "syntax error: unexpected <-"}); len(result.Errors) != 1 || result.Errors[0] != want {
+		t.Errorf("JSON错误结果错误: %+v", result.Errors)
+	}
+
+	// 构建成功后恢复转发
+	proxy.Reload()
+	if _, body := get("/api"); body != `{"ok":true}` {
+		t.Errorf("构建成功后应该恢复转发: %s", body)
+	}
+
+	app.Close()
+	status, body = get("/")
+	if status != http.StatusBadGateway || !strings.Contains(body, scriptTag) {
+		t.Errorf("应用不可用时应该返回包含脚本的502页面: %d %s", status, body)
+	}
+}
+
+// TestProxyHold 测试应用重启期间暂停转发、就绪后继续，以及重放被旧进程中断的请求
+func TestProxyHold(t *testing.T) {
+	var ready atomic.Bool
+	var slowCalls atomic.Int32
+	var proxy *Proxy
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/health":
+			if !ready.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case "/slow":
+			// 第一次请求时模拟旧进程被重启终止，连接在响应前断开
+			if slowCalls.Add(1) == 1 {
+				proxy.Hold()
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			w.Write(body)
+		default:
+			w.Write(body)
+		}
+	}))
+	defer app.Close()
+	target, _ := url.Parse(app.URL)
+	proxy = NewProxy("127.0.0.1:0", target, "", "")
+	if err := proxy.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	post := func(path, body string) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			resp, err := http.Post(proxy.URL()+path, "text/plain", strings.NewReader(body))
+			if err != nil {
+				ch <- result{err: err}
+				return
+			}
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+			ch <- result{status: resp.StatusCode, body: string(data)}
+		}()
+		return ch
+	}
+
+	// 重启期间的请求等待新进程通过就绪检查
+	proxy.Hold()
+	held := post("/echo", "hello")
+	select {
+	case r := <-held:
+		t.Fatalf("重启期间请求不应该被转发: %+v", r)
+	case <-time.After(300 * time.Millisecond):
+	}
+	reloaded := make(chan struct{})
+	go func() {
+		proxy.Reload()
+		close(reloaded)
+	}()
+	select {
+	case <-reloaded:
+		t.Fatal("就绪检查未通过时Reload不应该返回")
+	case <-time.After(300 * time.Millisecond):
+	}
+	ready.Store(true)
+	if r := <-held; r.err != nil || r.status != http.StatusOK || r.body != "hello" {
+		t.Errorf("就绪后应该转发暂停的请求: %+v", r)
+	}
+	<-reloaded
+
+	// 被旧进程中断的请求在新进程就绪后重新发送，请求体保持不变
+	replayed := post("/slow", "payload")
+	time.Sleep(200 * time.Millisecond)
+	proxy.Reload()
+	if r := <-replayed; r.err != nil || r.status != http.StatusOK || r.body != "payload" || slowCalls.Load() != 2 {
+		t.Errorf("中断的请求应该重新发送一次: %+v, 调用 %d 次", r, slowCalls.Load())
+	}
+
+	// 超过等待时间返回503
+	proxy.HoldTimeout = 200 * time.Millisecond
+	proxy.Hold()
+	if r := <-post("/echo", "late"); r.err != nil || r.status != http.StatusServiceUnavailable {
+		t.Errorf("等待重启超时应该返回503: %+v", r)
+	}
+	proxy.Reload()
+
+	// 应用启动失败时暂停的请求立即返回启动错误，不等待HoldTimeout
+	proxy.HoldTimeout = 30 * time.Second
+	proxy.Hold()
+	failed := post("/echo", "dead")
+	time.Sleep(100 * time.Millisecond)
+	proxy.StartFailed(errors.New("应用启动失败: exec: not found"))
+	select {
+	case r := <-failed:
+		if r.err != nil || r.status != http.StatusBadGateway || !strings.Contains(r.body, "应用启动失败") {
+			t.Errorf("启动失败时应该返回502和启动错误: %+v", r)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("启动失败时暂停的请求应该立即返回")
+	}
+	proxy.Reload()
+	if r := <-post("/echo", "again"); r.err != nil || r.status != http.StatusOK || r.body != "again" {
+		t.Errorf("重新启动后应该恢复转发: %+v", r)
+	}
+}
@@ -0,0 +1,207 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/tools"
+	"github.com/yggai/aigo_hotreload/watcher"
+)
+
+// Options 热重载配置
+type Options struct {
+	Root         string
+	BuildOutput  string
+	BuildArgs    []string
+	Delay        time.Duration
+	KillDelay    time.Duration
+	Poll         bool
+	PollInterval time.Duration
+	Rules        *watcher.Rules
+	Env          []string
+
+	// 自动刷新代理，ProxyAddr或AppURL为空时不启动
+	ProxyAddr string // 代理的监听地址
+	AppURL    string // 代理转发的应用地址
+	CertFile  string // 代理使用的HTTPS证书，为空时使用HTTP
+	KeyFile   string
+	// 应用重启期间代理暂停转发请求，新进程通过就绪检查后继续，为空时使用默认值
+	HoldTimeout time.Duration
+	ReadyPath   string
+	// ListenAddr 不为空时由开发服务监听应用端口，通过LISTEN_FDS把socket传给每个新进程
+	ListenAddr string
+}
+
+// DefaultOptions 返回默认热重载配置
+func DefaultOptions(root string) Options {
+	return Options{
+		Root:         root,
+		BuildOutput:  config.DevBuildOutput,
+		Delay:        config.DevBuildDelay,
+		KillDelay:    config.DevKillDelay,
+		PollInterval: watcher.DefaultPollInterval,
+		Rules:        watcher.DefaultRules(),
+	}
+}
+
+// Reloader 热重载器：监听文件变更，防抖后重新构建并重启应用
+type Reloader struct {
+	opts    Options
+	builder *Builder
+	runner  *Runner
+	proxy   *Proxy
+	logger  *tools.Logger
+}
+
+// NewReloader 创建新的热重载器
+func NewReloader(opts Options) *Reloader {
+	builder := NewBuilder(opts.Root, opts.BuildOutput, opts.BuildArgs...)
+	return &Reloader{
+		opts:    opts,
+		builder: builder,
+		runner:  NewRunner(builder.Output(), opts.Root, opts.Env, opts.KillDelay),
+		logger:  tools.NewLogger(),
+	}
+}
+
+// Run 启动热重载循环，直到ctx被取消
+func (r *Reloader) Run(ctx context.Context) error {
+	w, err := r.newWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	defer r.runner.Stop()
+
+	r.logger.Info("正在监听 %s (%s)", w.Root(), w.Mode())
+	if r.opts.ListenAddr != "" {
+		f, err := r.listen()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+	}
+	if r.opts.ProxyAddr != "" && r.opts.AppURL != "" {
+		if err := r.startProxy(); err != nil {
+			r.logger.Warning("%v，不自动刷新浏览器", err)
+		} else {
+			defer r.proxy.Close()
+		}
+	}
+	r.rebuild(ctx)
+
+	timer := time.NewTimer(r.opts.Delay)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.logger.Info("正在停止开发服务...")
+			return nil
+		case ev := <-w.Events():
+			rel, _ := filepath.Rel(w.Root(), ev.Path)
+			r.logger.Info("检测到变更: %s (%s)", rel, ev.Op)
+			timer.Reset(r.opts.Delay)
+		case err := <-w.Errors():
+			r.logger.Warning("文件监听出错: %v", err)
+		case <-timer.C:
+			r.rebuild(ctx)
+		}
+	}
+}
+
+// newWatcher 根据配置创建文件监听器
+func (r *Reloader) newWatcher() (*watcher.Watcher, error) {
+	if r.opts.Poll {
+		return watcher.NewPolling(r.opts.Root, r.opts.Rules, r.opts.PollInterval)
+	}
+	return watcher.New(r.opts.Root, r.opts.Rules)
+}
+
+// listen 监听应用端口并交给runner，返回的文件在开发服务退出时关闭
+func (r *Reloader) listen() (*os.File, error) {
+	ln, err := net.Listen("tcp", r.opts.ListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("监听应用端口失败: %v", err)
+	}
+	// File返回复制的文件描述符，socket在f关闭前一直处于监听状态
+	f, err := ln.(*net.TCPListener).File()
+	ln.Close()
+	if err != nil {
+		return nil, fmt.Errorf("无法传递socket: %v", err)
+	}
+	r.runner.Inherit(f)
+	r.logger.Info("已监听应用端口 %s，通过 LISTEN_FDS 和 LISTEN_PID 传给应用进程", r.opts.ListenAddr)
+	return f, nil
+}
+
+// startProxy 启动自动刷新代理
+func (r *Reloader) startProxy() error {
+	target, err := url.Parse(r.opts.AppURL)
+	if err != nil || target.Host == "" {
+		return fmt.Errorf("无效的应用地址 %s", r.opts.AppURL)
+	}
+	proxy := NewProxy(r.opts.ProxyAddr, target, r.opts.CertFile, r.opts.KeyFile)
+	if r.opts.HoldTimeout > 0 {
+		proxy.HoldTimeout = r.opts.HoldTimeout
+	}
+	if r.opts.ReadyPath != "" {
+		proxy.ReadyPath = r.opts.ReadyPath
+	}
+	if err := proxy.Start(); err != nil {
+		return err
+	}
+	r.proxy = proxy
+	r.logger.Info("自动刷新代理: %s -> %s", proxy.URL(), r.opts.AppURL)
+	return nil
+}
+
+// rebuild 重新构建，成功后重启应用；失败时保留旧进程
+func (r *Reloader) rebuild(ctx context.Context) {
+	r.logger.Info("正在构建...")
+	start := time.Now()
+
+	output, err := r.builder.Build(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		r.logger.Error("%v", err)
+		if output = strings.TrimSpace(output); output != "" {
+			r.logger.Println(output)
+		}
+		if r.proxy != nil {
+			r.proxy.BuildFailed(output)
+		}
+		return
+	}
+	r.logger.Success("构建完成 (%s)", time.Since(start).Round(time.Millisecond))
+
+	// 旧进程停止前暂停转发，新进程就绪后由Reload继续
+	if r.proxy != nil {
+		r.proxy.Hold()
+	}
+	if err := r.runner.Stop(); err != nil {
+		r.logger.Warning("%v", err)
+	}
+	if err := r.runner.Start(); err != nil {
+		r.logger.Error("%v", err)
+		if r.proxy != nil {
+			// 没有新进程可以等待，暂停的请求立即返回启动错误
+			r.proxy.StartFailed(fmt.Errorf("应用启动失败: %v", err))
+		}
+		return
+	}
+	r.logger.Success("应用已重启")
+	if r.proxy != nil {
+		go r.proxy.Reload()
+	}
+}
@@ -0,0 +1,85 @@
+package dev
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeModule 在临时目录中写入一个最小的Go程序
+func writeModule(t *testing.T, dir, mainSrc string) {
+	t.Helper()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.24\n"), 0644)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(mainSrc), 0644); err != nil {
+		t.Fatalf("写入main.go失败: %v", err)
+	}
+}
+
+// markerProgram 启动后把version写入marker文件并保持运行
+func markerProgram(version string) string {
+	return `package main
+
+import (
+	"os"
+	"time"
+)
+
+func main() {
+	os.WriteFile("marker.txt", []byte("` + version + `"), 0644)
+	time.Sleep(time.Hour)
+}
+`
+}
+
+// waitForFile 等待文件内容变为期望值
+func waitForFile(t *testing.T, path, expect string) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, err := os.ReadFile(path); err == nil && string(data) == expect {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	data, _ := os.ReadFile(path)
+	t.Fatalf("等待 %s 内容为 %q 超时, 实际为 %q", path, expect, string(data))
+}
+
+// TestReloader 测试修改代码后自动重新构建并重启
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, markerProgram("v1"))
+
+	opts := DefaultOptions(dir)
+	opts.Delay = 100 * time.Millisecond
+	opts.KillDelay = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewReloader(opts).Run(ctx)
+	}()
+
+	marker := filepath.Join(dir, "marker.txt")
+	waitForFile(t, marker, "v1")
+
+	// 构建失败时保留旧进程
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() { broken( }\n"), 0644)
+	time.Sleep(time.Second)
+	waitForFile(t, marker, "v1")
+
+	writeModule(t, dir, markerProgram("v2"))
+	waitForFile(t, marker, "v2")
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run 返回错误: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("取消后Run应该返回")
+	}
+}
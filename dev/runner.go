@@ -0,0 +1,135 @@
+package dev
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Runner 应用进程管理器，负责启动和终止构建出的二进制
+type Runner struct {
+	bin       string
+	dir       string
+	env       []string
+	killDelay time.Duration
+	stdout    io.Writer
+	stderr    io.Writer
+	files     []*os.File // 传给进程的socket，从文件描述符3开始
+
+	mu   sync.Mutex
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// NewRunner 创建新的进程管理器
+func NewRunner(bin, dir string, env []string, killDelay time.Duration) *Runner {
+	return &Runner{
+		bin:       bin,
+		dir:       dir,
+		env:       env,
+		killDelay: killDelay,
+		stdout:    os.Stdout,
+		stderr:    os.Stderr,
+	}
+}
+
+// Start 启动应用进程
+func (r *Runner) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cmd != nil {
+		return fmt.Errorf("进程已在运行")
+	}
+
+	cmd := exec.Command(r.bin)
+	if len(r.files) > 0 {
+		// 按systemd socket activation的约定传入socket，LISTEN_PID在exec应用前设置为进程自身的pid
+		cmd = listenCommand(r.bin)
+	}
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), r.env...)
+	if len(r.files) > 0 {
+		cmd.Env = slices.DeleteFunc(cmd.Env, func(kv string) bool { return strings.HasPrefix(kv, "LISTEN_PID=") })
+		cmd.Env = append(cmd.Env, "LISTEN_FDS="+strconv.Itoa(len(r.files)))
+		cmd.ExtraFiles = r.files
+	}
+	cmd.Stdout = r.stdout
+	cmd.Stderr = r.stderr
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动进程失败: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+
+	r.cmd = cmd
+	r.done = done
+	return nil
+}
+
+// Inherit 设置之后启动的进程继承的socket，进程重启时端口一直处于监听状态
+func (r *Runner) Inherit(files ...*os.File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files = files
+}
+
+// Running 判断进程是否仍在运行
+func (r *Runner) Running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cmd == nil {
+		return false
+	}
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Stop 终止应用进程，先发送中断信号，超过killDelay后强制结束
+func (r *Runner) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cmd == nil {
+		return nil
+	}
+	cmd, done := r.cmd, r.done
+	r.cmd, r.done = nil, nil
+
+	select {
+	case <-done:
+		return nil
+	default:
+	}
+
+	if err := interruptProcess(cmd); err != nil {
+		killProcess(cmd)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(r.killDelay):
+		if err := killProcess(cmd); err != nil {
+			return fmt.Errorf("终止进程失败: %v", err)
+		}
+		<-done
+	}
+	return nil
+}
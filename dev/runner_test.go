@@ -0,0 +1,132 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRunner 测试进程启动与终止
+func TestRunner(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, markerProgram("v1"))
+
+	builder := NewBuilder(dir, "tmp/main")
+	if out, err := builder.Build(context.Background()); err != nil {
+		t.Fatalf("构建失败: %v\n%s", err, out)
+	}
+
+	runner := NewRunner(builder.Output(), dir, nil, time.Second)
+	if err := runner.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	if err := runner.Start(); err == nil {
+		t.Error("重复启动应该返回错误")
+	}
+	waitForFile(t, filepath.Join(dir, "marker.txt"), "v1")
+
+	if !runner.Running() {
+		t.Error("进程应该在运行")
+	}
+	if err := runner.Stop(); err != nil {
+		t.Errorf("终止失败: %v", err)
+	}
+	if runner.Running() {
+		t.Error("进程应该已经终止")
+	}
+	if err := runner.Stop(); err != nil {
+		t.Errorf("重复终止不应该返回错误: %v", err)
+	}
+}
+
+// socketProgram 使用继承的socket提供HTTP服务
+const socketProgram = `package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+func main() {
+	if os.Getenv("LISTEN_FDS") != "1" || os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		os.Exit(2)
+	}
+	ln, err := net.FileListener(os.NewFile(3, "listener"))
+	if err != nil {
+		os.Exit(3)
+	}
+	http.Serve(ln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+}
+`
+
+// TestRunnerInherit 测试进程继承socket，重启期间的连接由新进程处理
+func TestRunnerInherit(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, socketProgram)
+	builder := NewBuilder(dir, "tmp/main")
+	if out, err := builder.Build(context.Background()); err != nil {
+		t.Fatalf("构建失败: %v\n%s", err, out)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + ln.Addr().String()
+	f, err := ln.(*net.TCPListener).File()
+	ln.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	t.Setenv("LISTEN_PID", "1")
+	runner := NewRunner(builder.Output(), dir, nil, time.Second)
+	runner.Inherit(f)
+	get := func() (string, error) {
+		resp, err := (&http.Client{Timeout: 10 * time.Second}).Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	if err := runner.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if body, err := get(); err != nil || body != "ok" {
+		t.Fatalf("应该通过继承的socket提供服务: %q, %v", body, err)
+	}
+	if err := runner.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 进程停止后端口仍然处于监听状态，连接等待新进程处理
+	result := make(chan error, 1)
+	go func() {
+		body, err := get()
+		if err == nil && body != "ok" {
+			err = fmt.Errorf("响应错误: %q", body)
+		}
+		result <- err
+	}()
+	time.Sleep(300 * time.Millisecond)
+	if err := runner.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer runner.Stop()
+	if err := <-result; err != nil {
+		t.Errorf("重启期间的请求应该由新进程处理: %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// ComponentFile 组件生成的文件
+type ComponentFile struct {
+	Path     string // 相对项目根目录的路径
+	Template string
+}
+
+// Component 可以通过 add 命令添加到已有项目中的组件
+type Component struct {
+	Name        string
+	Description string
+	// Files 组件生成的文件
+	Files func(data templates.Data) []ComponentFile
+	// Require 组件需要写入go.mod的依赖，为nil表示不需要
+	Require func(data templates.Data) []templates.Module
+	// Check 检查与项目已有内容的冲突（文件冲突由Conflicts统一检查），为nil表示没有额外检查
+	Check func(projectPath string, mod *GoMod, data templates.Data) []string
+	// Next 添加完成后的后续操作提示
+	Next func(data templates.Data) []string
+}
+
+// Components 支持添加的组件列表
+var Components = []Component{
+	{
+		Name:        "dockerfile",
+		Description: "Dockerfile、.dockerignore和docker-compose.yml",
+		Files: func(data templates.Data) []ComponentFile {
+			return []ComponentFile{
+				{"Dockerfile", templates.DockerfileTemplate},
+				{".dockerignore", templates.DockerignoreTemplate},
+				{"docker-compose.yml", templates.DockerComposeTemplate},
+			}
+		},
+		Next: func(data templates.Data) []string {
+			return []string{"docker compose up --build"}
+		},
+	},
+	{
+		Name:        "systemd",
+		Description: "systemd服务单元",
+		Files: func(data templates.Data) []ComponentFile {
+			return []ComponentFile{{"deploy/" + data.ProjectName + ".service", templates.SystemdTemplate}}
+		},
+		Next: func(data templates.Data) []string {
+			return []string{
+				fmt.Sprintf("sudo cp deploy/%s.service /etc/systemd/system/", data.ProjectName),
+				fmt.Sprintf("sudo systemctl daemon-reload && sudo systemctl enable --now %s", data.ProjectName),
+			}
+		},
+	},
+	{
+		Name:        "nginx",
+		Description: "nginx配置、配置脚本和SSL证书申请脚本",
+		Files: func(data templates.Data) []ComponentFile {
+			files := []ComponentFile{
+				{"config/" + data.Domain, data.NginxTemplate()},
+				{"config/setup-nginx.sh", templates.NginxSetupScriptTemplate},
+				{"scripts/apply-ssl.sh", templates.CertbotScriptTemplate},
+			}
+			if data.Compression != nil && data.Compression.Brotli {
+				files = append(files, ComponentFile{templates.BrotliConfigFile, templates.NginxBrotliTemplate})
+			}
+			return files
+		},
+		Next: func(data templates.Data) []string {
+			return []string{
+				fmt.Sprintf("config/setup-nginx.sh %s", data.Domain),
+				fmt.Sprintf("scripts/apply-ssl.sh %s", data.Domain),
+			}
+		},
+	},
+	{
+		Name:        "makefile",
+		Description: "常用构建命令的Makefile",
+		Files: func(data templates.Data) []ComponentFile {
+			return []ComponentFile{{"Makefile", templates.MakefileTemplate}}
+		},
+		Next: func(data templates.Data) []string {
+			return []string{"make build"}
+		},
+	},
+	{
+		Name:        "ci",
+		Description: "GitHub Actions持续集成工作流",
+		Files: func(data templates.Data) []ComponentFile {
+			return []ComponentFile{{".github/workflows/ci.yml", templates.CITemplate}}
+		},
+	},
+	{
+		Name:        "healthcheck",
+		Description: "internal/health健康检查包和探测脚本",
+		Files: func(data templates.Data) []ComponentFile {
+			return []ComponentFile{
+				{"internal/health/health.go", templates.HealthTemplate},
+				{"scripts/healthcheck.sh", templates.HealthScriptTemplate},
+			}
+		},
+		Check: func(projectPath string, mod *GoMod, data templates.Data) []string {
+			content, err := os.ReadFile(filepath.Join(projectPath, "main.go"))
+			if err == nil && (strings.Contains(string(content), `"/health"`) || strings.Contains(string(content), `"GET /health"`)) {
+				return []string{"main.go 中已注册 /health 路由，需要手动替换为 health.Handler()"}
+			}
+			return nil
+		},
+		Next: func(data templates.Data) []string {
+			return []string{healthRoutes[data.Framework.Name]}
+		},
+	},
+	{
+		Name:        "db",
+		Description: "internal/db数据库连接包（使用 --db 选择数据库）",
+		Files: func(data templates.Data) []ComponentFile {
+			return []ComponentFile{{"internal/db/db.go", templates.DatabaseTemplate}}
+		},
+		Require: func(data templates.Data) []templates.Module {
+			return []templates.Module{data.Database.Driver}
+		},
+		Check: func(projectPath string, mod *GoMod, data templates.Data) []string {
+			var conflicts []string
+			for _, db := range templates.Databases {
+				if db.Name != data.Database.Name && mod.Requires(db.Driver.Path) {
+					conflicts = append(conflicts, fmt.Sprintf("go.mod 中已依赖%s驱动 %s", db.Title, db.Driver.Path))
+				}
+			}
+			return conflicts
+		},
+		Next: func(data templates.Data) []string {
+			return []string{"go mod tidy"}
+		},
+	},
+}
+
+// healthRoutes 各框架挂载健康检查接口的写法
+var healthRoutes = map[string]string{
+	"gin":     `r.GET("/health", gin.WrapH(health.Handler()))`,
+	"echo":    `e.GET("/health", echo.WrapHandler(health.Handler()))`,
+	"chi":     `r.Method(http.MethodGet, "/health", health.Handler())`,
+	"fiber":   `app.Get("/health", adaptor.HTTPHandler(health.Handler()))`,
+	"nethttp": `mux.Handle("GET /health", health.Handler())`,
+}
+
+// LookupComponent 按名称查找组件，名称不区分大小写
+func LookupComponent(name string) (Component, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, component := range Components {
+		if component.Name == name {
+			return component, nil
+		}
+	}
+	return Component{}, fmt.Errorf("不支持的组件: %s（可选: %s）", name, strings.Join(ComponentNames(), ", "))
+}
+
+// ComponentNames 返回所有组件名称
+func ComponentNames() []string {
+	names := make([]string, 0, len(Components))
+	for _, component := range Components {
+		names = append(names, component.Name)
+	}
+	return names
+}
+
+// LoadProjectData 读取已有项目的模板数据：优先使用 aigo.yaml，
+// 没有项目清单的旧项目从go.mod推断模块路径、Go版本、框架和数据库
+func LoadProjectData(projectPath string) (templates.Data, *GoMod, error) {
+	mod, err := ReadGoMod(projectPath)
+	if err != nil {
+		return templates.Data{}, nil, err
+	}
+
+	data := templates.NewData(path.Base(mod.Module))
+	data.ModulePath = mod.Module
+	data.Nginx = false
+	if mod.GoVersion != "" {
+		data.GoVersion = mod.GoVersion
+	}
+
+	// 没有依赖任何已知框架时按标准库处理
+	data.Framework, _ = templates.LookupFramework("nethttp")
+	for _, fw := range templates.Frameworks {
+		if len(fw.Require) > 0 && mod.Requires(fw.Require[0].Path) {
+			data.Framework = fw
+			break
+		}
+	}
+	for _, db := range templates.Databases {
+		if mod.Requires(db.Driver.Path) {
+			db := db
+			data.Database = &db
+			break
+		}
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "Dockerfile")); err == nil {
+		data.Docker = true
+	}
+
+	project, err := config.LoadProject(projectPath)
+	switch {
+	case err == nil:
+		if err := data.ApplyProject(project); err != nil {
+			return templates.Data{}, nil, err
+		}
+	case !errors.Is(err, config.ErrNoProject):
+		return templates.Data{}, nil, err
+	}
+	return data, mod, nil
+}
+
+// UpdateProjectFile 修改项目清单 aigo.yaml，没有项目清单的旧项目按模板数据新建
+func (pg *ProjectGenerator) UpdateProjectFile(update func(p *config.Project)) error {
+	project, err := config.LoadProject(pg.projectPath)
+	if errors.Is(err, config.ErrNoProject) {
+		project, err = pg.data.Project(), nil
+	}
+	if err != nil {
+		return err
+	}
+
+	update(project)
+	content, err := project.Marshal()
+	if err != nil {
+		return err
+	}
+	// 项目清单允许用户修改，更新时不受覆盖策略限制
+	if err := pg.writer.Patch(filepath.Join(pg.projectPath, config.ProjectFile), content, config.FilePermission); err != nil {
+		return fmt.Errorf("更新%s失败: %v", config.ProjectFile, err)
+	}
+	return nil
+}
+
+// Conflicts 检查组件与项目已有内容的冲突：内容不同的已有文件和组件自身检查出的问题
+func (pg *ProjectGenerator) Conflicts(component Component, mod *GoMod) ([]string, error) {
+	var conflicts []string
+	for _, file := range component.Files(pg.data) {
+		old, err := os.ReadFile(filepath.Join(pg.projectPath, file.Path))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取文件 %s 失败: %v", file.Path, err)
+		}
+		content, err := templates.Render(file.Template, pg.data)
+		if err != nil {
+			return nil, fmt.Errorf("生成文件 %s 失败: %v", file.Path, err)
+		}
+		if string(old) != content {
+			conflicts = append(conflicts, fmt.Sprintf("文件 %s 已存在且内容不同", file.Path))
+		}
+	}
+	if component.Check != nil {
+		conflicts = append(conflicts, component.Check(pg.projectPath, mod, pg.data)...)
+	}
+	return conflicts, nil
+}
+
+// GenerateComponent 向已有项目添加组件：写入组件文件，并把缺少的依赖加入go.mod
+func (pg *ProjectGenerator) GenerateComponent(component Component, mod *GoMod) error {
+	for _, file := range component.Files(pg.data) {
+		content, err := templates.Render(file.Template, pg.data)
+		if err != nil {
+			return fmt.Errorf("生成文件 %s 失败: %v", file.Path, err)
+		}
+		mode := os.FileMode(config.FilePermission)
+		if strings.HasSuffix(file.Path, ".sh") {
+			mode = config.ScriptPermission
+		}
+		if err := pg.writeFileMode(file.Path, content, mode); err != nil {
+			return fmt.Errorf("生成文件 %s 失败: %v", file.Path, err)
+		}
+	}
+
+	if component.Require == nil {
+		return nil
+	}
+	require := component.Require(pg.data)
+	if len(mod.Missing(require)) == 0 {
+		return nil
+	}
+
+	// go.mod由用户维护，添加依赖不受覆盖策略限制
+	goMod := filepath.Join(pg.projectPath, "go.mod")
+	mode := os.FileMode(config.FilePermission)
+	if info, err := os.Stat(goMod); err == nil {
+		mode = info.Mode().Perm()
+	}
+	content := mod.AddRequire(require)
+	if err := pg.writer.Patch(goMod, []byte(content), mode); err != nil {
+		return fmt.Errorf("更新go.mod失败: %v", err)
+	}
+	if updated, err := ParseGoMod(content); err == nil {
+		*mod = *updated
+	}
+	return nil
+}
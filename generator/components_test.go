@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/templates"
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// newExistingProject 创建只包含go.mod和main.go的已有项目
+func newExistingProject(t *testing.T, goMod string) string {
+	t.Helper()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	return dir
+}
+
+// TestLoadProjectData 测试从go.mod推断框架和数据库
+func TestLoadProjectData(t *testing.T) {
+	dir := newExistingProject(t, "module github.com/acme/shop\n\ngo 1.22\n\nrequire (\n\tgithub.com/labstack/echo/v4 v4.12.0\n\tgithub.com/go-sql-driver/mysql v1.8.1\n)\n")
+
+	data, _, err := LoadProjectData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.ProjectName != "shop" || data.ModulePath != "github.com/acme/shop" || data.GoVersion != "1.22" {
+		t.Errorf("项目信息推断错误: %+v", data)
+	}
+	if data.Framework.Name != "echo" || data.Database == nil || data.Database.Name != "mysql" {
+		t.Errorf("框架或数据库推断错误: %s %v", data.Framework.Name, data.Database)
+	}
+
+	if _, _, err := LoadProjectData(t.TempDir()); err == nil {
+		t.Error("缺少go.mod时应该返回错误")
+	}
+}
+
+// TestComponentsRegistry 测试每个组件都声明了可渲染的文件
+func TestComponentsRegistry(t *testing.T) {
+	data := templates.NewData("demo")
+	db, _ := templates.LookupDatabase("postgres")
+	data.Database = &db
+
+	for _, component := range Components {
+		files := component.Files(data)
+		if len(files) == 0 {
+			t.Errorf("组件 %s 没有声明文件", component.Name)
+		}
+		for _, file := range files {
+			if _, err := templates.Render(file.Template, data); err != nil {
+				t.Errorf("组件 %s 的文件 %s 渲染失败: %v", component.Name, file.Path, err)
+			}
+		}
+	}
+
+	if _, err := LookupComponent("kubernetes"); err == nil {
+		t.Error("未知组件应该返回错误")
+	}
+}
+
+// TestGenerateComponent 测试添加db组件写入文件并更新go.mod
+func TestGenerateComponent(t *testing.T) {
+	dir := newExistingProject(t, "module demo\n\ngo 1.22\n")
+	data, mod, _ := LoadProjectData(dir)
+	db, _ := templates.LookupDatabase("sqlite")
+	data.Database = &db
+
+	gen := NewProjectGeneratorWithData(dir, data)
+	gen.SetWriter(tools.NewFileWriter(tools.WriteFiles, &bytes.Buffer{}))
+	component, _ := LookupComponent("db")
+	if err := gen.GenerateComponent(component, mod); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "internal", "db", "db.go")); err != nil {
+		t.Error("应该生成internal/db/db.go")
+	}
+	goMod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if !strings.Contains(string(goMod), "modernc.org/sqlite v1.33.1") {
+		t.Errorf("go.mod应该添加驱动依赖:\n%s", goMod)
+	}
+}
+
+// TestComponentConflicts 测试冲突检查
+func TestComponentConflicts(t *testing.T) {
+	dir := newExistingProject(t, "module demo\n\ngo 1.22\n\nrequire github.com/jackc/pgx/v5 v5.7.1\n")
+	os.WriteFile(filepath.Join(dir, "Makefile"), []byte("all:\n"), 0644)
+	data, mod, _ := LoadProjectData(dir)
+	gen := NewProjectGeneratorWithData(dir, data)
+
+	makefile, _ := LookupComponent("makefile")
+	conflicts, err := gen.Conflicts(makefile, mod)
+	if err != nil || len(conflicts) != 1 || !strings.Contains(conflicts[0], "Makefile") {
+		t.Errorf("已有Makefile应该报告冲突: %v %v", conflicts, err)
+	}
+
+	ci, _ := LookupComponent("ci")
+	if conflicts, _ := gen.Conflicts(ci, mod); len(conflicts) != 0 {
+		t.Errorf("没有已有文件时不应该有冲突: %v", conflicts)
+	}
+
+	// 已使用PostgreSQL时添加MySQL
+	mysql, _ := templates.LookupDatabase("mysql")
+	data.Database = &mysql
+	gen = NewProjectGeneratorWithData(dir, data)
+	dbComponent, _ := LookupComponent("db")
+	conflicts, _ = gen.Conflicts(dbComponent, mod)
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0], "PostgreSQL") {
+		t.Errorf("已有其他数据库驱动时应该报告冲突: %v", conflicts)
+	}
+}
+
+// TestLoadProjectDataFromManifest 测试aigo.yaml优先于go.mod推断
+func TestLoadProjectDataFromManifest(t *testing.T) {
+	dir := newExistingProject(t, "module github.com/acme/shop\n\ngo 1.22\n\nrequire github.com/labstack/echo/v4 v4.12.0\n")
+	manifest := "name: shop-api\nport: \"9100\"\ndomains:\n  - shop.acme.com\nframework: echo\ncomponents:\n  - nginx\n"
+	os.WriteFile(filepath.Join(dir, "aigo.yaml"), []byte(manifest), 0644)
+
+	data, _, err := LoadProjectData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.ProjectName != "shop-api" || data.Port != "9100" || data.Domain != "shop.acme.com" || !data.Nginx {
+		t.Errorf("应该使用aigo.yaml中的配置: %+v", data)
+	}
+
+	os.WriteFile(filepath.Join(dir, "aigo.yaml"), []byte("framework: rails\n"), 0644)
+	if _, _, err := LoadProjectData(dir); err == nil {
+		t.Error("aigo.yaml中的框架无效时应该返回错误")
+	}
+}
+
+// TestUpdateProjectFile 测试没有项目清单的旧项目添加组件时新建清单
+func TestUpdateProjectFile(t *testing.T) {
+	dir := newExistingProject(t, "module demo\n\ngo 1.22\n")
+	data, _, _ := LoadProjectData(dir)
+	gen := NewProjectGeneratorWithData(dir, data)
+	gen.SetWriter(tools.NewFileWriter(tools.WriteFiles, &bytes.Buffer{}))
+
+	err := gen.UpdateProjectFile(func(p *config.Project) { p.AddComponent("makefile") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := config.LoadProject(dir)
+	if err != nil || project.Name != "demo" || !project.HasComponent("makefile") {
+		t.Errorf("项目清单内容错误: %+v %v", project, err)
+	}
+}
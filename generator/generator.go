@@ -3,16 +3,22 @@ package generator
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/yggai/aigo_hotreload/config"
 	"github.com/yggai/aigo_hotreload/templates"
+	"github.com/yggai/aigo_hotreload/tools"
 )
 
 // ProjectGenerator 项目生成器
 type ProjectGenerator struct {
 	projectPath string
 	projectName string
+	data        templates.Data
+	writer      *tools.FileWriter
 }
 
 // NewProjectGenerator 创建新的项目生成器
@@ -20,73 +26,148 @@ func NewProjectGenerator(projectPath, projectName string) *ProjectGenerator {
 	return &ProjectGenerator{
 		projectPath: projectPath,
 		projectName: projectName,
+		data:        templates.NewData(projectName),
+		writer:      tools.NewFileWriter(tools.WriteFiles, os.Stdout),
 	}
 }
 
+// NewProjectGeneratorWithData 使用指定的模板数据创建项目生成器
+func NewProjectGeneratorWithData(projectPath string, data templates.Data) *ProjectGenerator {
+	return &ProjectGenerator{
+		projectPath: projectPath,
+		projectName: data.ProjectName,
+		data:        data,
+		writer:      tools.NewFileWriter(tools.WriteFiles, os.Stdout),
+	}
+}
+
+// SetWriter 设置文件写入器，用于dry-run和diff模式
+func (pg *ProjectGenerator) SetWriter(writer *tools.FileWriter) {
+	pg.writer = writer
+}
+
 // GenerateAll 生成所有项目文件
 func (pg *ProjectGenerator) GenerateAll() error {
-	files := []struct {
+	type file struct {
 		filename string
-		content  string
-	}{
-		{"go.mod", fmt.Sprintf(templates.GoModTemplate, pg.projectName)},
+		template string
+	}
+	files := []file{
+		{"go.mod", templates.GoModTemplate},
 		{"main.go", templates.MainGoTemplate},
 		{".air.toml", templates.AirTomlTemplate},
 		{".gitignore", templates.GitignoreTemplate},
-		{"README.md", fmt.Sprintf(templates.ReadmeTemplate, pg.projectName, pg.projectName)},
+		{"README.md", templates.ReadmeTemplate},
+	}
+	if pg.data.Database != nil {
+		files = append(files, file{"internal/db/db.go", templates.DatabaseTemplate})
+	}
+	if pg.data.Docker {
+		files = append(files,
+			file{"Dockerfile", templates.DockerfileTemplate},
+			file{".dockerignore", templates.DockerignoreTemplate},
+			file{"docker-compose.yml", templates.DockerComposeTemplate},
+		)
+	}
+	if pg.data.Nginx {
+		files = append(files,
+			// nginx配置文件
+			file{"config/" + pg.data.Domain, pg.data.NginxTemplate()},
+			file{"config/setup-nginx.sh", templates.NginxSetupScriptTemplate},
+			// SSL证书申请脚本
+			file{"scripts/apply-ssl.sh", templates.CertbotScriptTemplate},
+		)
 	}
 
 	for _, file := range files {
-		if err := pg.writeFile(file.filename, file.content); err != nil {
+		content, err := templates.Render(file.template, pg.data)
+		if err != nil {
+			return fmt.Errorf("生成文件 %s 失败: %v", file.filename, err)
+		}
+		// 脚本需要执行权限
+		mode := os.FileMode(config.FilePermission)
+		if strings.HasSuffix(file.filename, ".sh") {
+			mode = config.ScriptPermission
+		}
+		if err := pg.writeFileMode(file.filename, content, mode); err != nil {
 			return fmt.Errorf("生成文件 %s 失败: %v", file.filename, err)
 		}
 	}
 
-	// 创建config目录
-	configDir := filepath.Join(pg.projectPath, "config")
-	if err := os.MkdirAll(configDir, config.DirPermission); err != nil {
-		return fmt.Errorf("创建config目录失败: %v", err)
+	return pg.writeProjectFile()
+}
+
+// writeProjectFile 写入项目清单 aigo.yaml
+func (pg *ProjectGenerator) writeProjectFile() error {
+	content, err := pg.data.Project().Marshal()
+	if err != nil {
+		return err
+	}
+	if err := pg.writeFileMode(config.ProjectFile, string(content), config.FilePermission); err != nil {
+		return fmt.Errorf("生成文件 %s 失败: %v", config.ProjectFile, err)
 	}
+	return nil
+}
 
-	// 生成nginx配置文件
-	nginxFiles := []struct {
-		filename string
-		content  string
-	}{
-		{"config/your-domain.com", fmt.Sprintf(templates.NginxHTTPTemplate, "your-domain.com", config.DefaultPort, "your-domain.com", "your-domain.com")},
-		{"config/setup-nginx.sh", templates.NginxSetupScriptTemplate},
+// GenerateCustom 使用用户自定义模板生成项目文件，并执行post_generate钩子
+func (pg *ProjectGenerator) GenerateCustom(custom *templates.Custom) error {
+	files, err := custom.Render(pg.data)
+	if err != nil {
+		return err
 	}
 
-	for _, file := range nginxFiles {
-		if err := pg.writeFile(file.filename, file.content); err != nil {
-			return fmt.Errorf("生成文件 %s 失败: %v", file.filename, err)
+	hasProjectFile := false
+	for _, file := range files {
+		mode := file.Mode
+		if mode == 0 {
+			mode = config.FilePermission
 		}
+		if err := pg.writeFileMode(file.Path, string(file.Content), mode); err != nil {
+			return fmt.Errorf("生成文件 %s 失败: %v", file.Path, err)
+		}
+		hasProjectFile = hasProjectFile || file.Path == config.ProjectFile
 	}
 
-	// 创建scripts目录
-	scriptsDir := filepath.Join(pg.projectPath, "scripts")
-	if err := os.MkdirAll(scriptsDir, config.DirPermission); err != nil {
-		return fmt.Errorf("创建scripts目录失败: %v", err)
+	// 模板没有提供项目清单时按模板数据生成
+	if !hasProjectFile {
+		if err := pg.writeProjectFile(); err != nil {
+			return err
+		}
 	}
 
-	// 生成SSL证书申请脚本
-	sslScriptFile := "scripts/apply-ssl.sh"
-	if err := pg.writeFile(sslScriptFile, templates.CertbotScriptTemplate); err != nil {
-		return fmt.Errorf("生成文件 %s 失败: %v", sslScriptFile, err)
+	for _, hook := range custom.Manifest.Hooks.PostGenerate {
+		if pg.writer.Preview() {
+			fmt.Fprintf(os.Stdout, "  [hook] %s\n", hook)
+			continue
+		}
+		if err := pg.runHook(hook); err != nil {
+			return fmt.Errorf("执行钩子 %q 失败: %v", hook, err)
+		}
 	}
-
 	return nil
 }
 
+// runHook 在项目目录中执行钩子命令，模板变量通过环境变量传入
+func (pg *ProjectGenerator) runHook(hook string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", hook)
+	} else {
+		cmd = exec.Command("sh", "-c", hook)
+	}
+	cmd.Dir = pg.projectPath
+	cmd.Env = append(os.Environ(), pg.data.Env()...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 // writeFile 写入文件
 func (pg *ProjectGenerator) writeFile(filename, content string) error {
-	filePath := filepath.Join(pg.projectPath, filename)
-	
-	// 确保目录存在
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, config.DirPermission); err != nil {
-		return fmt.Errorf("创建目录失败: %v", err)
-	}
-	
-	return os.WriteFile(filePath, []byte(content), config.FilePermission)
-}
\ No newline at end of file
+	return pg.writeFileMode(filename, content, config.FilePermission)
+}
+
+// writeFileMode 按指定权限写入文件
+func (pg *ProjectGenerator) writeFileMode(filename, content string, mode os.FileMode) error {
+	return pg.writer.WriteFile(filepath.Join(pg.projectPath, filename), []byte(content), mode)
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 	"unicode/utf8"
+
+	"github.com/yggai/aigo_hotreload/templates"
 )
 
 // TestProjectGeneratorCreation 测试ProjectGenerator创建
@@ -117,6 +119,7 @@ func TestGenerateAll(t *testing.T) {
 		".air.toml",
 		".gitignore",
 		"README.md",
+		"aigo.yaml",
 		"config/your-domain.com",
 		"config/setup-nginx.sh",
 		"scripts/apply-ssl.sh",
@@ -824,4 +827,84 @@ func TestGeneratedFileLineEndings(t *testing.T) {
 			t.Errorf("文件 %s 应该包含换行符", filename)
 		}
 	}
-} 
\ No newline at end of file
+} 
+// TestGenerateCustom 测试使用自定义模板生成项目并执行钩子
+func TestGenerateCustom(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("需要 /bin/sh 执行钩子")
+	}
+
+	templateDir := t.TempDir()
+	files := map[string]string{
+		"template.yaml":   "hooks:\n  post_generate:\n    - echo \"$AIGO_PROJECT_NAME:$AIGO_VAR_DB\" > hook.txt\n",
+		"main.go.tmpl":    "// {{.ProjectName}} {{.Vars.db}}\n",
+		"scripts/run.sh":  "#!/bin/sh\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(templateDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Chmod(filepath.Join(templateDir, "scripts/run.sh"), 0755)
+
+	custom, err := templates.LoadCustom(templateDir)
+	if err != nil {
+		t.Fatalf("加载模板失败: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	data := templates.NewData("demo")
+	data.Vars["db"] = "postgres"
+
+	generator := NewProjectGeneratorWithData(tempDir, data)
+	if err := generator.GenerateCustom(custom); err != nil {
+		t.Fatalf("使用自定义模板生成失败: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "main.go"))
+	if err != nil || string(content) != "// demo postgres\n" {
+		t.Errorf("main.go 内容错误: %q %v", content, err)
+	}
+
+	info, err := os.Stat(filepath.Join(tempDir, "scripts", "run.sh"))
+	if err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("run.sh 应保留可执行权限: %v", err)
+	}
+
+	hook, err := os.ReadFile(filepath.Join(tempDir, "hook.txt"))
+	if err != nil || strings.TrimSpace(string(hook)) != "demo:postgres" {
+		t.Errorf("钩子应在项目目录中执行并获得模板变量: %q %v", hook, err)
+	}
+}
+
+// TestGenerateAllOptionalAssets 测试按数据模型生成可选的Docker、数据库和nginx文件
+func TestGenerateAllOptionalAssets(t *testing.T) {
+	tempDir := t.TempDir()
+	data := templates.NewData("demo")
+	db, _ := templates.LookupDatabase("postgres")
+	data.Database = &db
+	data.Docker = true
+	data.Nginx = false
+
+	if err := NewProjectGeneratorWithData(tempDir, data).GenerateAll(); err != nil {
+		t.Fatalf("生成项目失败: %v", err)
+	}
+
+	for _, name := range []string{"Dockerfile", ".dockerignore", "docker-compose.yml", "internal/db/db.go"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); err != nil {
+			t.Errorf("应该生成 %s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"config/setup-nginx.sh", "scripts/apply-ssl.sh"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); !os.IsNotExist(err) {
+			t.Errorf("不使用nginx时不应该生成 %s", name)
+		}
+	}
+
+	goMod, _ := os.ReadFile(filepath.Join(tempDir, "go.mod"))
+	if !strings.Contains(string(goMod), "github.com/jackc/pgx/v5") {
+		t.Error("go.mod 应该包含数据库驱动依赖")
+	}
+}
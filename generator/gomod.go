@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// GoMod 已有项目go.mod中与生成相关的信息
+type GoMod struct {
+	Module    string
+	GoVersion string
+	Require   []templates.Module
+	content   string
+}
+
+// ReadGoMod 读取项目目录下的go.mod
+func ReadGoMod(projectPath string) (*GoMod, error) {
+	content, err := os.ReadFile(filepath.Join(projectPath, "go.mod"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s 不是Go项目: 缺少go.mod", projectPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取go.mod失败: %v", err)
+	}
+	return ParseGoMod(string(content))
+}
+
+// ParseGoMod 解析go.mod中的module、go版本和require
+func ParseGoMod(content string) (*GoMod, error) {
+	mod := &GoMod{content: content}
+	inRequire := false
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inRequire {
+			if fields[0] == ")" {
+				inRequire = false
+			} else if len(fields) >= 2 {
+				mod.Require = append(mod.Require, templates.Module{Path: unquote(fields[0]), Version: fields[1]})
+			}
+			continue
+		}
+
+		switch fields[0] {
+		case "module":
+			if len(fields) > 1 {
+				mod.Module = unquote(fields[1])
+			}
+		case "go":
+			if len(fields) > 1 {
+				mod.GoVersion = fields[1]
+			}
+		case "require":
+			if len(fields) == 2 && fields[1] == "(" {
+				inRequire = true
+			} else if len(fields) >= 3 {
+				mod.Require = append(mod.Require, templates.Module{Path: unquote(fields[1]), Version: fields[2]})
+			}
+		}
+	}
+
+	if mod.Module == "" {
+		return nil, fmt.Errorf("go.mod中缺少module声明")
+	}
+	return mod, nil
+}
+
+// Requires 判断go.mod是否已经依赖指定模块
+func (m *GoMod) Requires(path string) bool {
+	for _, module := range m.Require {
+		if module.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing 返回尚未出现在go.mod中的依赖
+func (m *GoMod) Missing(modules []templates.Module) []templates.Module {
+	var missing []templates.Module
+	for _, module := range modules {
+		if !m.Requires(module.Path) {
+			missing = append(missing, module)
+		}
+	}
+	return missing
+}
+
+// AddRequire 返回添加依赖后的go.mod内容，新依赖写入第一个require块，
+// 没有require块时在末尾新建，已存在的依赖保持原有版本
+func (m *GoMod) AddRequire(modules []templates.Module) string {
+	missing := m.Missing(modules)
+	if len(missing) == 0 {
+		return m.content
+	}
+
+	var entries strings.Builder
+	for _, module := range missing {
+		fmt.Fprintf(&entries, "\t%s %s\n", module.Path, module.Version)
+	}
+
+	lines := strings.SplitAfter(m.content, "\n")
+	for i, line := range lines {
+		if strings.Join(strings.Fields(line), " ") != "require (" {
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == ")" {
+				return strings.Join(lines[:j], "") + entries.String() + strings.Join(lines[j:], "")
+			}
+		}
+	}
+
+	content := m.content
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + "\nrequire (\n" + entries.String() + ")\n"
+}
+
+// unquote 去掉go.mod中带引号路径的引号
+func unquote(s string) string {
+	return strings.Trim(s, "\"`")
+}
@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// TestParseGoMod 测试解析module、go版本和两种require写法
+func TestParseGoMod(t *testing.T) {
+	content := `module github.com/acme/api // 注释
+
+go 1.22
+
+require github.com/go-chi/chi/v5 v5.1.0
+
+require (
+	github.com/jackc/pgx/v5 v5.7.1
+	golang.org/x/text v0.15.0 // indirect
+)
+`
+	mod, err := ParseGoMod(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Module != "github.com/acme/api" || mod.GoVersion != "1.22" {
+		t.Errorf("module或go版本解析错误: %+v", mod)
+	}
+	for _, path := range []string{"github.com/go-chi/chi/v5", "github.com/jackc/pgx/v5", "golang.org/x/text"} {
+		if !mod.Requires(path) {
+			t.Errorf("应该解析出依赖 %s", path)
+		}
+	}
+
+	if _, err := ParseGoMod("go 1.22\n"); err == nil {
+		t.Error("缺少module时应该返回错误")
+	}
+}
+
+// TestGoModAddRequire 测试向go.mod添加依赖
+func TestGoModAddRequire(t *testing.T) {
+	driver := templates.Module{Path: "modernc.org/sqlite", Version: "v1.33.1"}
+
+	mod, _ := ParseGoMod("module demo\n\ngo 1.22\n\nrequire (\n\tgithub.com/go-chi/chi/v5 v5.1.0\n)\n")
+	got := mod.AddRequire([]templates.Module{driver, {Path: "github.com/go-chi/chi/v5", Version: "v5.2.0"}})
+	want := "module demo\n\ngo 1.22\n\nrequire (\n\tgithub.com/go-chi/chi/v5 v5.1.0\n\tmodernc.org/sqlite v1.33.1\n)\n"
+	if got != want {
+		t.Errorf("应该写入已有的require块且不修改已有依赖:\n%s", got)
+	}
+
+	mod, _ = ParseGoMod("module demo\n\ngo 1.22")
+	got = mod.AddRequire([]templates.Module{driver})
+	if !strings.HasSuffix(got, "go 1.22\n\nrequire (\n\tmodernc.org/sqlite v1.33.1\n)\n") {
+		t.Errorf("没有require块时应该新建:\n%s", got)
+	}
+
+	if mod.AddRequire(nil) != "module demo\n\ngo 1.22" {
+		t.Error("没有新依赖时不应该修改内容")
+	}
+}
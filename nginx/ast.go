@@ -0,0 +1,105 @@
+// Package nginx 解析nginx配置文件并进行静态检查，不依赖本机安装的nginx
+package nginx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Config 一个配置文件解析后的语法树
+type Config struct {
+	File       string
+	Directives []*Directive
+}
+
+// Directive 配置指令，带有块的指令（如 server、location）的子指令保存在Block中
+type Directive struct {
+	Name  string
+	Args  []string
+	File  string
+	Line  int
+	Block []*Directive // 不带块的指令为nil，空块为长度为0的切片
+
+	// Includes include指令展开后的配置文件，仅由ParseFile填充
+	Includes []*Config
+}
+
+// IsBlock 判断指令是否带有块
+func (d *Directive) IsBlock() bool {
+	return d.Block != nil
+}
+
+// Arg 返回第i个参数，不存在时返回空字符串
+func (d *Directive) Arg(i int) string {
+	if i < 0 || i >= len(d.Args) {
+		return ""
+	}
+	return d.Args[i]
+}
+
+// Position 返回 file:line 形式的位置
+func (d *Directive) Position() string {
+	return fmt.Sprintf("%s:%d", d.File, d.Line)
+}
+
+// String 以单行形式返回指令，块内容省略
+func (d *Directive) String() string {
+	s := strings.Join(append([]string{d.Name}, d.Args...), " ")
+	if d.IsBlock() {
+		return s + " {...}"
+	}
+	return s + ";"
+}
+
+// WalkFunc 遍历时的回调，parents为从外到内的父级块指令；返回false时不进入该指令的块
+type WalkFunc func(d *Directive, parents []*Directive) bool
+
+// Walk 深度优先遍历全部指令，include展开的文件按原位置遍历
+func (c *Config) Walk(fn WalkFunc) {
+	walk(c.Directives, nil, fn)
+}
+
+// walk 遍历一组指令
+func walk(directives []*Directive, parents []*Directive, fn WalkFunc) {
+	for _, d := range directives {
+		if !fn(d, parents) {
+			continue
+		}
+		for _, included := range d.Includes {
+			walk(included.Directives, parents, fn)
+		}
+		if d.IsBlock() {
+			walk(d.Block, append(parents[:len(parents):len(parents)], d), fn)
+		}
+	}
+}
+
+// Find 返回所有指定名称的指令
+func (c *Config) Find(name string) []*Directive {
+	var found []*Directive
+	c.Walk(func(d *Directive, _ []*Directive) bool {
+		if d.Name == name {
+			found = append(found, d)
+		}
+		return true
+	})
+	return found
+}
+
+// Find 返回块内（不含嵌套块）指定名称的指令
+func (d *Directive) Find(name string) []*Directive {
+	var found []*Directive
+	for _, child := range d.Block {
+		if child.Name == name {
+			found = append(found, child)
+		}
+		for _, included := range child.Includes {
+			for _, nested := range included.Directives {
+				if nested.Name == name {
+					found = append(found, nested)
+				}
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,46 @@
+package nginx
+
+import (
+	"testing"
+)
+
+// TestDirectiveHelpers 测试指令的辅助方法
+func TestDirectiveHelpers(t *testing.T) {
+	config, err := Parse("site.conf", []byte("server {\n    listen 443 ssl;\n    location / { return 200; }\n}\n"))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	listen := config.Find("listen")[0]
+	if listen.String() != "listen 443 ssl;" {
+		t.Errorf("String() 返回 %q", listen.String())
+	}
+	if listen.Position() != "site.conf:2" {
+		t.Errorf("Position() 返回 %q", listen.Position())
+	}
+	if listen.Arg(5) != "" {
+		t.Error("不存在的参数应该返回空字符串")
+	}
+	if got := config.Find("location")[0].String(); got != "location / {...}" {
+		t.Errorf("块指令的String() 返回 %q", got)
+	}
+}
+
+// TestWalkSkipBlock 测试回调返回false时不进入块
+func TestWalkSkipBlock(t *testing.T) {
+	config, err := Parse("nginx.conf", []byte("stream { server { listen 53; } }\nhttp { server { listen 80; } }\n"))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	var listens []string
+	config.Walk(func(d *Directive, parents []*Directive) bool {
+		if d.Name == "listen" {
+			listens = append(listens, d.Arg(0))
+		}
+		return d.Name != "stream"
+	})
+	if len(listens) != 1 || listens[0] != "80" {
+		t.Errorf("应该跳过stream块, 实际得到 %v", listens)
+	}
+}
@@ -0,0 +1,46 @@
+package nginx
+
+import (
+	"errors"
+	"strings"
+)
+
+// CertificateRef 配置中引用的证书文件
+type CertificateRef struct {
+	Path      string
+	Directive *Directive
+}
+
+// Certificates 返回配置中 ssl_certificate 引用的证书文件，包含变量的路径无法静态解析，被跳过
+func Certificates(configs ...*Config) []CertificateRef {
+	var refs []CertificateRef
+	for _, config := range configs {
+		config.Walk(func(d *Directive, _ []*Directive) bool {
+			if d.Name == "ssl_certificate" && d.Arg(0) != "" && !strings.Contains(d.Arg(0), "$") {
+				refs = append(refs, CertificateRef{Path: d.Arg(0), Directive: d})
+			}
+			return true
+		})
+	}
+	return refs
+}
+
+// CertificatePaths 解析文件或目录中的全部配置文件，返回其中引用的证书文件，目录的展开方式与LintPaths相同；
+// 无法解析的文件不影响其他文件，每个文件的错误通过errors.Join合并返回
+func CertificatePaths(paths ...string) ([]CertificateRef, error) {
+	files, err := configFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	var configs []*Config
+	var errs []error
+	for _, file := range files {
+		config, err := ParseFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		configs = append(configs, config)
+	}
+	return Certificates(topLevel(configs)...), errors.Join(errs...)
+}
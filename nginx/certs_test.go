@@ -0,0 +1,46 @@
+package nginx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCertificatePaths 测试收集配置和include文件中引用的证书
+func TestCertificatePaths(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.com":       "server {\n    listen 443 ssl;\n    ssl_certificate /etc/letsencrypt/live/a.com/fullchain.pem;\n    ssl_certificate_key /etc/letsencrypt/live/a.com/privkey.pem;\n    include ssl.inc;\n}\n",
+		"ssl.inc":     "ssl_certificate \"/etc/ssl/shared.pem\";\n",
+		"dynamic.com": "server { ssl_certificate /etc/ssl/$ssl_server_name.pem; }\n",
+		"broken.com":  "server {\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 无法解析的文件返回错误，但不影响其他文件
+	refs, err := CertificatePaths(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.com") {
+		t.Errorf("应该返回broken.com的解析错误, 实际得到 %v", err)
+	}
+	got := map[string]string{}
+	for _, ref := range refs {
+		got[ref.Path] = ref.Directive.Position()
+	}
+	want := map[string]string{
+		"/etc/letsencrypt/live/a.com/fullchain.pem": filepath.Join(dir, "a.com") + ":3",
+		"/etc/ssl/shared.pem":                       filepath.Join(dir, "ssl.inc") + ":1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("期望 %v, 实际得到 %v", want, got)
+	}
+	for path, position := range want {
+		if got[path] != position {
+			t.Errorf("%s 的位置应该是 %s, 实际得到 %s", path, position, got[path])
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package nginx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// DefaultRoot nginx配置根目录
+const DefaultRoot = templates.NginxRoot
+
+// 默认的检查配置和重新加载命令
+const (
+	DefaultTestCommand   = "nginx -t"
+	DefaultReloadCommand = "systemctl reload nginx"
+)
+
+// htpasswdGroups 依次尝试的nginx worker所在的组
+var htpasswdGroups = []string{"www-data", "nginx"}
+
+// Deployer 将项目中生成的nginx配置安装到nginx目录，与 setup-nginx.sh 的步骤相同；
+// 修改前保存原有状态，检查命令失败时全部恢复
+type Deployer struct {
+	Root          string // nginx配置根目录
+	TestCommand   string // 检查配置的命令
+	ReloadCommand string // 重新加载nginx的命令，为空时不重新加载
+	BackupDir     string // 被替换文件的备份目录，为空时只在内存中保存
+	Logf          func(format string, args ...interface{})
+}
+
+// NewDeployer 创建使用默认目录和命令的Deployer
+func NewDeployer() *Deployer {
+	return &Deployer{
+		Root:          DefaultRoot,
+		TestCommand:   DefaultTestCommand,
+		ReloadCommand: DefaultReloadCommand,
+	}
+}
+
+// SiteLink 返回域名在sites-enabled中的软链接路径
+func (d *Deployer) SiteLink(domain string) string {
+	return filepath.Join(d.Root, "sites-enabled", domain)
+}
+
+// Enable 启用项目config目录中的域名配置: 链接到sites-enabled，安装htpasswd文件和brotli配置片段，
+// 检查通过后重新加载nginx
+func (d *Deployer) Enable(domain, projectPath string) error {
+	configFile, err := filepath.Abs(filepath.Join(projectPath, "config", domain))
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("配置文件 %s 不存在，请先运行 aigo_hotreload nginx %s 生成", configFile, domain)
+	}
+	// htpasswd文件和brotli配置片段安装在Root下，配置中引用的路径必须相同
+	site := templates.Data{Domain: domain, NginxRoot: d.Root}
+	if err := d.checkPaths(configFile, content, site); err != nil {
+		return err
+	}
+
+	t := &transaction{deployer: d}
+	if err := t.symlink(configFile, d.SiteLink(domain)); err != nil {
+		return t.abort(err)
+	}
+	d.logf("已链接 %s -> %s", d.SiteLink(domain), configFile)
+
+	// basic auth密码文件只允许root和nginx所在的组读取
+	htpasswdFile := configFile + ".htpasswd"
+	if content, err := os.ReadFile(htpasswdFile); err == nil {
+		target := filepath.FromSlash(site.HtpasswdFile())
+		if err := t.install(target, content, 0640); err != nil {
+			return t.abort(err)
+		}
+		if !chgrp(target) {
+			if err := os.Chmod(target, 0644); err != nil {
+				return t.abort(err)
+			}
+		}
+		d.logf("已安装basic auth密码文件 %s", target)
+	} else if !os.IsNotExist(err) {
+		return t.abort(err)
+	}
+
+	// nginx没有brotli模块时只使用gzip压缩
+	brotliFile, err := filepath.Abs(filepath.Join(projectPath, "config", "brotli.conf"))
+	if err != nil {
+		return t.abort(err)
+	}
+	if _, err := os.Stat(brotliFile); err == nil {
+		if d.hasBrotli() {
+			snippet := filepath.FromSlash(site.BrotliSnippet())
+			if err := t.symlink(brotliFile, snippet); err != nil {
+				return t.abort(err)
+			}
+			d.logf("已启用brotli压缩")
+		} else {
+			d.logf("nginx未安装brotli模块，跳过brotli压缩")
+		}
+	}
+	return t.commit()
+}
+
+// Disable 删除域名在sites-enabled中的配置，检查通过后重新加载nginx；htpasswd文件保留
+func (d *Deployer) Disable(domain string) error {
+	link := d.SiteLink(domain)
+	if _, err := os.Lstat(link); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("域名 %s 未启用: %s 不存在", domain, link)
+		}
+		return err
+	}
+	t := &transaction{deployer: d}
+	if err := t.remove(link); err != nil {
+		return t.abort(err)
+	}
+	d.logf("已删除 %s", link)
+	return t.commit()
+}
+
+// checkPaths 检查配置引用的htpasswd文件和brotli配置片段与Enable安装的路径相同，
+// 生成配置时的 --nginx-root 与Root不同时返回错误
+func (d *Deployer) checkPaths(configFile string, content []byte, site templates.Data) error {
+	config, err := Parse(configFile, content)
+	if err != nil {
+		return err
+	}
+	snippet := filepath.Base(templates.BrotliSnippet)
+	var mismatch error
+	config.Walk(func(directive *Directive, _ []*Directive) bool {
+		path, want := directive.Arg(0), ""
+		switch {
+		case directive.Name == "auth_basic_user_file":
+			want = site.HtpasswdFile()
+		case directive.Name == "include" && strings.HasPrefix(filepath.Base(path), strings.TrimSuffix(snippet, ".conf")):
+			want = site.BrotliInclude()
+		default:
+			return true
+		}
+		if path != want && mismatch == nil {
+			mismatch = fmt.Errorf("%s: 引用的 %s 与安装路径 %s 不同，请运行 aigo_hotreload nginx %s --nginx-root %s 重新生成配置",
+				directive.Position(), path, want, site.Domain, d.Root)
+		}
+		return true
+	})
+	return mismatch
+}
+
+// hasBrotli 判断nginx是否加载了brotli模块
+func (d *Deployer) hasBrotli() bool {
+	modules, _ := os.ReadDir(filepath.Join(d.Root, "modules-enabled"))
+	for _, m := range modules {
+		if strings.Contains(m.Name(), "brotli") {
+			return true
+		}
+	}
+	main, _ := os.ReadFile(filepath.Join(d.Root, "nginx.conf"))
+	return bytes.Contains(main, []byte("brotli_module"))
+}
+
+// logf 输出部署步骤
+func (d *Deployer) logf(format string, args ...interface{}) {
+	if d.Logf != nil {
+		d.Logf(format, args...)
+	}
+}
+
+// Reload 执行重新加载nginx的命令，用于证书更新后让nginx使用新证书
+func Reload(command string) error {
+	return run(command)
+}
+
+// run 执行检查或重新加载命令，失败时返回包含命令输出的错误
+func run(command string) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+	out, err := exec.Command(fields[0], fields[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s 失败: %v\n%s", command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// chgrp 将文件的组改为nginx worker所在的组，都不存在时返回false
+func chgrp(path string) bool {
+	for _, name := range htpasswdGroups {
+		group, err := user.LookupGroup(name)
+		if err != nil {
+			continue
+		}
+		gid, err := strconv.Atoi(group.Gid)
+		if err == nil && os.Chown(path, -1, gid) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot 文件修改前的状态
+type snapshot struct {
+	path    string
+	exists  bool
+	link    string // 软链接的目标，为空表示普通文件
+	content []byte
+	mode    os.FileMode
+}
+
+// transaction 记录每个文件修改前的状态，用于失败时恢复
+type transaction struct {
+	deployer  *Deployer
+	snapshots []snapshot
+}
+
+// save 保存文件修改前的状态，设置了备份目录时同时写入备份
+func (t *transaction) save(path string) error {
+	s := snapshot{path: path}
+	info, err := os.Lstat(path)
+	switch {
+	case os.IsNotExist(err):
+		t.snapshots = append(t.snapshots, s)
+		return nil
+	case err != nil:
+		return err
+	}
+	s.exists = true
+	s.mode = info.Mode().Perm()
+	if info.Mode()&os.ModeSymlink != 0 {
+		if s.link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	} else if s.content, err = os.ReadFile(path); err != nil {
+		return err
+	}
+	t.snapshots = append(t.snapshots, s)
+
+	if t.deployer.BackupDir == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(t.deployer.Root, path)
+	if err != nil {
+		return err
+	}
+	backup := filepath.Join(t.deployer.BackupDir, rel)
+	if err := s.restoreTo(backup); err != nil {
+		return fmt.Errorf("备份 %s 失败: %v", path, err)
+	}
+	t.deployer.logf("已备份 %s -> %s", path, backup)
+	return nil
+}
+
+// symlink 创建或替换软链接
+func (t *transaction) symlink(target, path string) error {
+	if err := t.save(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(target, path)
+}
+
+// install 写入文件，替换已有的文件或软链接
+func (t *transaction) install(path string, content []byte, mode os.FileMode) error {
+	if err := t.save(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.WriteFile(path, content, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// remove 删除文件
+func (t *transaction) remove(path string) error {
+	if err := t.save(path); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// commit 执行检查命令，失败时恢复全部修改，通过后重新加载nginx
+func (t *transaction) commit() error {
+	d := t.deployer
+	if err := run(d.TestCommand); err != nil {
+		if rerr := t.rollback(); rerr != nil {
+			return fmt.Errorf("%v\n恢复原有配置失败: %v", err, rerr)
+		}
+		return fmt.Errorf("%v\n已恢复原有配置", err)
+	}
+	d.logf("nginx配置检查通过")
+	if err := run(d.ReloadCommand); err != nil {
+		return fmt.Errorf("重新加载nginx失败，配置已通过检查，可以稍后手动重新加载: %v", err)
+	}
+	return nil
+}
+
+// abort 修改过程中出错时恢复已做的修改
+func (t *transaction) abort(err error) error {
+	if rerr := t.rollback(); rerr != nil {
+		return fmt.Errorf("%v\n恢复原有配置失败: %v", err, rerr)
+	}
+	return err
+}
+
+// rollback 按相反顺序恢复全部文件
+func (t *transaction) rollback() error {
+	for i := len(t.snapshots) - 1; i >= 0; i-- {
+		s := t.snapshots[i]
+		if err := s.restoreTo(s.path); err != nil {
+			return fmt.Errorf("恢复 %s 失败: %v", s.path, err)
+		}
+	}
+	return nil
+}
+
+// restoreTo 将快照中的状态写入path
+func (s snapshot) restoreTo(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !s.exists {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if s.link != "" {
+		return os.Symlink(s.link, path)
+	}
+	if err := os.WriteFile(path, s.content, s.mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, s.mode)
+}
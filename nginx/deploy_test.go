@@ -0,0 +1,184 @@
+package nginx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeNginx 创建模拟的nginx程序: 把参数追加到日志文件，配置中包含 invalid 时检查失败
+func fakeNginx(t *testing.T, root string) (binary, log string) {
+	t.Helper()
+	dir := t.TempDir()
+	binary = filepath.Join(dir, "nginx")
+	log = filepath.Join(dir, "calls.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\n" +
+		"if [ \"$1\" = \"-t\" ] && cat " + root + "/sites-enabled/* 2>/dev/null | grep -q invalid; then\n" +
+		"    echo 'nginx: [emerg] unknown directive \"invalid\"'\n    exit 1\nfi\n"
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return binary, log
+}
+
+// deployFixture 创建nginx目录、项目目录和使用模拟nginx的Deployer
+func deployFixture(t *testing.T) (d *Deployer, project, log string) {
+	t.Helper()
+	root := t.TempDir()
+	project = t.TempDir()
+	binary, log := fakeNginx(t, root)
+	if err := os.MkdirAll(filepath.Join(project, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	d = &Deployer{
+		Root:          root,
+		TestCommand:   binary + " -t",
+		ReloadCommand: binary + " -s reload",
+		BackupDir:     filepath.Join(project, ".aigo", "backups", "nginx"),
+	}
+	return d, project, log
+}
+
+// TestDeployerEnable 测试启用站点并安装htpasswd文件和brotli配置片段
+func TestDeployerEnable(t *testing.T) {
+	d, project, log := deployFixture(t)
+	config := filepath.Join(project, "config", "a.com")
+	if err := d.Enable("a.com", project); err == nil {
+		t.Error("配置文件不存在时应该返回错误")
+	}
+
+	os.WriteFile(config, []byte("server { listen 80; }\n"), 0644)
+	os.WriteFile(config+".htpasswd", []byte("alice:$apr1$x$y\n"), 0600)
+	os.WriteFile(filepath.Join(project, "config", "brotli.conf"), []byte("brotli on;\n"), 0644)
+	os.MkdirAll(filepath.Join(d.Root, "modules-enabled"), 0755)
+	os.WriteFile(filepath.Join(d.Root, "modules-enabled", "50-mod-http-brotli.conf"), nil, 0644)
+	if err := d.Enable("a.com", project); err != nil {
+		t.Fatalf("启用站点失败: %v", err)
+	}
+
+	if target, err := os.Readlink(d.SiteLink("a.com")); err != nil || target != config {
+		t.Errorf("sites-enabled应该链接到 %s, 实际得到 %s, %v", config, target, err)
+	}
+	htpasswd := filepath.Join(d.Root, "htpasswd", "a.com")
+	if content, _ := os.ReadFile(htpasswd); string(content) != "alice:$apr1$x$y\n" {
+		t.Errorf("htpasswd文件内容错误: %q", content)
+	}
+	if info, err := os.Stat(htpasswd); err != nil || info.Mode().Perm() != 0640 && info.Mode().Perm() != 0644 {
+		t.Errorf("htpasswd文件权限应该是0640（没有nginx所在的组时为0644）: %v", err)
+	}
+	if _, err := os.Readlink(filepath.Join(d.Root, "snippets", "aigo-brotli.conf")); err != nil {
+		t.Errorf("应该链接brotli配置片段: %v", err)
+	}
+	if calls, _ := os.ReadFile(log); string(calls) != "-t\n-s reload\n" {
+		t.Errorf("应该先检查再重新加载, 实际调用:\n%s", calls)
+	}
+}
+
+// TestDeployerEnableRoot 测试配置引用的htpasswd文件不在Root下时，修改文件之前返回错误
+func TestDeployerEnableRoot(t *testing.T) {
+	d, project, log := deployFixture(t)
+	config := filepath.Join(project, "config", "a.com")
+	os.WriteFile(config+".htpasswd", []byte("alice:$apr1$x$y\n"), 0600)
+
+	os.WriteFile(config, []byte("server {\n    location / {\n        auth_basic_user_file /etc/nginx/htpasswd/a.com;\n    }\n}\n"), 0644)
+	err := d.Enable("a.com", project)
+	if err == nil || !strings.Contains(err.Error(), "--nginx-root "+d.Root) {
+		t.Fatalf("htpasswd路径与Root不同时应该提示重新生成配置, 实际得到 %v", err)
+	}
+	if _, err := os.Lstat(d.SiteLink("a.com")); !os.IsNotExist(err) {
+		t.Errorf("返回错误时不应该链接站点: %v", err)
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Errorf("返回错误时不应该调用nginx: %v", err)
+	}
+
+	os.WriteFile(config, []byte("server {\n    location / {\n        auth_basic_user_file "+filepath.Join(d.Root, "htpasswd", "a.com")+";\n    }\n}\n"), 0644)
+	if err := d.Enable("a.com", project); err != nil {
+		t.Fatalf("htpasswd位于Root下时应该启用成功: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(d.Root, "htpasswd", "a.com")); err != nil {
+		t.Errorf("应该安装htpasswd文件: %v", err)
+	}
+}
+
+// TestDeployerRollback 测试检查失败时恢复原有的软链接和文件
+func TestDeployerRollback(t *testing.T) {
+	d, project, log := deployFixture(t)
+	config := filepath.Join(project, "config", "a.com")
+	os.WriteFile(config, []byte("server { invalid; }\n"), 0644)
+	os.WriteFile(config+".htpasswd", []byte("alice:new\n"), 0600)
+
+	// 原有的站点链接到旧配置，htpasswd是旧文件
+	old := filepath.Join(t.TempDir(), "old.conf")
+	os.WriteFile(old, []byte("server { listen 80; }\n"), 0644)
+	os.MkdirAll(filepath.Join(d.Root, "sites-enabled"), 0755)
+	os.Symlink(old, d.SiteLink("a.com"))
+	htpasswd := filepath.Join(d.Root, "htpasswd", "a.com")
+	os.MkdirAll(filepath.Dir(htpasswd), 0755)
+	os.WriteFile(htpasswd, []byte("alice:old\n"), 0640)
+
+	err := d.Enable("a.com", project)
+	if err == nil || !strings.Contains(err.Error(), "unknown directive") || !strings.Contains(err.Error(), "已恢复原有配置") {
+		t.Fatalf("检查失败时应该返回nginx的输出并恢复, 实际得到: %v", err)
+	}
+	if target, _ := os.Readlink(d.SiteLink("a.com")); target != old {
+		t.Errorf("应该恢复原有的软链接, 实际指向 %s", target)
+	}
+	if content, _ := os.ReadFile(htpasswd); string(content) != "alice:old\n" {
+		t.Errorf("应该恢复原有的htpasswd文件, 实际得到 %q", content)
+	}
+	if calls, _ := os.ReadFile(log); strings.Contains(string(calls), "reload") {
+		t.Errorf("检查失败时不应该重新加载:\n%s", calls)
+	}
+
+	// 备份目录保留被替换的软链接和文件
+	if target, _ := os.Readlink(filepath.Join(d.BackupDir, "sites-enabled", "a.com")); target != old {
+		t.Errorf("应该备份原有的软链接, 实际指向 %s", target)
+	}
+	if content, _ := os.ReadFile(filepath.Join(d.BackupDir, "htpasswd", "a.com")); string(content) != "alice:old\n" {
+		t.Errorf("应该备份原有的htpasswd文件, 实际得到 %q", content)
+	}
+
+	// 原来没有启用的站点检查失败后不留下软链接
+	os.Remove(d.SiteLink("a.com"))
+	if err := d.Enable("a.com", project); err == nil {
+		t.Fatal("检查失败时应该返回错误")
+	}
+	if _, err := os.Lstat(d.SiteLink("a.com")); !os.IsNotExist(err) {
+		t.Errorf("应该删除新建的软链接: %v", err)
+	}
+}
+
+// TestDeployerDisable 测试禁用站点，检查失败时恢复
+func TestDeployerDisable(t *testing.T) {
+	d, project, log := deployFixture(t)
+	if err := d.Disable("a.com"); err == nil {
+		t.Error("未启用的站点应该返回错误")
+	}
+
+	config := filepath.Join(project, "config", "a.com")
+	os.WriteFile(config, []byte("server { listen 80; }\n"), 0644)
+	if err := d.Enable("a.com", project); err != nil {
+		t.Fatalf("启用站点失败: %v", err)
+	}
+	if err := d.Disable("a.com"); err != nil {
+		t.Fatalf("禁用站点失败: %v", err)
+	}
+	if _, err := os.Lstat(d.SiteLink("a.com")); !os.IsNotExist(err) {
+		t.Errorf("应该删除sites-enabled中的软链接: %v", err)
+	}
+	if calls, _ := os.ReadFile(log); string(calls) != "-t\n-s reload\n-t\n-s reload\n" {
+		t.Errorf("启用和禁用都应该检查并重新加载, 实际调用:\n%s", calls)
+	}
+
+	// 删除后检查仍然失败（其他站点有错误）时恢复
+	os.Symlink(config, d.SiteLink("a.com"))
+	os.WriteFile(filepath.Join(d.Root, "sites-enabled", "b.com"), []byte("invalid;\n"), 0644)
+	if err := d.Disable("a.com"); err == nil {
+		t.Fatal("检查失败时应该返回错误")
+	}
+	if target, _ := os.Readlink(d.SiteLink("a.com")); target != config {
+		t.Errorf("应该恢复被删除的软链接, 实际指向 %q", target)
+	}
+}
@@ -0,0 +1,211 @@
+package nginx
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// htpasswd支持的密码哈希算法
+const (
+	HashAPR1   = "apr1"   // Apache MD5，nginx在所有平台上都能校验
+	HashBcrypt = "bcrypt" // 更安全，但需要系统crypt()支持 $2y$（如libxcrypt、musl）
+)
+
+// HashMethods 支持的密码哈希算法
+var HashMethods = []string{HashAPR1, HashBcrypt}
+
+// itoa64 crypt使用的base64字母表，也用于生成盐和随机密码
+const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// HtpasswdEntry htpasswd文件中的一个用户
+type HtpasswdEntry struct {
+	User string
+	Hash string
+}
+
+// Htpasswd auth_basic_user_file 使用的密码文件，保持用户在文件中的顺序
+type Htpasswd struct {
+	Entries []HtpasswdEntry
+}
+
+// ParseHtpasswd 解析htpasswd文件，忽略空行和注释
+func ParseHtpasswd(data []byte) (*Htpasswd, error) {
+	h := &Htpasswd{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("htpasswd第%d行格式应为 user:hash", i+1)
+		}
+		h.Entries = append(h.Entries, HtpasswdEntry{User: user, Hash: hash})
+	}
+	return h, nil
+}
+
+// Set 添加用户或修改已有用户的密码
+func (h *Htpasswd) Set(user, password, method string) error {
+	if err := ValidateUser(user); err != nil {
+		return err
+	}
+	hash, err := HashPassword(password, method)
+	if err != nil {
+		return err
+	}
+	for i := range h.Entries {
+		if h.Entries[i].User == user {
+			h.Entries[i].Hash = hash
+			return nil
+		}
+	}
+	h.Entries = append(h.Entries, HtpasswdEntry{User: user, Hash: hash})
+	return nil
+}
+
+// Users 返回文件中的全部用户名
+func (h *Htpasswd) Users() []string {
+	users := make([]string, 0, len(h.Entries))
+	for _, e := range h.Entries {
+		users = append(users, e.User)
+	}
+	return users
+}
+
+// Bytes 返回htpasswd文件内容
+func (h *Htpasswd) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, e := range h.Entries {
+		fmt.Fprintf(&buf, "%s:%s\n", e.User, e.Hash)
+	}
+	return buf.Bytes()
+}
+
+// ValidateUser 检查basic auth用户名
+func ValidateUser(user string) error {
+	if user == "" || strings.ContainsAny(user, ": \t\r\n#") {
+		return fmt.Errorf("用户名不能为空，也不能包含冒号、空白或 #: %q", user)
+	}
+	return nil
+}
+
+// ValidateHash 检查密码哈希算法
+func ValidateHash(method string) error {
+	for _, m := range HashMethods {
+		if m == method {
+			return nil
+		}
+	}
+	return fmt.Errorf("未知的密码哈希算法: %s，可选 %s", method, strings.Join(HashMethods, "、"))
+}
+
+// HashPassword 使用指定算法计算htpasswd中的密码哈希
+func HashPassword(password, method string) (string, error) {
+	switch method {
+	case HashAPR1, "":
+		salt, err := randomString(8)
+		if err != nil {
+			return "", err
+		}
+		return apr1(password, salt), nil
+	case HashBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", fmt.Errorf("计算bcrypt哈希失败: %v", err)
+		}
+		return string(hash), nil
+	}
+	return "", ValidateHash(method)
+}
+
+// GeneratePassword 生成随机密码，用于未指定密码的用户
+func GeneratePassword() (string, error) {
+	return randomString(16)
+}
+
+// randomString 从itoa64中随机选取字符，跳过 . 和 / 便于复制
+func randomString(n int) (string, error) {
+	letters := itoa64[2:]
+	b := make([]byte, n)
+	for i := range b {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
+		if err != nil {
+			return "", fmt.Errorf("生成随机数失败: %v", err)
+		}
+		b[i] = letters[j.Int64()]
+	}
+	return string(b), nil
+}
+
+// apr1 计算Apache的 $apr1$ MD5密码哈希，算法与htpasswd -m相同
+func apr1(password, salt string) string {
+	const magic = "$apr1$"
+	pw, s := []byte(password), []byte(salt)
+
+	alt := md5.New()
+	alt.Write(pw)
+	alt.Write(s)
+	alt.Write(pw)
+	altSum := alt.Sum(nil)
+
+	d := md5.New()
+	d.Write(pw)
+	d.Write([]byte(magic))
+	d.Write(s)
+	for i := len(pw); i > 0; i -= 16 {
+		d.Write(altSum[:min(i, 16)])
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			d.Write([]byte{0})
+		} else {
+			d.Write(pw[:1])
+		}
+	}
+	sum := d.Sum(nil)
+
+	// 1000轮迭代减慢暴力破解
+	for i := 0; i < 1000; i++ {
+		r := md5.New()
+		if i&1 != 0 {
+			r.Write(pw)
+		} else {
+			r.Write(sum)
+		}
+		if i%3 != 0 {
+			r.Write(s)
+		}
+		if i%7 != 0 {
+			r.Write(pw)
+		}
+		if i&1 != 0 {
+			r.Write(sum)
+		} else {
+			r.Write(pw)
+		}
+		sum = r.Sum(nil)
+	}
+
+	var out []byte
+	encode := func(a, b, c byte, n int) {
+		v := uint(a)<<16 | uint(b)<<8 | uint(c)
+		for ; n > 0; n-- {
+			out = append(out, itoa64[v&0x3f])
+			v >>= 6
+		}
+	}
+	encode(sum[0], sum[6], sum[12], 4)
+	encode(sum[1], sum[7], sum[13], 4)
+	encode(sum[2], sum[8], sum[14], 4)
+	encode(sum[3], sum[9], sum[15], 4)
+	encode(sum[4], sum[10], sum[5], 4)
+	encode(0, 0, sum[11], 2)
+	return magic + salt + "$" + string(out)
+}
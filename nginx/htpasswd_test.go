@@ -0,0 +1,83 @@
+package nginx
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestAPR1 测试apr1哈希与htpasswd -m、openssl passwd -apr1的结果一致
+func TestAPR1(t *testing.T) {
+	cases := []struct {
+		password string
+		salt     string
+		want     string
+	}{
+		{"secret", "abcdefgh", "$apr1$abcdefgh$h9FWgUz3n9YxylKLlR5SQ/"},
+		{"a much longer password 123", "12345678", "$apr1$12345678$qPghyINJ.9kZgNkINYA/H/"},
+	}
+	for _, c := range cases {
+		if got := apr1(c.password, c.salt); got != c.want {
+			t.Errorf("apr1(%q, %q) = %s, 期望 %s", c.password, c.salt, got, c.want)
+		}
+	}
+}
+
+// TestHashPassword 测试两种哈希算法
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("secret", HashAPR1)
+	if err != nil || !strings.HasPrefix(hash, "$apr1$") {
+		t.Fatalf("apr1哈希错误: %s, %v", hash, err)
+	}
+	if salt := strings.Split(hash, "$")[2]; apr1("secret", salt) != hash {
+		t.Errorf("apr1哈希无法用相同的盐复现: %s", hash)
+	}
+
+	hash, err = HashPassword("secret", HashBcrypt)
+	if err != nil {
+		t.Fatalf("bcrypt哈希失败: %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("secret")); err != nil {
+		t.Errorf("bcrypt哈希无法校验: %v", err)
+	}
+
+	if _, err := HashPassword("secret", "md5"); err == nil {
+		t.Error("未知的哈希算法应该返回错误")
+	}
+}
+
+// TestHtpasswd 测试解析和修改htpasswd文件
+func TestHtpasswd(t *testing.T) {
+	h, err := ParseHtpasswd([]byte("# 注释\nalice:$apr1$abcdefgh$h9FWgUz3n9YxylKLlR5SQ/\n\ncarol:{SHA}abc\n"))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if err := h.Set("alice", "new", HashAPR1); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Set("bob", "pw", HashAPR1); err != nil {
+		t.Fatal(err)
+	}
+	if users := strings.Join(h.Users(), ","); users != "alice,carol,bob" {
+		t.Errorf("用户顺序错误: %s", users)
+	}
+	content := string(h.Bytes())
+	if strings.Contains(content, "h9FWgUz3n9YxylKLlR5SQ/") || !strings.Contains(content, "carol:{SHA}abc\n") {
+		t.Errorf("应该只修改指定用户的密码:\n%s", content)
+	}
+
+	if _, err := ParseHtpasswd([]byte("alice\n")); err == nil {
+		t.Error("缺少哈希的行应该返回错误")
+	}
+	for _, user := range []string{"", "a:b", "a b"} {
+		if err := h.Set(user, "pw", HashAPR1); err == nil {
+			t.Errorf("用户名 %q 应该返回错误", user)
+		}
+	}
+
+	password, err := GeneratePassword()
+	if err != nil || len(password) != 16 || strings.ContainsAny(password, "./") {
+		t.Errorf("随机密码错误: %q, %v", password, err)
+	}
+}
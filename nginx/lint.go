@@ -0,0 +1,413 @@
+package nginx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Severity 检查结果的严重程度
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue 一条检查结果
+type Issue struct {
+	File     string
+	Line     int
+	Severity Severity
+	Message  string
+}
+
+// String 以 file:line: severity: message 形式返回检查结果
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Severity, i.Message)
+}
+
+// HasErrors 判断检查结果中是否有错误级别的问题
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// socketOptions 同一地址只能在一个server中设置的listen参数
+var socketOptions = []string{
+	"backlog", "rcvbuf", "sndbuf", "reuseport", "ipv6only", "deferred",
+	"bind", "fastopen", "so_keepalive", "accept_filter", "setfib",
+}
+
+// listen 规范化后的listen指令
+type listen struct {
+	addr       string
+	ssl        bool
+	isDefault  bool
+	socketOpts bool
+	directive  *Directive
+}
+
+// server 检查使用的server块信息
+type server struct {
+	directive *Directive
+	listens   []listen
+	names     []*Directive
+}
+
+// linter 在一组配置文件上执行检查
+type linter struct {
+	issues    []Issue
+	servers   []*server
+	upstreams map[string]*Directive
+}
+
+// Lint 检查一组配置，这些配置视为同一个nginx实例加载的内容
+func Lint(configs ...*Config) []Issue {
+	l := &linter{upstreams: map[string]*Directive{}}
+	for _, config := range configs {
+		l.collect(config)
+	}
+	l.checkListen()
+	l.checkServerNames()
+	for _, config := range configs {
+		l.checkProxyPass(config)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].File != l.issues[j].File {
+			return l.issues[i].File < l.issues[j].File
+		}
+		return l.issues[i].Line < l.issues[j].Line
+	})
+	return l.issues
+}
+
+// report 记录一条检查结果
+func (l *linter) report(d *Directive, severity Severity, format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{
+		File:     d.File,
+		Line:     d.Line,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// collect 收集http上下文中的server块和upstream块
+func (l *linter) collect(config *Config) {
+	config.Walk(func(d *Directive, parents []*Directive) bool {
+		if !d.IsBlock() {
+			return true
+		}
+		switch d.Name {
+		case "stream", "mail":
+			return false
+		case "upstream":
+			if len(d.Find("server")) == 0 {
+				l.report(d, SeverityError, "upstream %s 没有配置 server", d.Arg(0))
+			}
+			l.upstreams[d.Arg(0)] = d
+			return false
+		case "server":
+			l.servers = append(l.servers, newServer(d))
+			return true
+		}
+		return true
+	})
+}
+
+// newServer 解析server块中的listen和server_name
+func newServer(d *Directive) *server {
+	s := &server{directive: d, names: d.Find("server_name")}
+	for _, ld := range d.Find("listen") {
+		if len(ld.Args) == 0 {
+			continue
+		}
+		ls := listen{addr: normalizeListen(ld.Args[0]), directive: ld}
+		for _, opt := range ld.Args[1:] {
+			name, _, _ := strings.Cut(opt, "=")
+			switch name {
+			case "ssl":
+				ls.ssl = true
+			case "default_server", "default":
+				ls.isDefault = true
+			}
+			for _, socketOpt := range socketOptions {
+				if name == socketOpt {
+					ls.socketOpts = true
+				}
+			}
+		}
+		s.listens = append(s.listens, ls)
+	}
+	return s
+}
+
+// addrs 返回server监听的地址，没有listen指令时为nginx默认的 *:80
+func (s *server) addrs() []string {
+	if len(s.listens) == 0 {
+		return []string{"*:80"}
+	}
+	addrs := make([]string, len(s.listens))
+	for i, ls := range s.listens {
+		addrs[i] = ls.addr
+	}
+	return addrs
+}
+
+// normalizeListen 将listen地址规范化为 host:port 形式
+func normalizeListen(addr string) string {
+	if strings.HasPrefix(addr, "unix:") {
+		return addr
+	}
+	if strings.HasPrefix(addr, "[") {
+		if strings.Contains(addr, "]:") {
+			return addr
+		}
+		return addr + ":80"
+	}
+	if isDigits(addr) {
+		return "*:" + addr
+	}
+	host, port, ok := strings.Cut(addr, ":")
+	if !ok {
+		port = "80"
+	}
+	if host == "0.0.0.0" {
+		host = "*"
+	}
+	return host + ":" + port
+}
+
+// isDigits 判断字符串是否全部由数字组成
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkListen 检查重复的listen、重复的default_server、ssl不一致和重复的socket选项
+func (l *linter) checkListen() {
+	defaults := map[string]*Directive{}
+	sockets := map[string]*Directive{}
+	ssl := map[string]listen{}
+
+	for _, s := range l.servers {
+		seen := map[string]bool{}
+		for _, ls := range s.listens {
+			if seen[ls.addr] {
+				l.report(ls.directive, SeverityError, "同一server中重复的 listen %s", ls.addr)
+				continue
+			}
+			seen[ls.addr] = true
+
+			if ls.isDefault {
+				if first, ok := defaults[ls.addr]; ok {
+					l.report(ls.directive, SeverityError, "listen %s 重复设置 default_server（首次设置于 %s）", ls.addr, first.Position())
+				} else {
+					defaults[ls.addr] = ls.directive
+				}
+			}
+			if ls.socketOpts {
+				if first, ok := sockets[ls.addr]; ok {
+					l.report(ls.directive, SeverityError, "listen %s 的socket选项重复设置（首次设置于 %s）", ls.addr, first.Position())
+				} else {
+					sockets[ls.addr] = ls.directive
+				}
+			}
+			if first, ok := ssl[ls.addr]; !ok {
+				ssl[ls.addr] = ls
+			} else if first.ssl != ls.ssl {
+				l.report(ls.directive, SeverityError, "listen %s 的ssl设置与 %s 冲突，同一地址上的server需要同时启用或同时关闭ssl", ls.addr, first.directive.Position())
+			}
+		}
+	}
+}
+
+// checkServerNames 检查同一监听地址上重复的server_name，nginx只会使用第一个server块
+func (l *linter) checkServerNames() {
+	first := map[string]*Directive{}
+	for _, s := range l.servers {
+		seen := map[string]bool{}
+		reported := map[string]bool{}
+		for _, addr := range s.addrs() {
+			for _, nd := range s.names {
+				for _, name := range nd.Args {
+					key := addr + " " + strings.ToLower(name)
+					if name == "" || seen[key] {
+						continue
+					}
+					seen[key] = true
+					prev, ok := first[key]
+					if !ok {
+						first[key] = nd
+						continue
+					}
+					// 同一个名称在多个地址上重复时只报告一次
+					if !reported[name] {
+						reported[name] = true
+						l.report(nd, SeverityWarning, "server_name %s 在 %s 上重复定义（首次定义于 %s），该server块将被忽略", name, addr, prev.Position())
+					}
+				}
+			}
+		}
+	}
+}
+
+// checkProxyPass 检查proxy_pass的代理目标
+func (l *linter) checkProxyPass(config *Config) {
+	config.Walk(func(d *Directive, _ []*Directive) bool {
+		if d.Name != "proxy_pass" {
+			return true
+		}
+		if len(d.Args) != 1 || d.Args[0] == "" {
+			l.report(d, SeverityError, "proxy_pass 缺少代理目标")
+			return true
+		}
+
+		target := d.Args[0]
+		scheme, rest, ok := strings.Cut(target, "://")
+		if !ok || (scheme != "http" && scheme != "https") {
+			if !strings.HasPrefix(target, "$") {
+				l.report(d, SeverityError, "proxy_pass 目标 %s 缺少 http:// 或 https:// 前缀", target)
+			}
+			return true
+		}
+		host, _, _ := strings.Cut(rest, "/")
+		switch {
+		case host == "":
+			l.report(d, SeverityError, "proxy_pass 目标 %s 缺少主机", target)
+		case strings.Contains(host, "$"), strings.HasPrefix(host, "unix:"), strings.HasPrefix(host, "["):
+		case strings.Contains(host, ":"):
+			if _, port, _ := strings.Cut(host, ":"); !isDigits(port) {
+				l.report(d, SeverityError, "proxy_pass 目标 %s 的端口无效", target)
+			}
+		case host != "localhost" && !strings.Contains(host, "."):
+			// 不带端口和域名后缀的主机通常是upstream名称，也可能是容器服务等主机名，未定义时只警告
+			if _, ok := l.upstreams[host]; !ok {
+				l.report(d, SeverityWarning, "proxy_pass 引用的 upstream %s 未定义，将按主机名解析", host)
+			}
+		}
+		return true
+	})
+}
+
+// LintPaths 解析并检查文件或目录中的全部配置文件；目录中的隐藏文件和以 #! 开头的脚本被跳过，
+// 被其他文件include的文件只检查一次，语法错误作为检查结果返回
+func LintPaths(paths ...string) ([]Issue, error) {
+	files, err := configFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	var configs []*Config
+	for _, file := range files {
+		config, err := ParseFile(file)
+		if err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				issues = append(issues, Issue{File: parseErr.File, Line: parseErr.Line, Severity: SeverityError, Message: parseErr.Message})
+				continue
+			}
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return append(issues, Lint(topLevel(configs)...)...), nil
+}
+
+// otherConfigs 目录中不是nginx配置的文件扩展名: htpasswd密码文件、Caddyfile和Traefik动态配置
+var otherConfigs = map[string]bool{".htpasswd": true, ".caddy": true, ".yml": true, ".yaml": true}
+
+// configFiles 展开目录，返回需要检查的配置文件
+func configFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if file != path && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// 目录、密码文件、其他反向代理的配置和脚本不是nginx配置
+			if d.IsDir() || otherConfigs[filepath.Ext(file)] {
+				return nil
+			}
+			script, err := isScript(file)
+			if err != nil || script {
+				return err
+			}
+			files = append(files, file)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// isScript 判断文件是否以 #! 开头
+func isScript(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 2)
+	n, _ := f.Read(head)
+	return bytes.Equal(head[:n], []byte("#!")), nil
+}
+
+// topLevel 去掉被其他配置include的配置，避免重复检查
+func topLevel(configs []*Config) []*Config {
+	included := map[string]bool{}
+	for _, config := range configs {
+		config.Walk(func(d *Directive, _ []*Directive) bool {
+			for _, inc := range d.Includes {
+				if abs, err := filepath.Abs(inc.File); err == nil {
+					included[abs] = true
+				}
+			}
+			return true
+		})
+	}
+
+	var result []*Config
+	for _, config := range configs {
+		if abs, err := filepath.Abs(config.File); err != nil || !included[abs] {
+			result = append(result, config)
+		}
+	}
+	return result
+}
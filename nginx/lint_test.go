@@ -0,0 +1,125 @@
+package nginx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lint 解析并检查配置内容
+func lint(t *testing.T, src string) []Issue {
+	t.Helper()
+	config, err := Parse("site.conf", []byte(src))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	return Lint(config)
+}
+
+// TestLint 测试各项检查规则
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name    string
+		src     string
+		line    int
+		message string
+	}{
+		{"重复server_name", "server { listen 80; server_name a.com; }\nserver { listen 80; server_name b.com A.com; }\n", 2, "server_name A.com 在 *:80 上重复定义"},
+		{"默认端口的重复server_name", "server { server_name a.com; }\nserver {\n    server_name a.com;\n}\n", 3, "server_name a.com 在 *:80 上重复定义"},
+		{"缺少代理目标", "server { location / {\n    proxy_pass;\n} }\n", 2, "proxy_pass 缺少代理目标"},
+		{"缺少协议", "server { location / { proxy_pass localhost:8080; } }\n", 1, "缺少 http:// 或 https:// 前缀"},
+		{"未定义upstream", "server { location / { proxy_pass http://backend; } }\n", 1, "upstream backend 未定义"},
+		{"无效端口", "server { location / { proxy_pass http://localhost:port; } }\n", 1, "端口无效"},
+		{"空upstream", "upstream backend {}\n", 1, "upstream backend 没有配置 server"},
+		{"同一server重复listen", "server {\n    listen 80;\n    listen 0.0.0.0:80;\n}\n", 3, "重复的 listen *:80"},
+		{"重复default_server", "server { listen 80 default_server; }\nserver { listen *:80 default_server; server_name b.com; }\n", 2, "重复设置 default_server"},
+		{"ssl冲突", "server { listen 443 ssl; server_name a.com; }\nserver { listen 443; server_name b.com; }\n", 2, "ssl设置与 site.conf:1 冲突"},
+		{"socket选项重复", "server { listen 80 reuseport; server_name a.com; }\nserver { listen 80 backlog=511; server_name b.com; }\n", 2, "socket选项重复设置"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			issues := lint(t, c.src)
+			if len(issues) != 1 {
+				t.Fatalf("期望1条检查结果, 实际得到 %v", issues)
+			}
+			if issues[0].Line != c.line || !strings.Contains(issues[0].Message, c.message) {
+				t.Errorf("期望第%d行 %q, 实际得到 %s", c.line, c.message, issues[0])
+			}
+		})
+	}
+}
+
+// TestLintValid 测试正确的配置没有检查结果
+func TestLintValid(t *testing.T) {
+	src := `upstream backend {
+    server 127.0.0.1:8001;
+    server 127.0.0.1:8002;
+}
+server {
+    listen 80;
+    listen [::]:80;
+    server_name example.com;
+    return 301 https://$host$request_uri;
+}
+server {
+    listen 443 ssl;
+    listen [::]:443 ssl;
+    server_name example.com;
+    location / { proxy_pass http://backend; }
+    location /api/ { proxy_pass http://localhost:8080/; }
+    location /svc/ { proxy_pass http://api.internal; }
+    location /dyn/ { proxy_pass $target; }
+    location /host/ { proxy_pass http://backend-host:8080; }
+}
+stream {
+    server { listen 80; }
+}
+`
+	if issues := lint(t, src); len(issues) != 0 {
+		t.Errorf("正确的配置不应该有检查结果: %v", issues)
+	}
+}
+
+// TestLintProxyPassHostname 测试未定义为upstream的主机名只警告，带端口的主机名不检查upstream
+func TestLintProxyPassHostname(t *testing.T) {
+	issues := lint(t, "server { location / { proxy_pass http://backend; } }\n")
+	if len(issues) != 1 || issues[0].Severity != SeverityWarning || HasErrors(issues) {
+		t.Errorf("未定义的upstream应该是警告, 实际得到 %v", issues)
+	}
+	if issues := lint(t, "server { location / { proxy_pass http://backend-host:8080; } }\n"); len(issues) != 0 {
+		t.Errorf("带端口的主机名不应该有检查结果: %v", issues)
+	}
+}
+
+// TestLintPaths 测试检查目录: 跳过脚本和隐藏文件，语法错误作为检查结果，被include的文件只检查一次
+func TestLintPaths(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "setup-nginx.sh"), []byte("#!/bin/bash\nif [ -z \"$1\" ]; then\n"), 0755)
+	os.WriteFile(filepath.Join(dir, ".hidden"), []byte("}"), 0644)
+	os.WriteFile(filepath.Join(dir, "nginx.conf"), []byte("http { include a.example.com; }\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "a.example.com"), []byte("server { listen 80; server_name a.example.com; }\n"), 0644)
+
+	issues, err := LintPaths(dir)
+	if err != nil {
+		t.Fatalf("检查失败: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("不应该有检查结果: %v", issues)
+	}
+
+	broken := filepath.Join(dir, "b.example.com")
+	os.WriteFile(broken, []byte("server {\n    listen 80;\n"), 0644)
+	issues, err = LintPaths(dir)
+	if err != nil {
+		t.Fatalf("检查失败: %v", err)
+	}
+	if len(issues) != 1 || issues[0].File != broken || issues[0].Line != 1 || !HasErrors(issues) {
+		t.Errorf("缺少闭合括号应该作为错误返回, 实际得到 %v", issues)
+	}
+
+	if _, err := LintPaths(filepath.Join(dir, "missing")); err == nil {
+		t.Error("路径不存在时应该返回错误")
+	}
+}
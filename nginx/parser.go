@@ -0,0 +1,290 @@
+package nginx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth include的最大嵌套层数
+const maxIncludeDepth = 16
+
+// ParseError 语法错误，包含出错的文件和行号
+type ParseError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// Error 实现error接口
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenOpen      // {
+	tokenClose     // }
+	tokenSemicolon // ;
+)
+
+// token 词法单元
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// describe 返回错误信息中使用的词法单元描述
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "文件结尾"
+	case tokenOpen:
+		return `"{"`
+	case tokenClose:
+		return `"}"`
+	case tokenSemicolon:
+		return `";"`
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lexer nginx配置词法分析器
+type lexer struct {
+	file string
+	src  []byte
+	pos  int
+	line int
+}
+
+// next 返回下一个词法单元，注释和空白被跳过
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case c == '{':
+			l.pos++
+			return token{tokenOpen, "{", l.line}, nil
+		case c == '}':
+			l.pos++
+			return token{tokenClose, "}", l.line}, nil
+		case c == ';':
+			l.pos++
+			return token{tokenSemicolon, ";", l.line}, nil
+		case c == '"' || c == '\'':
+			return l.quoted(c)
+		default:
+			return l.word(), nil
+		}
+	}
+	return token{tokenEOF, "", l.line}, nil
+}
+
+// quoted 读取引号包围的参数，支持反斜杠转义引号和反斜杠
+func (l *lexer) quoted(quote byte) (token, error) {
+	start := l.line
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == quote:
+			l.pos++
+			return token{tokenWord, b.String(), start}, nil
+		case c == '\\' && l.pos+1 < len(l.src) && (l.src[l.pos+1] == quote || l.src[l.pos+1] == '\\'):
+			b.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+			continue
+		case c == '\n':
+			l.line++
+		}
+		b.WriteByte(c)
+		l.pos++
+	}
+	return token{}, &ParseError{File: l.file, Line: start, Message: "引号未闭合"}
+}
+
+// word 读取普通参数，${var} 形式的变量中的花括号属于参数本身
+func (l *lexer) word() token {
+	start := l.pos
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case ' ', '\t', '\r', '\n', ';', '{', '}':
+			if c == '{' && l.pos > start && l.src[l.pos-1] == '$' {
+				if end := strings.IndexByte(string(l.src[l.pos:]), '}'); end > 0 {
+					l.pos += end + 1
+					continue
+				}
+			}
+			return token{tokenWord, string(l.src[start:l.pos]), l.line}
+		case '\\':
+			l.pos++
+		}
+		l.pos++
+	}
+	if l.pos > len(l.src) {
+		l.pos = len(l.src)
+	}
+	return token{tokenWord, string(l.src[start:l.pos]), l.line}
+}
+
+// parser nginx配置语法分析器
+type parser struct {
+	lexer
+}
+
+// Parse 解析配置内容，name用于错误信息和指令位置，include指令不展开
+func Parse(name string, src []byte) (*Config, error) {
+	p := &parser{lexer{file: name, src: src, line: 1}}
+	directives, err := p.block(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{File: name, Directives: directives}, nil
+}
+
+// block 解析指令列表直到遇到 "}"（parent不为nil时）或文件结尾
+func (p *parser) block(parent *Directive) ([]*Directive, error) {
+	directives := []*Directive{}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.kind {
+		case tokenEOF:
+			if parent != nil {
+				return nil, p.errorf(parent.Line, `%s 块缺少闭合的 "}"`, parent.Name)
+			}
+			return directives, nil
+		case tokenClose:
+			if parent == nil {
+				return nil, p.errorf(tok.line, `多余的 "}"`)
+			}
+			return directives, nil
+		case tokenOpen, tokenSemicolon:
+			return nil, p.errorf(tok.line, "意外的 %s", tok.describe())
+		}
+
+		d, err := p.directive(tok)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, d)
+	}
+}
+
+// directive 解析一条指令的参数和块
+func (p *parser) directive(name token) (*Directive, error) {
+	d := &Directive{Name: name.text, Args: []string{}, File: p.file, Line: name.line}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.kind {
+		case tokenWord:
+			d.Args = append(d.Args, tok.text)
+		case tokenSemicolon:
+			return d, nil
+		case tokenOpen:
+			block, err := p.block(d)
+			if err != nil {
+				return nil, err
+			}
+			d.Block = block
+			return d, nil
+		default:
+			return nil, p.errorf(d.Line, `指令 %s 缺少 ";"，遇到 %s`, d.Name, tok.describe())
+		}
+	}
+}
+
+// errorf 创建当前文件的语法错误
+func (p *parser) errorf(line int, format string, args ...interface{}) error {
+	return &ParseError{File: p.file, Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+// ParseFile 解析配置文件并展开include指令，相对路径相对于当前文件所在目录；
+// 匹配不到任何文件的include被忽略（如只存在于服务器上的 /etc/nginx/mime.types）
+func ParseFile(path string) (*Config, error) {
+	return parseFile(path, nil)
+}
+
+// parseFile 解析配置文件，stack为正在解析的include链，用于检测循环引用
+func parseFile(path string, stack []string) (*Config, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, seen := range stack {
+		if seen == abs {
+			return nil, fmt.Errorf("%s: 循环include", path)
+		}
+	}
+	if len(stack) >= maxIncludeDepth {
+		return nil, fmt.Errorf("%s: include嵌套超过 %d 层", path, maxIncludeDepth)
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := Parse(path, src)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveIncludes(config.Directives, path, append(stack, abs)); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// resolveIncludes 展开指令列表（含嵌套块）中的include指令
+func resolveIncludes(directives []*Directive, path string, stack []string) error {
+	for _, d := range directives {
+		if d.IsBlock() {
+			if err := resolveIncludes(d.Block, path, stack); err != nil {
+				return err
+			}
+			continue
+		}
+		if d.Name != "include" || len(d.Args) != 1 {
+			continue
+		}
+
+		pattern := d.Args[0]
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: include路径无效: %v", d.Position(), err)
+		}
+		for _, match := range matches {
+			included, err := parseFile(match, stack)
+			if err != nil {
+				return err
+			}
+			d.Includes = append(d.Includes, included)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package nginx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParse 测试指令、参数、块和注释的解析
+func TestParse(t *testing.T) {
+	src := `# 注释
+user nginx;
+http {
+    server {
+        listen 80 default_server;
+        server_name example.com "www.example.com";  # 行尾注释
+        location ~ ^/api/(.*)$ {
+            proxy_pass http://localhost:8080/${1};
+            proxy_set_header Connection 'upgrade';
+        }
+        location / {}
+    }
+}
+`
+	config, err := Parse("test.conf", []byte(src))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(config.Directives) != 2 {
+		t.Fatalf("期望2条顶层指令, 实际得到 %d", len(config.Directives))
+	}
+
+	user := config.Directives[0]
+	if user.Name != "user" || user.Arg(0) != "nginx" || user.IsBlock() || user.Line != 2 {
+		t.Errorf("user指令解析错误: %+v", user)
+	}
+
+	servers := config.Find("server")
+	if len(servers) != 1 || !servers[0].IsBlock() {
+		t.Fatalf("应该解析出1个server块, 实际得到 %d", len(servers))
+	}
+	names := servers[0].Find("server_name")
+	if len(names) != 1 || !reflect.DeepEqual(names[0].Args, []string{"example.com", "www.example.com"}) {
+		t.Errorf("server_name参数解析错误: %v", names)
+	}
+
+	locations := servers[0].Find("location")
+	if len(locations) != 2 {
+		t.Fatalf("应该解析出2个location, 实际得到 %d", len(locations))
+	}
+	if !reflect.DeepEqual(locations[0].Args, []string{"~", "^/api/(.*)$"}) {
+		t.Errorf("location参数解析错误: %v", locations[0].Args)
+	}
+	proxy := locations[0].Find("proxy_pass")
+	if len(proxy) != 1 || proxy[0].Arg(0) != "http://localhost:8080/${1}" || proxy[0].Line != 8 {
+		t.Errorf("带${var}的参数解析错误: %+v", proxy)
+	}
+	if got := locations[0].Find("proxy_set_header")[0].Arg(1); got != "upgrade" {
+		t.Errorf("单引号参数解析错误: %q", got)
+	}
+	if !locations[1].IsBlock() || len(locations[1].Block) != 0 {
+		t.Error("空块应该被解析为不含指令的块")
+	}
+}
+
+// TestParseErrors 测试语法错误的位置和信息
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		src     string
+		line    int
+		message string
+	}{
+		{"缺少闭合括号", "http {\n    server {\n        listen 80;\n    }\n", 1, `http 块缺少闭合的 "}"`},
+		{"多余的闭合括号", "server {\n}\n}\n", 3, `多余的 "}"`},
+		{"缺少分号", "server {\n    listen 80\n}\n", 2, `指令 listen 缺少 ";"`},
+		{"文件结尾缺少分号", "user nginx", 1, `指令 user 缺少 ";"`},
+		{"意外的分号", "server {\n    ;\n}\n", 2, `意外的 ";"`},
+		{"引号未闭合", "server_name \"example.com;\n", 1, "引号未闭合"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Parse("bad.conf", []byte(c.src))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("期望ParseError, 实际得到 %v", err)
+			}
+			if parseErr.Line != c.line || !strings.Contains(parseErr.Message, c.message) {
+				t.Errorf("期望第%d行 %q, 实际得到 %v", c.line, c.message, err)
+			}
+			if !strings.HasPrefix(err.Error(), "bad.conf:") {
+				t.Errorf("错误信息应该包含文件名: %v", err)
+			}
+		})
+	}
+}
+
+// TestParseFileInclude 测试include展开、glob匹配和循环检测
+func TestParseFileInclude(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "sites"), 0755)
+	os.WriteFile(filepath.Join(dir, "nginx.conf"), []byte("http {\n    include mime.types;\n    include sites/*.conf;\n}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "sites", "a.conf"), []byte("server { server_name a.example.com; }\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "sites", "b.conf"), []byte("server { server_name b.example.com; }\n"), 0644)
+
+	config, err := ParseFile(filepath.Join(dir, "nginx.conf"))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	includes := config.Find("include")
+	if len(includes) != 2 || len(includes[0].Includes) != 0 || len(includes[1].Includes) != 2 {
+		t.Fatalf("include展开错误: %+v", includes)
+	}
+
+	// 遍历时include的内容位于http块中
+	var parents []string
+	config.Walk(func(d *Directive, p []*Directive) bool {
+		if d.Name == "server" {
+			parents = append(parents, p[len(p)-1].Name)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(parents, []string{"http", "http"}) {
+		t.Errorf("include的server块应该位于http块中, 实际父级为 %v", parents)
+	}
+
+	os.WriteFile(filepath.Join(dir, "sites", "c.conf"), []byte("include ../nginx.conf;\n"), 0644)
+	if _, err := ParseFile(filepath.Join(dir, "nginx.conf")); err == nil || !strings.Contains(err.Error(), "循环include") {
+		t.Errorf("循环include应该返回错误, 实际得到 %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/generator"
+	"github.com/yggai/aigo_hotreload/templates"
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// AddOptions 向已有项目添加组件的选项
+type AddOptions struct {
+	Dir        string   // 项目目录，为空时使用当前目录
+	Components []string // 组件名称
+	Port       string   // 服务端口，为空时使用aigo.yaml中的端口
+	Domain     string   // nginx组件使用的域名，为空时使用aigo.yaml中的主域名
+	Database   string   // db组件使用的数据库，为空时沿用go.mod中已有的驱动
+	Force      bool     // 允许覆盖被手动修改的文件
+	DryRun     bool     // 只列出将要写入的文件、权限和大小，不写入磁盘
+	Diff       bool     // 只显示与磁盘上已有文件的统一diff，不写入磁盘
+
+	Overwrite tools.OverwritePolicy // 已有文件内容不同时的处理策略，为空时备份后覆盖
+}
+
+// AddComponents 向已有项目添加组件，项目信息从go.mod推断
+func (m *Manager) AddComponents(opts AddOptions) error {
+	if len(opts.Components) == 0 {
+		return errors.New("请指定要添加的组件")
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	projectPath, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	components := make([]generator.Component, 0, len(opts.Components))
+	for _, name := range opts.Components {
+		component, err := generator.LookupComponent(name)
+		if err != nil {
+			return err
+		}
+		components = append(components, component)
+	}
+
+	data, mod, err := generator.LoadProjectData(projectPath)
+	if err != nil {
+		return err
+	}
+	if err := applyAddOptions(&data, opts, components); err != nil {
+		return err
+	}
+
+	policy := opts.Overwrite
+	if policy == "" {
+		policy = tools.PolicyBackup
+	}
+	writer, err := tools.NewProjectWriter(projectPath, tools.SelectWriteMode(opts.DryRun, opts.Diff), policy, opts.Force)
+	if err != nil {
+		return err
+	}
+	gen := generator.NewProjectGeneratorWithData(projectPath, data)
+	gen.SetWriter(writer)
+
+	for _, component := range components {
+		conflicts, err := gen.Conflicts(component, mod)
+		if err != nil {
+			return err
+		}
+		if writer.Mode() == tools.WriteDryRun {
+			m.logger.Info("[dry-run] 将添加组件 %s (%s):", component.Name, component.Description)
+		}
+		for _, conflict := range conflicts {
+			m.logger.Warning("%s: %s", component.Name, conflict)
+		}
+
+		if err := gen.GenerateComponent(component, mod); err != nil {
+			return fmt.Errorf("添加组件 %s 失败: %v", component.Name, err)
+		}
+	}
+
+	// 在项目清单中记录启用的组件
+	err = gen.UpdateProjectFile(func(p *config.Project) {
+		if opts.Port != "" {
+			p.Port = data.Port
+		}
+		for _, component := range components {
+			p.AddComponent(component.Name)
+			switch component.Name {
+			case "nginx":
+				p.AddDomain(data.Domain)
+			case "db":
+				p.Database = data.Database.Name
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.Finish(); err != nil {
+		return err
+	}
+	if writer.Preview() {
+		return nil
+	}
+
+	for _, component := range components {
+		m.logger.Success("已添加组件 %s: %s", component.Name, component.Description)
+		if component.Next == nil {
+			continue
+		}
+		for _, step := range component.Next(data) {
+			m.logger.Println("  " + step)
+		}
+	}
+	return nil
+}
+
+// applyAddOptions 把命令行选项合并到从项目推断出的模板数据中
+func applyAddOptions(data *templates.Data, opts AddOptions, components []generator.Component) error {
+	if opts.Port != "" {
+		if err := validatePort(opts.Port); err != nil {
+			return err
+		}
+		data.Port = opts.Port
+	}
+	if opts.Domain != "" {
+		if err := config.ValidateDomain(opts.Domain); err != nil {
+			return err
+		}
+		data.Domain = opts.Domain
+	}
+	if opts.Database != "" {
+		db, err := templates.LookupDatabase(opts.Database)
+		if err != nil {
+			return err
+		}
+		data.Database = &db
+	}
+
+	for _, component := range components {
+		switch component.Name {
+		case "db":
+			if data.Database == nil {
+				return errors.New("添加db组件需要使用 --db 指定数据库")
+			}
+		case "dockerfile":
+			// 同时添加的makefile、ci会生成docker相关内容
+			data.Docker = true
+		case "nginx":
+			data.Nginx = true
+		}
+	}
+	return nil
+}
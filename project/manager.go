@@ -1,90 +1,233 @@
 package project
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/yggai/aigo_hotreload/config"
 	"github.com/yggai/aigo_hotreload/generator"
+	"github.com/yggai/aigo_hotreload/templates"
 	"github.com/yggai/aigo_hotreload/tools"
 )
 
+// CreateOptions 创建项目的选项
+type CreateOptions struct {
+	Name   string
+	Dir    string // 项目所在的父目录，为空时使用当前目录
+	Force  bool   // 目录已存在时仍然生成
+	DryRun bool   // 只列出将要写入的文件、权限和大小，不写入磁盘
+	Diff   bool   // 只显示与磁盘上已有文件的统一diff，不写入磁盘
+
+	Overwrite tools.OverwritePolicy // 已有文件内容不同时的处理策略，为空时备份后覆盖
+
+	Module    string            // Go模块路径，为空时使用项目名称
+	Framework string            // HTTP框架名称，为空时使用gin
+	Port      string            // 服务端口，为空时使用默认端口
+	Domain    string            // nginx配置使用的域名，为空时使用占位域名
+	SkipNginx bool              // 不生成nginx配置与SSL脚本
+	Docker    bool              // 生成Dockerfile、.dockerignore和docker-compose.yml
+	Database  string            // 数据库名称，为空表示不使用
+	Template  string            // 自定义模板：本地目录、git仓库地址或已注册的模板名称
+	Vars      map[string]string // 命令行指定的模板变量，优先于模板默认值和交互输入
+}
+
 // Manager 项目管理器
 type Manager struct {
-	airManager *tools.AirManager
-	logger     *tools.Logger
+	logger *tools.Logger
 }
 
 // NewManager 创建新的项目管理器
 func NewManager() *Manager {
 	return &Manager{
-		airManager: tools.NewAirManager(),
-		logger:     tools.NewLogger(),
+		logger: tools.NewLogger(),
 	}
 }
 
 // CreateProject 创建新项目
-func (m *Manager) CreateProject(projectName string) {
+func (m *Manager) CreateProject(opts CreateOptions) error {
+	projectName := opts.Name
+
 	// 检查项目名称是否有效
 	if strings.TrimSpace(projectName) == "" {
-		m.logger.Error(config.Messages.Errors.EmptyName)
-		return
+		return errors.New(config.Messages.Errors.EmptyName)
 	}
 
-	// 获取当前工作目录
-	cwd, err := os.Getwd()
-	if err != nil {
-		m.logger.Error(config.Messages.Errors.GetCwd, err)
-		return
+	// 获取项目父目录
+	parent := opts.Dir
+	if parent == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf(config.Messages.Errors.GetCwd, err)
+		}
+		parent = cwd
 	}
 
-	projectPath := filepath.Join(cwd, projectName)
+	projectPath, err := filepath.Abs(filepath.Join(parent, projectName))
+	if err != nil {
+		return fmt.Errorf(config.Messages.Errors.GetCwd, err)
+	}
 
-	// 检查目录是否已存在
-	if _, err := os.Stat(projectPath); !os.IsNotExist(err) {
-		m.logger.Error(config.Messages.Errors.DirExists, projectPath)
-		return
+	data, err := newData(opts)
+	if err != nil {
+		return err
 	}
 
-	// 创建项目目录
-	if err := os.MkdirAll(projectPath, config.DirPermission); err != nil {
-		m.logger.Error(config.Messages.Errors.CreateDir, projectPath, err)
-		return
+	// 检查目录是否已存在，diff模式用于对比已有项目，不做检查
+	if _, err := os.Stat(projectPath); !os.IsNotExist(err) && !opts.Force && !opts.Diff {
+		return fmt.Errorf(config.Messages.Errors.DirExists, projectPath)
 	}
 
-	m.logger.Info(config.Messages.Success.Creating, projectName)
+	policy := opts.Overwrite
+	if policy == "" {
+		policy = tools.PolicyBackup
+	}
+	writer, err := tools.NewProjectWriter(projectPath, tools.SelectWriteMode(opts.DryRun, opts.Diff), policy, opts.Force)
+	if err != nil {
+		return err
+	}
+	switch writer.Mode() {
+	case tools.WriteDryRun:
+		m.logger.Info("[dry-run] 将在 %s 创建项目 %s (框架 %s):", projectPath, projectName, data.Framework.Title)
+	case tools.WriteFiles:
+		// 创建项目目录
+		if err := os.MkdirAll(projectPath, config.DirPermission); err != nil {
+			return fmt.Errorf(config.Messages.Errors.CreateDir, projectPath, err)
+		}
+		m.logger.Info(config.Messages.Success.Creating, projectName)
+	}
 
 	// 生成项目文件
-	gen := generator.NewProjectGenerator(projectPath, projectName)
-	if err := gen.GenerateAll(); err != nil {
-		m.logger.Error(config.Messages.Errors.GenerateFiles, err)
-		return
+	if opts.Template != "" {
+		err = m.generateCustom(projectPath, data, writer, opts)
+	} else {
+		gen := generator.NewProjectGeneratorWithData(projectPath, data)
+		gen.SetWriter(writer)
+		err = gen.GenerateAll()
+	}
+	if err != nil {
+		return fmt.Errorf(config.Messages.Errors.GenerateFiles, err)
+	}
+	if err := writer.Finish(); err != nil {
+		return err
+	}
+	if writer.Preview() {
+		return nil
 	}
 
 	m.logger.Success(config.Messages.Success.Created, projectName)
 	m.logger.PrintEmpty()
 
-	// 检查并安装 air
-	m.airManager.InstallAir()
-
 	// 显示后续步骤
-	m.showNextSteps(projectName)
+	m.showNextSteps(projectName, data)
+	return nil
+}
+
+// newData 根据创建选项生成模板数据
+func newData(opts CreateOptions) (templates.Data, error) {
+	data := templates.NewData(opts.Name)
+	if opts.Module != "" {
+		data.ModulePath = opts.Module
+	}
+	if opts.Port != "" {
+		if err := validatePort(opts.Port); err != nil {
+			return data, err
+		}
+		data.Port = opts.Port
+	}
+	if opts.Domain != "" {
+		data.Domain = opts.Domain
+	}
+	if opts.Framework != "" {
+		fw, err := templates.LookupFramework(opts.Framework)
+		if err != nil {
+			return data, err
+		}
+		data.Framework = fw
+	}
+	if opts.Database != "" {
+		db, err := templates.LookupDatabase(opts.Database)
+		if err != nil {
+			return data, err
+		}
+		data.Database = &db
+	}
+	data.Nginx = !opts.SkipNginx
+	data.Docker = opts.Docker
+	return data, nil
+}
+
+// generateCustom 使用自定义模板生成项目，未通过 --var 指定的变量在终端中交互询问
+func (m *Manager) generateCustom(projectPath string, data templates.Data, writer *tools.FileWriter, opts CreateOptions) error {
+	custom, cleanup, err := tools.NewTemplateManager().Resolve(opts.Template)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	custom.ApplyDefaults(&data)
+
+	if tools.IsTerminal(os.Stdin) {
+		prompter := tools.NewPrompter(os.Stdin, os.Stdout)
+		for _, prompt := range custom.Manifest.Prompts {
+			if _, ok := opts.Vars[prompt.Name]; ok {
+				continue
+			}
+			message := prompt.Message
+			if message == "" {
+				message = prompt.Name
+			}
+
+			var answer string
+			if len(prompt.Choices) > 0 {
+				answer, err = prompter.Choose(message, prompt.Choices, data.Vars[prompt.Name])
+			} else {
+				answer, err = prompter.Ask(message, data.Vars[prompt.Name])
+			}
+			if err != nil {
+				return err
+			}
+			data.Vars[prompt.Name] = answer
+		}
+	}
+
+	for key, value := range opts.Vars {
+		if !data.Set(key, value) {
+			data.Vars[key] = value
+		}
+	}
+
+	m.logger.Info("使用模板: %s", custom.Manifest.Name)
+	gen := generator.NewProjectGeneratorWithData(projectPath, data)
+	gen.SetWriter(writer)
+	return gen.GenerateCustom(custom)
 }
 
 // showNextSteps 显示项目创建后的后续步骤
-func (m *Manager) showNextSteps(projectName string) {
+func (m *Manager) showNextSteps(projectName string, data templates.Data) {
 	m.logger.Println(config.Messages.Success.NextSteps)
 	m.logger.Info(config.Messages.Success.Commands.Cd, projectName)
 	m.logger.Println(config.Messages.Success.Commands.Tidy)
-	m.logger.Println(config.Messages.Success.Commands.Air)
+	m.logger.Println(config.Messages.Success.Commands.Dev)
 	m.logger.PrintEmpty()
-	m.logger.Println(config.Messages.Success.Commands.Access)
-	
+	m.logger.Info(config.Messages.Success.Commands.Access, data.Port)
+
+	if data.Docker {
+		m.logger.PrintEmpty()
+		m.logger.Println("🐳 容器部署:")
+		m.logger.Println("  docker compose up -d")
+	}
+
+	if !data.Nginx {
+		return
+	}
+
 	// 显示部署相关信息
 	m.logger.PrintEmpty()
 	m.logger.Println("🌐 域名部署:")
-	m.logger.Println(config.Messages.Success.Deployment.NginxSetup)
-	m.logger.Println(config.Messages.Success.Deployment.SSLSetup)
-	m.logger.Println(config.Messages.Success.Deployment.DomainAccess)
-} 
\ No newline at end of file
+	m.logger.Info(config.Messages.Success.Deployment.NginxSetup, data.Domain)
+	m.logger.Info(config.Messages.Success.Deployment.SSLSetup, data.Domain)
+	m.logger.Info(config.Messages.Success.Deployment.DomainAccess, data.Domain)
+}
\ No newline at end of file
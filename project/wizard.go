@@ -0,0 +1,150 @@
+package project
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/templates"
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// noDatabase 向导中表示不使用数据库的选项
+const noDatabase = "none"
+
+// Wizard 交互式创建向导，逐项询问项目配置，已在opts中给出的项目名称不再询问
+func Wizard(p *tools.Prompter, opts CreateOptions) (CreateOptions, error) {
+	var err error
+
+	for strings.TrimSpace(opts.Name) == "" {
+		if opts.Name, err = p.Ask("项目名称", ""); err != nil {
+			return opts, err
+		}
+	}
+
+	if opts.Module == "" {
+		opts.Module = opts.Name
+	}
+	if opts.Module, err = p.Ask("Go模块路径", opts.Module); err != nil {
+		return opts, err
+	}
+
+	if opts.Framework == "" {
+		opts.Framework = templates.DefaultFramework
+	}
+	if opts.Framework, err = p.Choose("HTTP框架", templates.FrameworkNames(), opts.Framework); err != nil {
+		return opts, err
+	}
+
+	if opts.Port == "" {
+		opts.Port = config.DefaultPort
+	}
+	for {
+		if opts.Port, err = p.Ask("服务端口", opts.Port); err != nil {
+			return opts, err
+		}
+		if err := validatePort(opts.Port); err == nil {
+			break
+		}
+		p.Printf("无效的端口: %s\n", opts.Port)
+		opts.Port = config.DefaultPort
+	}
+
+	nginx, err := p.Confirm("是否生成nginx配置和SSL证书脚本", !opts.SkipNginx)
+	if err != nil {
+		return opts, err
+	}
+	opts.SkipNginx = !nginx
+	if nginx {
+		if opts.Domain == "" {
+			opts.Domain = config.DefaultDomain
+		}
+		for {
+			if opts.Domain, err = p.Ask("域名", opts.Domain); err != nil {
+				return opts, err
+			}
+			if err := config.ValidateDomain(opts.Domain); err == nil {
+				break
+			}
+			p.Printf("无效的域名: %s\n", opts.Domain)
+			opts.Domain = config.DefaultDomain
+		}
+	} else {
+		opts.Domain = ""
+	}
+
+	if opts.Docker, err = p.Confirm("是否生成Docker文件", opts.Docker); err != nil {
+		return opts, err
+	}
+
+	database := opts.Database
+	if database == "" {
+		database = noDatabase
+	}
+	choices := append([]string{noDatabase}, templates.DatabaseNames()...)
+	if database, err = p.Choose("数据库", choices, database); err != nil {
+		return opts, err
+	}
+	opts.Database = ""
+	if database != noDatabase {
+		opts.Database = database
+	}
+
+	return opts, nil
+}
+
+// CommandLine 返回与选项等价的非交互命令，便于在脚本中复用
+func (opts CreateOptions) CommandLine() string {
+	args := []string{config.Messages.ToolName, "create", opts.Name}
+	if opts.Dir != "" {
+		args = append(args, "--path", opts.Dir)
+	}
+	if opts.Module != "" && opts.Module != opts.Name {
+		args = append(args, "--module", opts.Module)
+	}
+	if opts.Framework != "" {
+		args = append(args, "--framework", opts.Framework)
+	}
+	if opts.Port != "" {
+		args = append(args, "--port", opts.Port)
+	}
+	if opts.SkipNginx {
+		args = append(args, "--no-nginx")
+	} else if opts.Domain != "" {
+		args = append(args, "--domain", opts.Domain)
+	}
+	if opts.Docker {
+		args = append(args, "--docker")
+	}
+	if opts.Database != "" {
+		args = append(args, "--db", opts.Database)
+	}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// validatePort 检查端口是否为1-65535之间的数字
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("无效的端口: %s", port)
+	}
+	return nil
+}
+
+// shellQuote 为包含特殊字符的参数加上单引号
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@=+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
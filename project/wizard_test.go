@@ -0,0 +1,75 @@
+package project
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/tools"
+)
+
+// TestWizard 测试交互向导的回答写入创建选项
+func TestWizard(t *testing.T) {
+	answers := strings.Join([]string{
+		"my-api",                 // 项目名称
+		"github.com/acme/my-api", // 模块路径
+		"chi",                    // 框架
+		"abc",                    // 无效端口
+		"9000",                   // 端口
+		"y",                      // nginx
+		"../evil",                // 无效域名
+		"api.acme.com",           // 域名
+		"y",                      // docker
+		"2",                      // 数据库: postgres
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	opts, err := Wizard(tools.NewPrompter(strings.NewReader(answers), &out), CreateOptions{})
+	if err != nil {
+		t.Fatalf("向导执行失败: %v", err)
+	}
+
+	want := CreateOptions{
+		Name:      "my-api",
+		Module:    "github.com/acme/my-api",
+		Framework: "chi",
+		Port:      "9000",
+		Domain:    "api.acme.com",
+		Docker:    true,
+		Database:  "postgres",
+	}
+	if opts.Name != want.Name || opts.Module != want.Module || opts.Framework != want.Framework ||
+		opts.Port != want.Port || opts.Domain != want.Domain || opts.SkipNginx ||
+		opts.Docker != want.Docker || opts.Database != want.Database {
+		t.Errorf("向导结果错误:\n实际 %+v\n期望 %+v", opts, want)
+	}
+	if !strings.Contains(out.String(), "无效的端口: abc") {
+		t.Error("无效端口应该提示并重新询问")
+	}
+	if !strings.Contains(out.String(), "无效的域名: ../evil") {
+		t.Error("无效域名应该提示并重新询问")
+	}
+
+	expect := "aigo_hotreload create my-api --module github.com/acme/my-api --framework chi --port 9000 --domain api.acme.com --docker --db postgres"
+	if got := opts.CommandLine(); got != expect {
+		t.Errorf("等价命令错误:\n实际 %s\n期望 %s", got, expect)
+	}
+}
+
+// TestWizardDefaults 测试直接回车使用默认值
+func TestWizardDefaults(t *testing.T) {
+	answers := strings.Repeat("\n", 3) + "n\n\n\n"
+
+	opts, err := Wizard(tools.NewPrompter(strings.NewReader(answers), &bytes.Buffer{}), CreateOptions{Name: "demo", Dir: "/tmp/my projects"})
+	if err != nil {
+		t.Fatalf("向导执行失败: %v", err)
+	}
+	if opts.Module != "demo" || opts.Framework != "gin" || opts.Port != "8888" || !opts.SkipNginx || opts.Docker || opts.Database != "" {
+		t.Errorf("默认值错误: %+v", opts)
+	}
+
+	expect := "aigo_hotreload create demo --path '/tmp/my projects' --framework gin --port 8888 --no-nginx"
+	if got := opts.CommandLine(); got != expect {
+		t.Errorf("等价命令错误:\n实际 %s\n期望 %s", got, expect)
+	}
+}
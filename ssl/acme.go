@@ -0,0 +1,251 @@
+package ssl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// ACME服务的目录地址
+const (
+	LetsEncryptURL        = "https://acme-v02.api.letsencrypt.org/directory"
+	LetsEncryptStagingURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+)
+
+// DefaultRenewBefore 证书剩余有效期少于该时长时续期
+const DefaultRenewBefore = 30 * 24 * time.Hour
+
+// challengeDir HTTP-01验证文件在webroot中的目录
+const challengeDir = ".well-known/acme-challenge"
+
+// Issuer 通过ACME（RFC 8555）的HTTP-01验证申请证书，验证文件写入nginx提供的webroot
+type Issuer struct {
+	DirectoryURL string       // ACME服务的目录地址
+	Store        Store        // 账户私钥和证书的保存目录
+	Webroot      string       // nginx中 /.well-known/acme-challenge/ 的root目录
+	Email        string       // 注册账户的联系邮箱，可以为空
+	HTTPClient   *http.Client // 访问ACME服务的客户端，为空时使用默认客户端
+	Logf         func(format string, args ...interface{})
+}
+
+// NewIssuer 创建使用Let's Encrypt的Issuer
+func NewIssuer(dir, webroot string) *Issuer {
+	return &Issuer{
+		DirectoryURL: LetsEncryptURL,
+		Store:        Store{Dir: dir},
+		Webroot:      webroot,
+	}
+}
+
+// HTTPClient 返回额外信任caFile中根证书的HTTP客户端，用于Pebble等使用自签名证书的ACME测试服务
+func HTTPClient(caFile string) (*http.Client, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s 中没有PEM格式的证书", caFile)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Transport: transport, Timeout: time.Minute}, nil
+}
+
+// AccountKeyFile 返回ACME服务对应的账户私钥路径，不同的服务使用不同的账户
+func (i *Issuer) AccountKeyFile() string {
+	host := i.DirectoryURL
+	if u, err := url.Parse(i.DirectoryURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return filepath.Join(i.Store.Dir, "accounts", strings.ReplaceAll(host, ":", "_"), "aigo-account.key")
+}
+
+// Issue 为domains申请一张证书，保存到第一个域名的证书目录，返回域名证书
+func (i *Issuer) Issue(ctx context.Context, domains []string) (*x509.Certificate, error) {
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("没有指定域名")
+	}
+	// 在创建订单之前检查，避免白白消耗申请次数
+	if i.Store.CertbotManaged(domains[0]) {
+		return nil, i.Store.certbotError(domains[0])
+	}
+	client, err := i.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(domains...))
+	if err != nil {
+		return nil, fmt.Errorf("创建证书订单失败: %v", err)
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := i.authorize(ctx, client, authzURL); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := client.WaitOrder(ctx, order.URI); err != nil {
+		return nil, fmt.Errorf("等待证书订单失败: %v", err)
+	}
+
+	// 每次申请都生成新的私钥
+	key, err := NewKey()
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(nil, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("创建证书请求失败: %v", err)
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("签发证书失败: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("解析证书失败: %v", err)
+	}
+	if err := i.Store.Save(domains[0], chain, key); err != nil {
+		return nil, err
+	}
+	i.logf("证书已保存到 %s，有效期至 %s", i.Store.LiveDir(domains[0]), leaf.NotAfter.Format(time.DateOnly))
+	return leaf, nil
+}
+
+// Renew 续期剩余有效期少于before的证书，domains为空时检查证书目录中的全部域名，返回已续期的域名；
+// 续期使用原证书中的全部域名，一个域名失败时继续处理其他域名
+func (i *Issuer) Renew(ctx context.Context, before time.Duration, domains []string) ([]string, error) {
+	if len(domains) == 0 {
+		var err error
+		if domains, err = i.Store.Domains(); err != nil {
+			return nil, err
+		}
+	}
+	var renewed []string
+	var errs []error
+	for _, domain := range domains {
+		if i.Store.CertbotManaged(domain) {
+			i.logf("%s 的证书由certbot管理，跳过", domain)
+			continue
+		}
+		certs, err := i.Store.Load(domain)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", domain, err))
+			continue
+		}
+		leaf := certs[0]
+		if remaining := time.Until(leaf.NotAfter); remaining > before {
+			i.logf("%s 的证书有效期至 %s，还有 %d 天，无需续期", domain, leaf.NotAfter.Format(time.DateOnly), int(remaining.Hours()/24))
+			continue
+		}
+		i.logf("正在续期 %s 的证书...", domain)
+		if _, err := i.Issue(ctx, certDomains(domain, leaf)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", domain, err))
+			continue
+		}
+		renewed = append(renewed, domain)
+	}
+	return renewed, errors.Join(errs...)
+}
+
+// client 创建ACME客户端并注册账户，账户私钥不存在时生成
+func (i *Issuer) client(ctx context.Context) (*acme.Client, error) {
+	key, err := LoadOrCreateKey(i.AccountKeyFile())
+	if err != nil {
+		return nil, fmt.Errorf("读取账户私钥失败: %v", err)
+	}
+	client := &acme.Client{
+		Key:          key,
+		DirectoryURL: i.DirectoryURL,
+		HTTPClient:   i.HTTPClient,
+		UserAgent:    "aigo_hotreload",
+	}
+	account := &acme.Account{}
+	if i.Email != "" {
+		account.Contact = []string{"mailto:" + i.Email}
+	}
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("注册ACME账户失败: %v", err)
+	}
+	return client, nil
+}
+
+// authorize 完成一个域名的HTTP-01验证，验证文件在结束后删除
+func (i *Issuer) authorize(ctx context.Context, client *acme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("获取域名验证失败: %v", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	domain := authz.Identifier.Value
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("域名 %s 不支持HTTP-01验证", domain)
+	}
+
+	response, err := client.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(i.Webroot, filepath.FromSlash(challengeDir), challenge.Token)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("创建验证目录失败: %v", err)
+	}
+	if err := os.WriteFile(file, []byte(response), 0644); err != nil {
+		return fmt.Errorf("写入验证文件失败: %v", err)
+	}
+	defer os.Remove(file)
+
+	i.logf("正在验证域名 %s...", domain)
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("域名 %s 验证失败: %v", domain, err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authzURL); err != nil {
+		return fmt.Errorf("域名 %s 验证失败: %v\n请确认域名解析到本机，并且nginx把 http://%s/%s/ 指向 %s", domain, err, domain, challengeDir, i.Webroot)
+	}
+	return nil
+}
+
+// logf 输出申请进度
+func (i *Issuer) logf(format string, args ...interface{}) {
+	if i.Logf != nil {
+		i.Logf(format, args...)
+	}
+}
+
+// certDomains 返回证书中的全部域名，domain排在第一个
+func certDomains(domain string, cert *x509.Certificate) []string {
+	domains := []string{domain}
+	for _, name := range cert.DNSNames {
+		if name != domain {
+			domains = append(domains, name)
+		}
+	}
+	return domains
+}
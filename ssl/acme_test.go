@@ -0,0 +1,327 @@
+package ssl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// fakeACME 按RFC 8555流程工作的ACME测试服务，与Pebble一样通过HTTP访问webroot完成HTTP-01验证，
+// 不校验JWS签名
+type fakeACME struct {
+	server   *httptest.Server
+	webroot  string        // 模拟nginx提供验证文件的地址
+	validity time.Duration // 签发证书的有效期
+	caKey    *ecdsa.PrivateKey
+	caCert   *x509.Certificate
+
+	mu       sync.Mutex
+	jwk      map[string]string // 账户公钥
+	accounts int
+	orders   map[string]*fakeOrder
+	issued   int
+}
+
+// fakeOrder 测试服务中的一个订单
+type fakeOrder struct {
+	domains []string
+	status  string
+	authz   map[string]string // 域名 -> 验证状态
+	tokens  map[string]string // 域名 -> token
+	cert    []byte
+}
+
+// newFakeACME 启动ACME测试服务，webroot为提供验证文件的HTTP服务地址
+func newFakeACME(t *testing.T, webroot string) *fakeACME {
+	t.Helper()
+	f := &fakeACME{webroot: webroot, validity: 90 * 24 * time.Hour, orders: map[string]*fakeOrder{}}
+	f.caKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Fake ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &f.caKey.PublicKey, f.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.caCert, _ = x509.ParseCertificate(der)
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// url 返回测试服务中的地址
+func (f *fakeACME) url(path string) string {
+	return f.server.URL + path
+}
+
+// handle 处理ACME请求
+func (f *fakeACME) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", time.Now().UnixNano()))
+	if r.URL.Path == "/directory" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"newNonce":   f.url("/nonce"),
+			"newAccount": f.url("/account"),
+			"newOrder":   f.url("/order"),
+			"meta":       map[string]string{"termsOfService": f.url("/terms")},
+		})
+		return
+	}
+	if r.URL.Path == "/nonce" {
+		return
+	}
+
+	var jws struct{ Protected, Payload string }
+	json.NewDecoder(r.Body).Decode(&jws)
+	protected, _ := base64.RawURLEncoding.DecodeString(jws.Protected)
+	payload, _ := base64.RawURLEncoding.DecodeString(jws.Payload)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch parts[0] {
+	case "account":
+		var header struct{ JWK map[string]string }
+		json.Unmarshal(protected, &header)
+		w.Header().Set("Location", f.url("/acct/1"))
+		if f.jwk != nil {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			f.jwk = header.JWK
+			f.accounts++
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "valid"})
+	case "order":
+		if len(parts) == 1 {
+			var req struct{ Identifiers []struct{ Value string } }
+			json.Unmarshal(payload, &req)
+			id := fmt.Sprint(len(f.orders) + 1)
+			order := &fakeOrder{status: "pending", authz: map[string]string{}, tokens: map[string]string{}}
+			for _, ident := range req.Identifiers {
+				order.domains = append(order.domains, ident.Value)
+				order.authz[ident.Value] = "pending"
+				order.tokens[ident.Value] = "token-" + id + "-" + ident.Value
+			}
+			f.orders[id] = order
+			w.Header().Set("Location", f.url("/order/"+id))
+			w.WriteHeader(http.StatusCreated)
+			f.writeOrder(w, id)
+			return
+		}
+		f.writeOrder(w, parts[1])
+	case "authz":
+		order, domain := f.orders[parts[1]], parts[2]
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     order.authz[domain],
+			"identifier": map[string]string{"type": "dns", "value": domain},
+			"challenges": []map[string]string{
+				{"type": "dns-01", "url": f.url("/chal/dns"), "token": "dns", "status": "pending"},
+				{"type": "http-01", "url": f.url("/chal/" + parts[1] + "/" + domain), "token": order.tokens[domain], "status": order.authz[domain]},
+			},
+		})
+	case "chal":
+		// 与Pebble相同，通过HTTP获取验证文件并比较key authorization
+		order, domain := f.orders[parts[1]], parts[2]
+		token := order.tokens[domain]
+		order.authz[domain] = "invalid"
+		req, _ := http.NewRequest("GET", f.webroot+"/.well-known/acme-challenge/"+token, nil)
+		req.Host = domain
+		if res, err := http.DefaultClient.Do(req); err == nil {
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			if string(body) == token+"."+f.thumbprint() {
+				order.authz[domain] = "valid"
+			}
+		}
+		if order.status == "pending" && order.authz[domain] == "valid" {
+			order.status = "ready"
+			for _, status := range order.authz {
+				if status != "valid" {
+					order.status = "pending"
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]string{"type": "http-01", "url": f.url(r.URL.Path), "token": token, "status": order.authz[domain]})
+	case "finalize":
+		var req struct{ CSR string }
+		json.Unmarshal(payload, &req)
+		der, _ := base64.RawURLEncoding.DecodeString(req.CSR)
+		csr, err := x509.ParseCertificateRequest(der)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(f.validity),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+		cert, err := x509.CreateCertificate(rand.Reader, template, f.caCert, csr.PublicKey, f.caKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		order := f.orders[parts[1]]
+		order.cert = append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.caCert.Raw})...)
+		order.status = "valid"
+		f.issued++
+		f.writeOrder(w, parts[1])
+	case "cert":
+		w.Write(f.orders[parts[1]].cert)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// writeOrder 返回订单的JSON
+func (f *fakeACME) writeOrder(w http.ResponseWriter, id string) {
+	order := f.orders[id]
+	var authz []string
+	for _, domain := range order.domains {
+		authz = append(authz, f.url("/authz/"+id+"/"+domain))
+	}
+	resp := map[string]interface{}{"status": order.status, "authorizations": authz, "finalize": f.url("/finalize/" + id)}
+	if order.status == "valid" {
+		resp["certificate"] = f.url("/cert/" + id)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// thumbprint 计算账户公钥的JWK thumbprint
+func (f *fakeACME) thumbprint() string {
+	x, _ := base64.RawURLEncoding.DecodeString(f.jwk["x"])
+	y, _ := base64.RawURLEncoding.DecodeString(f.jwk["y"])
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	thumbprint, _ := acme.JWKThumbprint(key)
+	return thumbprint
+}
+
+// newTestIssuer 创建使用测试服务的Issuer，webroot由模拟的nginx提供
+func newTestIssuer(t *testing.T) (*Issuer, *fakeACME) {
+	t.Helper()
+	webroot := t.TempDir()
+	nginx := httptest.NewServer(http.FileServer(http.Dir(webroot)))
+	t.Cleanup(nginx.Close)
+	f := newFakeACME(t, nginx.URL)
+	issuer := NewIssuer(t.TempDir(), webroot)
+	issuer.DirectoryURL = f.url("/directory")
+	return issuer, f
+}
+
+// TestIssue 测试通过HTTP-01验证申请证书
+func TestIssue(t *testing.T) {
+	issuer, f := newTestIssuer(t)
+	ctx := context.Background()
+	cert, err := issuer.Issue(ctx, []string{"a.example.com", "www.a.example.com"})
+	if err != nil {
+		t.Fatalf("申请证书失败: %v", err)
+	}
+	if strings.Join(cert.DNSNames, ",") != "a.example.com,www.a.example.com" {
+		t.Errorf("证书域名错误: %v", cert.DNSNames)
+	}
+
+	dir := issuer.Store.LiveDir("a.example.com")
+	chain, err := LoadCertificates(filepath.Join(dir, FullchainFile))
+	if err != nil || len(chain) != 2 || chain[1].Subject.CommonName != "Fake ACME CA" {
+		t.Fatalf("fullchain.pem应该包含证书和中间证书: %v", err)
+	}
+	key, err := os.ReadFile(filepath.Join(dir, PrivkeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ParseKey(key)
+	if err != nil || !signer.Public().(*ecdsa.PublicKey).Equal(chain[0].PublicKey) {
+		t.Errorf("私钥与证书不匹配: %v", err)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, PrivkeyFile)); info.Mode().Perm() != 0600 {
+		t.Errorf("私钥权限应该是0600, 实际得到 %o", info.Mode().Perm())
+	}
+	tokens, _ := os.ReadDir(filepath.Join(issuer.Webroot, challengeDir))
+	if len(tokens) != 0 {
+		t.Errorf("验证完成后应该删除验证文件, 剩余 %d 个", len(tokens))
+	}
+
+	// 再次申请时复用账户私钥
+	if _, err := issuer.Issue(ctx, []string{"b.example.com"}); err != nil {
+		t.Fatalf("再次申请失败: %v", err)
+	}
+	if f.accounts != 1 {
+		t.Errorf("应该只注册一个账户, 实际注册 %d 个", f.accounts)
+	}
+	if domains, _ := issuer.Store.Domains(); strings.Join(domains, ",") != "a.example.com,b.example.com" {
+		t.Errorf("证书目录中的域名错误: %v", domains)
+	}
+}
+
+// TestIssueChallengeFailure 测试webroot没有提供验证文件时返回错误
+func TestIssueChallengeFailure(t *testing.T) {
+	issuer, f := newTestIssuer(t)
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	f.webroot = missing.URL
+	_, err := issuer.Issue(context.Background(), []string{"a.example.com"})
+	if err == nil || !strings.Contains(err.Error(), "a.example.com 验证失败") {
+		t.Fatalf("验证失败时应该返回错误, 实际得到: %v", err)
+	}
+	if _, err := os.Stat(issuer.Store.LiveDir("a.example.com")); !os.IsNotExist(err) {
+		t.Errorf("验证失败时不应该保存证书: %v", err)
+	}
+}
+
+// TestRenew 测试只续期即将过期的证书
+func TestRenew(t *testing.T) {
+	issuer, f := newTestIssuer(t)
+	ctx := context.Background()
+	f.validity = 10 * 24 * time.Hour
+	if _, err := issuer.Issue(ctx, []string{"old.example.com", "www.old.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	f.validity = 90 * 24 * time.Hour
+	if _, err := issuer.Issue(ctx, []string{"new.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	renewed, err := issuer.Renew(ctx, DefaultRenewBefore, nil)
+	if err != nil || strings.Join(renewed, ",") != "old.example.com" {
+		t.Fatalf("应该只续期old.example.com, 实际得到 %v, %v", renewed, err)
+	}
+	certs, _ := issuer.Store.Load("old.example.com")
+	if time.Until(certs[0].NotAfter) < 80*24*time.Hour || strings.Join(certs[0].DNSNames, ",") != "old.example.com,www.old.example.com" {
+		t.Errorf("续期后的证书错误: %v %v", certs[0].NotAfter, certs[0].DNSNames)
+	}
+	if f.issued != 3 {
+		t.Errorf("应该签发3张证书, 实际签发 %d 张", f.issued)
+	}
+
+	if _, err := issuer.Renew(ctx, DefaultRenewBefore, []string{"missing.example.com"}); err == nil {
+		t.Error("没有证书的域名应该返回错误")
+	}
+}
@@ -0,0 +1,196 @@
+package ssl
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 本地CA目录中的文件
+const (
+	LocalCAFile    = "rootCA.pem"     // 需要加入系统信任库的根证书
+	LocalCAKeyFile = "rootCA-key.pem" // 根证书私钥，只允许所有者读取
+)
+
+// 本地证书的有效期；浏览器不接受有效期超过825天的证书
+const (
+	localCAValidity   = 10 * 365 * 24 * time.Hour
+	localCertValidity = 825 * 24 * time.Hour
+)
+
+// DefaultLocalNames 未指定域名时本地证书包含的名称
+var DefaultLocalNames = []string{"localhost", "127.0.0.1", "::1"}
+
+// LocalCA 本地开发使用的根证书，只在本机生成和信任，用于给localhost、*.test等域名签发证书
+type LocalCA struct {
+	Dir  string
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+// LoadOrCreateLocalCA 读取dir中的本地根证书，不存在时生成；返回的created表示是否新生成
+func LoadOrCreateLocalCA(dir string) (ca *LocalCA, created bool, err error) {
+	ca = &LocalCA{Dir: dir}
+	certFile, keyFile := ca.CertFile(), filepath.Join(dir, LocalCAKeyFile)
+	if _, err := os.Stat(certFile); err == nil {
+		certs, err := LoadCertificates(certFile)
+		if err != nil {
+			return nil, false, err
+		}
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, false, fmt.Errorf("读取本地CA私钥失败: %v", err)
+		}
+		if ca.Key, err = ParseKey(data); err != nil {
+			return nil, false, err
+		}
+		ca.Cert = certs[0]
+		return ca, false, nil
+	}
+
+	key, err := NewKey()
+	if err != nil {
+		return nil, false, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, false, err
+	}
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"aigo_hotreload development CA"},
+			CommonName:   "aigo_hotreload local CA " + hostname,
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(localCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, false, fmt.Errorf("生成本地CA失败: %v", err)
+	}
+	if ca.Cert, err = x509.ParseCertificate(der); err != nil {
+		return nil, false, err
+	}
+	ca.Key = key
+
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, false, err
+	}
+	if err := writeFileAtomic(keyFile, keyPEM, keyPermission); err != nil {
+		return nil, false, err
+	}
+	if err := writeFileAtomic(certFile, encodeCerts([][]byte{der}), 0644); err != nil {
+		return nil, false, err
+	}
+	return ca, true, nil
+}
+
+// CertFile 返回根证书路径
+func (ca *LocalCA) CertFile() string {
+	return filepath.Join(ca.Dir, LocalCAFile)
+}
+
+// Fingerprint 返回根证书的SHA-256指纹
+func (ca *LocalCA) Fingerprint() string {
+	sum := sha256.Sum256(ca.Cert.Raw)
+	return fmt.Sprintf("%X", sum[:])
+}
+
+// Issue 为names签发服务器证书，IP地址写入IP SAN，其他名称（可以是 *.test 这样的通配符）写入DNS SAN
+func (ca *LocalCA) Issue(names []string) (chain [][]byte, key crypto.Signer, err error) {
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("没有指定域名")
+	}
+	if key, err = NewKey(); err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"aigo_hotreload development certificate"},
+			CommonName:   names[0],
+		},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(localCertValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("签发本地证书失败: %v", err)
+	}
+	// 根证书已在信任库中，不放入证书链
+	return [][]byte{der}, key, nil
+}
+
+// LocalCertName 返回本地证书在证书目录中使用的名称，通配符的 * 替换为 _wildcard
+func LocalCertName(names []string) string {
+	return strings.ReplaceAll(names[0], "*", "_wildcard")
+}
+
+// TrustInstructions 返回把根证书加入goos系统信任库的命令
+func TrustInstructions(goos, caFile string) []string {
+	var lines []string
+	switch goos {
+	case "darwin":
+		lines = append(lines,
+			"sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain "+caFile)
+	case "windows":
+		lines = append(lines,
+			"certutil -addstore -f ROOT "+caFile+"   # 在管理员命令行中运行")
+	default:
+		lines = append(lines,
+			"# Debian/Ubuntu",
+			"sudo cp "+caFile+" /usr/local/share/ca-certificates/aigo-local-ca.crt && sudo update-ca-certificates",
+			"# CentOS/RHEL/Fedora",
+			"sudo trust anchor --store "+caFile)
+	}
+	if goos != "windows" {
+		// Firefox和Linux上的Chrome使用自己的NSS数据库
+		lines = append(lines,
+			"# Firefox / Linux上的Chrome（需要安装libnss3-tools）",
+			`certutil -d sql:$HOME/.pki/nssdb -A -t "C,," -n "aigo_hotreload local CA" -i `+caFile)
+	}
+	return lines
+}
+
+// randomSerial 生成128位随机证书序列号
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("生成证书序列号失败: %v", err)
+	}
+	return serial, nil
+}
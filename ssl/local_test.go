@@ -0,0 +1,86 @@
+package ssl
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLocalCA 测试本地CA只生成一次，签发的证书可以用根证书验证
+func TestLocalCA(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ca")
+	ca, created, err := LoadOrCreateLocalCA(dir)
+	if err != nil || !created {
+		t.Fatalf("生成本地CA失败: %v, created=%v", err, created)
+	}
+	if !ca.Cert.IsCA {
+		t.Error("根证书应该是CA证书")
+	}
+	info, err := os.Stat(filepath.Join(dir, LocalCAKeyFile))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("根证书私钥权限应该是0600: %v, %v", info, err)
+	}
+
+	again, created, err := LoadOrCreateLocalCA(dir)
+	if err != nil || created {
+		t.Fatalf("已有本地CA时不应该重新生成: %v, created=%v", err, created)
+	}
+	if again.Fingerprint() != ca.Fingerprint() {
+		t.Error("重新读取的根证书指纹不一致")
+	}
+
+	names := []string{"localhost", "*.test", "127.0.0.1", "::1"}
+	chain, key, err := again.Issue(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 1 {
+		t.Errorf("证书链不应该包含根证书: %d", len(chain))
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(leaf.DNSNames, ",") != "localhost,*.test" || len(leaf.IPAddresses) != 2 {
+		t.Errorf("证书名称错误: %v %v", leaf.DNSNames, leaf.IPAddresses)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	for _, name := range []string{"localhost", "app.test", "127.0.0.1", "::1"} {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: name, Roots: roots}); err != nil {
+			t.Errorf("%s 验证失败: %v", name, err)
+		}
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots}); err == nil {
+		t.Error("证书不应该对 example.com 有效")
+	}
+
+	store := Store{Dir: t.TempDir()}
+	name := LocalCertName([]string{"*.test"})
+	if name != "_wildcard.test" {
+		t.Errorf("通配符证书名称错误: %s", name)
+	}
+	if err := store.Save(name, chain, key); err != nil {
+		t.Fatal(err)
+	}
+	if certs, err := store.Load(name); err != nil || certs[0].SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+		t.Errorf("读取保存的本地证书失败: %v", err)
+	}
+}
+
+// TestTrustInstructions 测试各系统的信任库命令
+func TestTrustInstructions(t *testing.T) {
+	for goos, want := range map[string]string{
+		"darwin":  "security add-trusted-cert",
+		"linux":   "update-ca-certificates",
+		"windows": "certutil -addstore -f ROOT",
+	} {
+		lines := strings.Join(TrustInstructions(goos, "/ca/rootCA.pem"), "\n")
+		if !strings.Contains(lines, want) || !strings.Contains(lines, "/ca/rootCA.pem") {
+			t.Errorf("%s 的信任库命令错误:\n%s", goos, lines)
+		}
+	}
+}
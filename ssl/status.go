@@ -0,0 +1,53 @@
+package ssl
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// CertificateInfo 证书文件的检查结果
+type CertificateInfo struct {
+	Path     string
+	Sources  []string // 发现该证书的位置: 证书目录或引用它的nginx配置 file:line
+	Subject  string
+	Issuer   string
+	Names    []string // 证书中的DNS和IP名称
+	NotAfter time.Time
+	Err      error // 文件不存在或无法解析
+}
+
+// Inspect 读取并解析证书文件，错误记录在返回结果中
+func Inspect(path string) CertificateInfo {
+	info := CertificateInfo{Path: path}
+	certs, err := LoadCertificates(path)
+	if err != nil {
+		info.Err = err
+		return info
+	}
+	leaf := certs[0]
+	info.Subject = leaf.Subject.CommonName
+	info.Issuer = leaf.Issuer.CommonName
+	if info.Issuer == "" {
+		info.Issuer = strings.Join(leaf.Issuer.Organization, ", ")
+	}
+	info.Names = append(info.Names, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		info.Names = append(info.Names, ip.String())
+	}
+	if len(info.Names) == 0 && leaf.Subject.CommonName != "" {
+		info.Names = []string{leaf.Subject.CommonName}
+	}
+	info.NotAfter = leaf.NotAfter
+	return info
+}
+
+// DaysLeft 返回now到证书过期的剩余天数，向下取整: 剩余不足一天为0，过期不足一天为-1
+func (c CertificateInfo) DaysLeft(now time.Time) int {
+	return int(math.Floor(c.NotAfter.Sub(now).Hours() / 24))
+}
+
+// Expiring 判断证书是否无法读取、已过期或剩余有效期少于threshold
+func (c CertificateInfo) Expiring(now time.Time, threshold time.Duration) bool {
+	return c.Err != nil || c.NotAfter.Sub(now) < threshold
+}
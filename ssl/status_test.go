@@ -0,0 +1,42 @@
+package ssl
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestInspect 测试读取证书的颁发者、域名和剩余天数
+func TestInspect(t *testing.T) {
+	der, key := selfSigned(t, "a.com")
+	store := Store{Dir: t.TempDir()}
+	if err := store.Save("a.com", [][]byte{der}, key); err != nil {
+		t.Fatal(err)
+	}
+
+	info := Inspect(filepath.Join(store.LiveDir("a.com"), FullchainFile))
+	if info.Err != nil {
+		t.Fatal(info.Err)
+	}
+	if info.Subject != "a.com" || info.Issuer != "a.com" || len(info.Names) != 1 || info.Names[0] != "a.com" {
+		t.Errorf("证书信息错误: %+v", info)
+	}
+	now := time.Now()
+	if days := info.DaysLeft(now); days != 0 {
+		t.Errorf("一小时后过期的证书剩余天数应该是0, 实际得到 %d", days)
+	}
+	if !info.Expiring(now, 24*time.Hour) || info.Expiring(now, 0) {
+		t.Error("一小时后过期的证书应该在1天阈值内过期")
+	}
+	if days := info.DaysLeft(now.Add(2 * time.Hour)); days != -1 {
+		t.Errorf("过期不足一天的证书剩余天数应该是-1, 实际得到 %d", days)
+	}
+	if days := info.DaysLeft(now.Add(48 * time.Hour)); days != -2 {
+		t.Errorf("过期一天多的证书剩余天数应该是-2, 实际得到 %d", days)
+	}
+
+	missing := Inspect(filepath.Join(t.TempDir(), FullchainFile))
+	if missing.Err == nil || !missing.Expiring(now, 0) {
+		t.Error("不存在的证书应该返回错误并视为需要处理")
+	}
+}
@@ -0,0 +1,238 @@
+package ssl
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultDir 证书目录，与certbot相同，生成的nginx HTTPS配置直接使用其中的 live/<domain>/ 文件
+const DefaultDir = "/etc/letsencrypt"
+
+// 证书目录中每个域名的文件，与certbot的命名相同
+const (
+	FullchainFile = "fullchain.pem" // 证书和中间证书，nginx的ssl_certificate
+	PrivkeyFile   = "privkey.pem"   // 私钥，nginx的ssl_certificate_key
+	CertFile      = "cert.pem"      // 只有域名证书
+	ChainFile     = "chain.pem"     // 只有中间证书
+)
+
+// keyPermission 私钥文件权限，只允许所有者读写
+const keyPermission = 0600
+
+// Store 证书目录，每个域名的证书保存在 live/<domain>/ 下
+type Store struct {
+	Dir string
+}
+
+// LiveDir 返回域名的证书目录
+func (s Store) LiveDir(domain string) string {
+	return filepath.Join(s.Dir, "live", domain)
+}
+
+// Domains 返回目录中已有证书的全部域名
+func (s Store) Domains() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Dir, "live"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var domains []string
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(s.Dir, "live", e.Name(), FullchainFile)); err == nil {
+			domains = append(domains, e.Name())
+		}
+	}
+	sort.Strings(domains)
+	return domains, nil
+}
+
+// Load 读取域名的证书链，第一个为域名证书
+func (s Store) Load(domain string) ([]*x509.Certificate, error) {
+	return LoadCertificates(filepath.Join(s.LiveDir(domain), FullchainFile))
+}
+
+// CertbotManaged 判断域名的证书是否由certbot管理：certbot的 live/<domain>/ 中是指向 archive/ 的软链接，
+// 替换成普通文件会破坏certbot的续期
+func (s Store) CertbotManaged(domain string) bool {
+	for _, name := range []string{PrivkeyFile, CertFile, ChainFile, FullchainFile} {
+		info, err := os.Lstat(filepath.Join(s.LiveDir(domain), name))
+		if err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// certbotError 拒绝覆盖certbot管理的证书时返回的错误
+func (s Store) certbotError(domain string) error {
+	return fmt.Errorf("%s 中的证书由certbot管理（文件是指向archive的软链接），覆盖会破坏certbot的续期；"+
+		"请继续使用 certbot renew，或使用 --dir 指定其他证书目录", s.LiveDir(domain))
+}
+
+// Save 保存证书链和私钥，每个文件先写入临时文件再替换，私钥只允许所有者读取；不覆盖certbot管理的证书
+func (s Store) Save(domain string, chain [][]byte, key crypto.Signer) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("证书链为空")
+	}
+	if s.CertbotManaged(domain) {
+		return s.certbotError(domain)
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	dir := s.LiveDir(domain)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	files := []struct {
+		name    string
+		content []byte
+		mode    os.FileMode
+	}{
+		{PrivkeyFile, keyPEM, keyPermission},
+		{CertFile, encodeCerts(chain[:1]), 0644},
+		{ChainFile, encodeCerts(chain[1:]), 0644},
+		{FullchainFile, encodeCerts(chain), 0644},
+	}
+	for _, f := range files {
+		if err := writeFileAtomic(filepath.Join(dir, f.name), f.content, f.mode); err != nil {
+			return fmt.Errorf("保存 %s 失败: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+// LoadCertificates 读取PEM文件中的全部证书
+func LoadCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%s 中没有证书", path)
+	}
+	return certs, nil
+}
+
+// LoadOrCreateKey 读取PEM私钥，文件不存在时生成ECDSA P-256私钥并保存
+func LoadOrCreateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		key, err := NewKey()
+		if err != nil {
+			return nil, err
+		}
+		content, err := encodeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(path, content, keyPermission); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseKey(data)
+}
+
+// NewKey 生成ECDSA P-256私钥
+func NewKey() (crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("生成私钥失败: %v", err)
+	}
+	return key, nil
+}
+
+// ParseKey 解析PEM编码的私钥
+func ParseKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("私钥不是PEM格式")
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析私钥失败: %v", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("不支持的私钥类型 %T", key)
+	}
+	return signer, nil
+}
+
+// encodeKey 将私钥编码为PKCS#8 PEM
+func encodeKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("编码私钥失败: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// encodeCerts 将DER证书编码为PEM
+func encodeCerts(chain [][]byte) []byte {
+	var out []byte
+	for _, der := range chain {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	return out
+}
+
+// writeFileAtomic 先写入同目录的临时文件再重命名，避免nginx读到写了一半的文件
+func writeFileAtomic(path string, content []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,128 @@
+package ssl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// selfSigned 生成自签名证书
+func selfSigned(t *testing.T, domain string) ([]byte, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, _ := NewKey()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der, key.(*ecdsa.PrivateKey)
+}
+
+// TestStoreSaveAndLoad 测试按certbot的目录结构保存证书
+func TestStoreSaveAndLoad(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	if domains, err := store.Domains(); err != nil || len(domains) != 0 {
+		t.Errorf("空目录不应该有域名: %v, %v", domains, err)
+	}
+
+	der, key := selfSigned(t, "a.com")
+	if err := store.Save("a.com", [][]byte{der}, key); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+	for _, name := range []string{FullchainFile, PrivkeyFile, CertFile, ChainFile} {
+		if _, err := os.Stat(filepath.Join(store.LiveDir("a.com"), name)); err != nil {
+			t.Errorf("应该生成 %s: %v", name, err)
+		}
+	}
+	certs, err := store.Load("a.com")
+	if err != nil || len(certs) != 1 || certs[0].Subject.CommonName != "a.com" {
+		t.Fatalf("读取证书失败: %v", err)
+	}
+	if domains, _ := store.Domains(); len(domains) != 1 || domains[0] != "a.com" {
+		t.Errorf("证书目录中的域名错误: %v", domains)
+	}
+	if err := store.Save("b.com", nil, key); err == nil {
+		t.Error("空证书链应该返回错误")
+	}
+}
+
+// TestStoreCertbotLineage 测试不覆盖certbot管理的证书: live/<domain>/ 中是指向archive的软链接
+func TestStoreCertbotLineage(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	der, key := selfSigned(t, "a.com")
+	archive := Store{Dir: filepath.Join(store.Dir, "archive-src")}
+	if err := archive.Save("a.com", [][]byte{der}, key); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(store.LiveDir("a.com"), 0755)
+	for _, name := range []string{FullchainFile, PrivkeyFile, CertFile, ChainFile} {
+		if err := os.Symlink(filepath.Join(archive.LiveDir("a.com"), name), filepath.Join(store.LiveDir("a.com"), name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !store.CertbotManaged("a.com") || store.CertbotManaged("b.com") {
+		t.Error("只有软链接的证书目录应该被识别为certbot管理")
+	}
+	if err := store.Save("a.com", [][]byte{der}, key); err == nil {
+		t.Error("覆盖certbot管理的证书应该返回错误")
+	}
+	if info, err := os.Lstat(filepath.Join(store.LiveDir("a.com"), FullchainFile)); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("certbot的软链接应该保留: %v", err)
+	}
+	if domains, _ := store.Domains(); len(domains) != 1 {
+		t.Errorf("certbot管理的证书仍然应该列出: %v", domains)
+	}
+
+	// 续期时跳过，不访问ACME服务
+	issuer := NewIssuer(store.Dir, t.TempDir())
+	issuer.DirectoryURL = "http://127.0.0.1:1/directory"
+	renewed, err := issuer.Renew(context.Background(), 1000*24*time.Hour, []string{"a.com"})
+	if err != nil || len(renewed) != 0 {
+		t.Errorf("续期应该跳过certbot管理的证书: %v, %v", renewed, err)
+	}
+	if _, err := issuer.Issue(context.Background(), []string{"a.com"}); err == nil || !strings.Contains(err.Error(), "certbot") {
+		t.Errorf("申请certbot管理的域名应该在访问ACME服务之前返回错误: %v", err)
+	}
+}
+
+// TestLoadOrCreateKey 测试生成和读取私钥
+func TestLoadOrCreateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts", "account.key")
+	key, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("私钥权限应该是0600, 实际得到 %o", info.Mode().Perm())
+	}
+	again, err := LoadOrCreateKey(path)
+	if err != nil || !again.Public().(*ecdsa.PublicKey).Equal(key.Public()) {
+		t.Errorf("应该读取已有的私钥: %v", err)
+	}
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	if _, err := ParseKey(pkcs1); err != nil {
+		t.Errorf("应该支持PKCS#1私钥: %v", err)
+	}
+	if _, err := ParseKey([]byte("not a key")); err == nil {
+		t.Error("非PEM内容应该返回错误")
+	}
+}
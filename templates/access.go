@@ -0,0 +1,119 @@
+package templates
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NginxRoot 服务器上nginx配置的默认根目录，htpasswd文件和brotli配置片段安装在其下
+const NginxRoot = "/etc/nginx"
+
+// HtpasswdDir 服务器上存放各域名htpasswd文件的默认目录，由 setup-nginx.sh 安装
+const HtpasswdDir = NginxRoot + "/htpasswd"
+
+// limitRate limit_req_zone的速率，每秒或每分钟的请求数
+var limitRate = regexp.MustCompile(`^[1-9][0-9]*r/[sm]$`)
+
+// Credential basic auth的用户和密码哈希
+type Credential struct {
+	User string
+	Hash string
+}
+
+// LimitZone 一个路由的请求速率限制区域
+type LimitZone struct {
+	Name string
+	Path string
+	Rate string
+}
+
+// validateAccess 检查速率限制和IP访问列表
+func (l Location) validateAccess() error {
+	if l.Rate != "" && !limitRate.MatchString(l.Rate) {
+		return fmt.Errorf("rate 格式应为每秒或每分钟的请求数（如 10r/s、60r/m）: %s", l.Rate)
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("burst 不能为负数: %d", l.Burst)
+	}
+	if l.Burst > 0 && l.Rate == "" {
+		return fmt.Errorf("burst 需要与 rate 一起使用")
+	}
+	for _, addr := range append(append([]string{}, l.Allow...), l.Deny...) {
+		if net.ParseIP(addr) == nil {
+			if _, _, err := net.ParseCIDR(addr); err != nil {
+				return fmt.Errorf("allow 和 deny 应为IP或CIDR: %q", addr)
+			}
+		}
+	}
+	return nil
+}
+
+// LimitZones 返回各路由的limit_req_zone，名称由域名和路由序号组成
+func (d Data) LimitZones() []LimitZone {
+	var zones []LimitZone
+	for i, l := range d.Locations {
+		if l.Rate != "" {
+			zones = append(zones, LimitZone{Name: d.limitZone(i), Path: l.Path, Rate: l.Rate})
+		}
+	}
+	return zones
+}
+
+// limitZone 返回第i个路由的速率限制区域名
+func (d Data) limitZone(i int) string {
+	return d.ConfigName() + "_limit" + strconv.Itoa(i+1)
+}
+
+// nginxRoot 返回服务器上nginx配置的根目录
+func (d Data) nginxRoot() string {
+	if d.NginxRoot == "" {
+		return NginxRoot
+	}
+	return strings.TrimSuffix(d.NginxRoot, "/")
+}
+
+// HtpasswdDir 返回服务器上存放htpasswd文件的目录
+func (d Data) HtpasswdDir() string {
+	return d.nginxRoot() + "/htpasswd"
+}
+
+// HtpasswdFile 返回服务器上本域名的htpasswd文件
+func (d Data) HtpasswdFile() string {
+	return d.HtpasswdDir() + "/" + d.Domain
+}
+
+// HtpasswdConfigFile 返回项目中本域名htpasswd文件的相对路径
+func (d Data) HtpasswdConfigFile() string {
+	return "config/" + d.Domain + ".htpasswd"
+}
+
+// AuthEnabled 判断是否有路由启用了basic auth
+func (d Data) AuthEnabled() bool {
+	for _, l := range d.Locations {
+		if l.Auth {
+			return true
+		}
+	}
+	return false
+}
+
+// setAccess 设置第i个路由的速率限制、IP访问列表和basic auth
+func (d Data) setAccess(block *LocationBlock, i int, l Location) {
+	if l.Rate != "" {
+		block.LimitZone = d.limitZone(i)
+		block.Burst = l.Burst
+	}
+	block.Allow = l.Allow
+	block.Deny = l.Deny
+	if l.Auth {
+		block.AuthFile = d.HtpasswdFile()
+	}
+}
+
+// Restricted 判断location是否设置了访问控制
+func (b LocationBlock) Restricted() bool {
+	return b.LimitZone != "" || len(b.Allow) > 0 || len(b.Deny) > 0 || b.AuthFile != ""
+}
@@ -0,0 +1,90 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseLocationAccess 测试解析路由的访问控制参数
+func TestParseLocationAccess(t *testing.T) {
+	location, err := ParseLocation("/admin/=9001,rate=10r/s,burst=20,allow=10.0.0.0/8,allow=192.168.1.10,deny=10.0.0.5,auth")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if location.Rate != "10r/s" || location.Burst != 20 || !location.Auth {
+		t.Errorf("访问控制解析错误: %+v", location)
+	}
+	if strings.Join(location.Allow, " ") != "10.0.0.0/8 192.168.1.10" || strings.Join(location.Deny, " ") != "10.0.0.5" {
+		t.Errorf("IP列表解析错误: allow=%v deny=%v", location.Allow, location.Deny)
+	}
+	if _, err := ParseLocation("/,root=/srv/www,auth,allow=::1"); err != nil {
+		t.Errorf("静态目录应该支持访问控制: %v", err)
+	}
+
+	invalid := []string{
+		"/api,rate=10",
+		"/api,rate=0r/s",
+		"/api,rate=10r/h",
+		"/api,burst=5",
+		"/api,rate=1r/s,burst=-1",
+		"/api,rate=1r/s,burst=many",
+		"/api,allow=all",
+		"/api,deny=10.0.0.0/33",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseLocation(spec); err == nil {
+			t.Errorf("%q 应该返回错误", spec)
+		}
+	}
+}
+
+// TestNginxAccessTemplate 测试渲染速率限制、IP访问列表和basic auth
+func TestNginxAccessTemplate(t *testing.T) {
+	data := nginxData("dev.example.com", "8888")
+	data.Locations = []Location{
+		{Path: "/api/", Proxy: "9001", Rate: "100r/m"},
+		{Path: "/", Rate: "10r/s", Burst: 20, Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.5"}, Auth: true},
+	}
+	if !data.AuthEnabled() || data.HtpasswdConfigFile() != "config/dev.example.com.htpasswd" {
+		t.Errorf("basic auth设置错误")
+	}
+
+	for _, name := range []string{NginxHTTPTemplate, NginxHTTPSTemplate} {
+		data.TLS = nil
+		if name == NginxHTTPSTemplate {
+			data.TLS = &TLS{}
+		}
+		formatted := render(t, name, data)
+		for _, want := range []string{
+			"limit_req_zone $binary_remote_addr zone=dev_example_com_limit1:10m rate=100r/m; # /api/\n",
+			"limit_req_zone $binary_remote_addr zone=dev_example_com_limit2:10m rate=10r/s; # /\n",
+			"    location /api/ {\n        limit_req zone=dev_example_com_limit1;\n        limit_req_status 429;\n\n        proxy_pass http://localhost:9001;",
+			"    location / {\n        limit_req zone=dev_example_com_limit2 burst=20 nodelay;\n        limit_req_status 429;\n" +
+				"        deny 10.0.0.5;\n        allow 10.0.0.0/8;\n        deny all;\n" +
+				"        auth_basic \"Restricted\";\n        auth_basic_user_file /etc/nginx/htpasswd/dev.example.com;\n\n        proxy_pass",
+		} {
+			if !strings.Contains(formatted, want) {
+				t.Errorf("%s 应该包含 %q:\n%s", name, want, formatted)
+			}
+		}
+	}
+
+	// htpasswd文件和brotli配置片段的路径使用指定的nginx根目录
+	data.NginxRoot = "/opt/nginx/"
+	if formatted := render(t, NginxHTTPTemplate, data); !strings.Contains(formatted, "auth_basic_user_file /opt/nginx/htpasswd/dev.example.com;\n") {
+		t.Errorf("htpasswd文件应该位于nginx根目录下:\n%s", formatted)
+	}
+	if include := data.BrotliInclude(); include != "/opt/nginx/snippets/aigo-brotli*.conf" {
+		t.Errorf("brotli配置片段应该位于nginx根目录下, 实际得到 %s", include)
+	}
+	data.NginxRoot = ""
+
+	data.Locations = nil
+	if formatted := render(t, NginxHTTPTemplate, data); strings.Contains(formatted, "limit_req") || strings.Contains(formatted, "auth_basic") {
+		t.Errorf("未配置访问控制时不应该生成相关指令:\n%s", formatted)
+	}
+	script := render(t, NginxSetupScriptTemplate, data)
+	if !strings.Contains(script, `install -m 640 "$HTPASSWD_FILE" "/etc/nginx/htpasswd/$DOMAIN"`) {
+		t.Errorf("配置脚本应该安装htpasswd文件:\n%s", script)
+	}
+}
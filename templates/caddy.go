@@ -0,0 +1,334 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CaddyTemplate Caddyfile站点配置模板
+const CaddyTemplate = "caddy/Caddyfile.tmpl"
+
+// CaddySetupScriptTemplate Caddy配置脚本模板
+const CaddySetupScriptTemplate = "caddy/setup-caddy.sh.tmpl"
+
+// caddyBalance nginx负载均衡方式对应的Caddy lb_policy
+var caddyBalance = map[string]string{
+	"":                "round_robin",
+	BalanceRoundRobin: "round_robin",
+	BalanceLeastConn:  "least_conn",
+	BalanceIPHash:     "ip_hash",
+}
+
+// caddyPlaceholders 请求头中常用的nginx变量对应的Caddy占位符
+var caddyPlaceholders = strings.NewReplacer(
+	"$host", "{host}",
+	"$remote_addr", "{remote_host}",
+	"$scheme", "{scheme}",
+	"$request_uri", "{uri}",
+)
+
+// CaddyRoute Caddyfile中的一个handle块
+type CaddyRoute struct {
+	Directive string // handle 或 handle_path（去掉路径前缀）
+	Matcher   string // 路径匹配，/ 为空表示匹配其余全部请求
+	Deny      string // 以空格分隔的IP或CIDR
+	Allow     string
+	Auth      bool
+	Static    bool
+	Root      string
+	Index     string
+	TryFiles  string
+	Upstreams string // 以空格分隔的上游地址
+	Proxy     CaddyProxy
+}
+
+// CaddyProxy reverse_proxy的参数，零值表示全部使用Caddy默认值
+type CaddyProxy struct {
+	Policy      string
+	MaxFails    int
+	FailTimeout string
+	Headers     []Header
+	Keepalive   int
+	DialTimeout string
+	ReadTimeout string
+	SendTimeout string
+}
+
+// Options 判断reverse_proxy是否需要参数块
+func (p CaddyProxy) Options() bool {
+	return p.Policy != "" || p.MaxFails > 0 || p.FailTimeout != "" || len(p.Headers) > 0 || p.Transport()
+}
+
+// Transport 判断是否需要transport http块
+func (p CaddyProxy) Transport() bool {
+	return p.Keepalive > 0 || p.DialTimeout != "" || p.ReadTimeout != "" || p.SendTimeout != ""
+}
+
+// CaddyCache Caddyfile中按扩展名设置Cache-Control的规则
+type CaddyCache struct {
+	Name         string
+	Paths        string
+	CacheControl string
+}
+
+// CaddyAddress 返回站点地址，未启用TLS时使用 http:// 关闭自动HTTPS
+func (d Data) CaddyAddress() string {
+	if d.TLS == nil {
+		return "http://" + d.Domain
+	}
+	return d.Domain
+}
+
+// CaddyCertificate 返回自定义证书的 tls 参数，为空时使用Caddy自动申请的证书
+func (d Data) CaddyCertificate() string {
+	if d.TLS == nil || d.TLS.Cert == "" {
+		return ""
+	}
+	return d.TLS.Cert + " " + d.TLS.Key
+}
+
+// CaddyEncodings 返回encode指令的压缩算法，Caddy内置zstd和gzip，不支持brotli
+func (d Data) CaddyEncodings() string {
+	if d.Compression == nil || !d.Compression.Gzip && !d.Compression.Brotli {
+		return ""
+	}
+	return "zstd gzip"
+}
+
+// CaddyCacheRules 返回按扩展名设置缓存的规则，off表示不设置
+func (d Data) CaddyCacheRules() []CaddyCache {
+	var rules []CaddyCache
+	for _, r := range d.Cache {
+		if r.Expires == "off" {
+			continue
+		}
+		paths := make([]string, 0, len(r.Extensions))
+		for _, ext := range r.Extensions {
+			paths = append(paths, "*."+ext)
+		}
+		rules = append(rules, CaddyCache{
+			Name:         "cache" + strconv.Itoa(len(rules)+1),
+			Paths:        strings.Join(paths, " "),
+			CacheControl: r.CacheControl(),
+		})
+	}
+	return rules
+}
+
+// CacheControl 返回与nginx expires指令相同的Cache-Control头
+func (r CacheRule) CacheControl() string {
+	switch r.Expires {
+	case "epoch":
+		return "no-cache"
+	case "max":
+		return "max-age=315360000"
+	}
+	return "max-age=" + strconv.Itoa(nginxSeconds(r.Expires))
+}
+
+// nginxSeconds 将nginx时间参数转换为秒，不带单位时为秒
+func nginxSeconds(value string) int {
+	units := map[string]int{"ms": 0, "s": 1, "m": 60, "h": 3600, "d": 86400}
+	number := strings.TrimRight(value, "smhd")
+	n, _ := strconv.Atoi(number)
+	if unit := value[len(number):]; unit != "" {
+		if unit == "ms" {
+			return n / 1000
+		}
+		return n * units[unit]
+	}
+	return n
+}
+
+// caddyDuration 将nginx时间参数转换为Caddy的时长，不带单位时为秒
+func caddyDuration(value string) string {
+	if value == "" || !isDigits(value) {
+		return value
+	}
+	return value + "s"
+}
+
+// caddyUpstream 将代理目标转换为reverse_proxy的上游地址，Caddy的上游地址不能带路径
+func caddyUpstream(target string) (string, error) {
+	if isDigits(target) {
+		return "localhost:" + target, nil
+	}
+	if rest, ok := strings.CutPrefix(target, "unix:"); ok {
+		return "unix/" + rest, nil
+	}
+	scheme, rest, ok := strings.Cut(target, "://")
+	if !ok {
+		return target, nil
+	}
+	if strings.Contains(rest, "/") {
+		return "", fmt.Errorf("Caddy的reverse_proxy不支持带路径的代理目标: %s", target)
+	}
+	if scheme == "https" {
+		return target, nil
+	}
+	return rest, nil
+}
+
+// CaddyRoutes 返回站点中的全部handle块，与nginx的location一一对应
+func (d Data) CaddyRoutes() ([]CaddyRoute, error) {
+	var routes []CaddyRoute
+	for _, l := range d.siteLocations() {
+		route := CaddyRoute{
+			Directive: "handle",
+			Deny:      strings.Join(l.Deny, " "),
+			Allow:     strings.Join(l.Allow, " "),
+			Auth:      l.Auth,
+		}
+		// alias与handle_path相同，都是去掉路径前缀后在目录中查找文件
+		if l.StripPrefix || l.Alias != "" {
+			route.Directive = "handle_path"
+		}
+
+		if l.Static() {
+			block := staticBlock(l)
+			route.Static = true
+			route.Root = l.Root + l.Alias
+			route.Index = block.Index
+			route.TryFiles = "{path} {path}/"
+			if l.SPA {
+				fallback := strings.TrimRight(l.Path, "/") + "/" + block.Index
+				if l.Alias != "" {
+					fallback = "/" + block.Index
+				}
+				route.TryFiles += " " + fallback
+			}
+			routes = append(routes, caddyMatch(route, l.Path)...)
+			continue
+		}
+
+		route.Proxy = d.caddyProxy(l)
+		if l.Proxy != "" {
+			target, err := proxyTarget(l.Proxy)
+			if err != nil {
+				return nil, err
+			}
+			upstream, err := caddyUpstream(target)
+			if err != nil {
+				return nil, err
+			}
+			route.Upstreams = upstream
+		} else {
+			upstreams, err := d.caddyUpstreams()
+			if err != nil {
+				return nil, err
+			}
+			route.Upstreams = strings.Join(upstreams, " ")
+		}
+		routes = append(routes, caddyMatch(route, l.Path)...)
+	}
+	return routes, nil
+}
+
+// caddyMatch 为路由设置路径匹配: /api 拆成 /api 和 /api/* 两个handle块，不匹配 /apifoo；/ 匹配全部请求
+func caddyMatch(route CaddyRoute, path string) []CaddyRoute {
+	switch {
+	case path == "/":
+		return []CaddyRoute{route}
+	case strings.HasSuffix(path, "/"):
+		route.Matcher = path + "*"
+		return []CaddyRoute{route}
+	}
+	exact, prefix := route, route
+	exact.Matcher = path
+	prefix.Matcher = path + "/*"
+	return []CaddyRoute{exact, prefix}
+}
+
+// caddyProxy 返回一个路由的reverse_proxy参数，未指定代理目标时使用upstream的负载均衡设置
+func (d Data) caddyProxy(l Location) CaddyProxy {
+	proxy := CaddyProxy{
+		DialTimeout: caddyDuration(l.ConnectTimeout),
+		ReadTimeout: caddyDuration(l.ReadTimeout),
+		SendTimeout: caddyDuration(l.SendTimeout),
+	}
+	for _, h := range sortedHeaders(l.Headers) {
+		proxy.Headers = append(proxy.Headers, Header{h.Name, caddyPlaceholders.Replace(h.Value)})
+	}
+
+	if l.Proxy != "" || d.Upstream == nil {
+		return proxy
+	}
+	proxy.Policy = caddyBalance[d.Upstream.Method]
+	proxy.Keepalive = d.Upstream.Keepalive
+	var weights []string
+	for _, s := range d.Upstream.Servers {
+		weights = append(weights, strconv.Itoa(max(s.Weight, 1)))
+		// Caddy的被动健康检查作用于整个reverse_proxy，使用第一个设置了参数的后端
+		if s.MaxFails != nil && proxy.MaxFails == 0 {
+			proxy.MaxFails = *s.MaxFails
+		}
+		if s.FailTimeout != "" && proxy.FailTimeout == "" {
+			proxy.FailTimeout = caddyDuration(s.FailTimeout)
+		}
+	}
+	if proxy.Policy == "round_robin" && d.upstreamWeighted() {
+		proxy.Policy = "weighted_round_robin " + strings.Join(weights, " ")
+	}
+	if proxy.MaxFails > 0 && proxy.FailTimeout == "" {
+		proxy.FailTimeout = "10s"
+	}
+	return proxy
+}
+
+// caddyUpstreams 返回默认后端的上游地址
+func (d Data) caddyUpstreams() ([]string, error) {
+	if d.Upstream == nil {
+		return []string{"localhost:" + d.Port}, nil
+	}
+	var upstreams []string
+	for _, s := range d.Upstream.Servers {
+		upstream, err := caddyUpstream(s.Addr)
+		if err != nil {
+			return nil, err
+		}
+		upstreams = append(upstreams, upstream)
+	}
+	return upstreams, nil
+}
+
+// upstreamWeighted 判断是否有后端设置了权重
+func (d Data) upstreamWeighted() bool {
+	if d.Upstream == nil {
+		return false
+	}
+	for _, s := range d.Upstream.Servers {
+		if s.Weight > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// siteLocations 返回站点的全部路由，未自定义 / 时追加转发到默认后端的 /
+func (d Data) siteLocations() []Location {
+	locations := append([]Location{}, d.Locations...)
+	for _, l := range locations {
+		if l.Path == "/" {
+			return locations
+		}
+	}
+	return append(locations, Location{Path: "/", WebSocket: true})
+}
+
+// CaddyUnsupported 返回Caddy核心不支持、生成时被忽略的设置
+func (d Data) CaddyUnsupported() []string {
+	var ignored []string
+	if d.Compression != nil && d.Compression.Brotli {
+		ignored = append(ignored, "brotli压缩（Caddy内置zstd和gzip）")
+	}
+	for _, l := range d.Locations {
+		if l.Rate != "" {
+			ignored = append(ignored, fmt.Sprintf("路由 %s 的 rate（需要caddy-ratelimit插件）", l.Path))
+		}
+	}
+	if d.Upstream != nil && d.Upstream.Method != "" && d.Upstream.Method != BalanceRoundRobin && d.upstreamWeighted() {
+		ignored = append(ignored, "weight（只有round_robin支持权重）")
+	}
+	return ignored
+}
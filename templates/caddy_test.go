@@ -0,0 +1,109 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCaddyTemplate 测试使用与nginx相同的站点配置渲染Caddyfile
+func TestCaddyTemplate(t *testing.T) {
+	data := nginxData("dev.example.com", "8888")
+	formatted := render(t, CaddyTemplate, data)
+	for _, want := range []string{
+		"http://dev.example.com {\n",
+		"\thandle {\n\t\treverse_proxy localhost:8888\n\t}\n",
+		"output file /var/log/caddy/dev.example.com.access.log",
+	} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("Caddyfile应该包含 %q:\n%s", want, formatted)
+		}
+	}
+
+	max := 3
+	data.TLS = &TLS{Cert: "/etc/ssl/dev.pem", Key: "/etc/ssl/dev.key"}
+	data.Upstream = &Upstream{Keepalive: 16, Servers: []UpstreamServer{{Addr: "10.0.0.1:8080", Weight: 2, MaxFails: &max}, {Addr: "10.0.0.2:8080"}}}
+	data.Locations = []Location{
+		{Path: "/api/", Proxy: "9001", StripPrefix: true, ReadTimeout: "60", Headers: map[string]string{"X-Real-IP": "$remote_addr"}},
+		{Path: "/assets/", Alias: "/srv/assets/", SPA: true},
+		{Path: "/admin/", Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.5"}, Auth: true},
+	}
+	data.Compression = &Compression{Gzip: true}
+	data.Cache = []CacheRule{{Extensions: []string{"js", "css"}, Expires: "30d"}, {Extensions: []string{"html"}, Expires: "off"}}
+	data.Credentials = []Credential{{User: "alice", Hash: "$2a$10$hash"}}
+	formatted = render(t, CaddyTemplate, data)
+	for _, want := range []string{
+		"dev.example.com {\n\ttls /etc/ssl/dev.pem /etc/ssl/dev.key\n",
+		"\tencode zstd gzip\n",
+		"\t@cache1 path *.js *.css\n\theader @cache1 Cache-Control \"max-age=2592000\"\n",
+		"\thandle_path /api/* {\n\t\treverse_proxy localhost:9001 {\n\t\t\theader_up X-Real-IP {remote_host}\n\t\t\ttransport http {\n\t\t\t\tread_timeout 60s\n",
+		"\thandle_path /assets/* {\n\t\troot * /srv/assets/\n\t\ttry_files {path} {path}/ /index.html\n",
+		"\t\t@denied remote_ip 10.0.0.5\n\t\trespond @denied 403\n\t\t@not_allowed not remote_ip 10.0.0.0/8\n",
+		"\t\tbasic_auth {\n\t\t\talice $2a$10$hash\n\t\t}\n",
+		"\t\treverse_proxy 10.0.0.1:8080 10.0.0.2:8080 {\n\t\t\tlb_policy weighted_round_robin 2 1\n\t\t\tfail_duration 10s\n\t\t\tmax_fails 3\n",
+		"keepalive_idle_conns_per_host 16",
+	} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("Caddyfile应该包含 %q:\n%s", want, formatted)
+		}
+	}
+	if strings.Contains(formatted, "*.html") {
+		t.Errorf("expires off 不应该生成缓存规则:\n%s", formatted)
+	}
+
+	data.Locations = []Location{{Path: "/api/", Proxy: "http://localhost:9001/v1/"}}
+	if _, err := data.CaddyRoutes(); err == nil {
+		t.Error("带路径的代理目标应该返回错误")
+	}
+}
+
+// TestCaddyStripPrefixMatch 测试去掉前缀的路由只匹配前缀本身和它下面的路径，与TestNginxStripPrefixRewrite对应
+func TestCaddyStripPrefixMatch(t *testing.T) {
+	data := nginxData("dev.example.com", "8888")
+	data.Locations = []Location{{Path: "/api", Proxy: "9001", StripPrefix: true}}
+	routes, err := data.CaddyRoutes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按Caddy的规则匹配handle_path的路径并去掉前缀，没有匹配时由 / 路由原样转发
+	forward := func(uri string) string {
+		for _, r := range routes {
+			prefix, wildcard := strings.CutSuffix(r.Matcher, "*")
+			if r.Matcher == "" || (!wildcard && uri != r.Matcher) || (wildcard && !strings.HasPrefix(uri, prefix)) {
+				continue
+			}
+			rest := strings.TrimPrefix(uri, strings.TrimSuffix(prefix, "/"))
+			if !strings.HasPrefix(rest, "/") {
+				rest = "/" + rest
+			}
+			return rest
+		}
+		return uri
+	}
+	for uri, want := range map[string]string{"/api": "/", "/api/": "/", "/api/users/1": "/users/1", "/apifoo": "/apifoo"} {
+		if got := forward(uri); got != want {
+			t.Errorf("%s 应该转发为 %s, 实际得到 %s", uri, want, got)
+		}
+	}
+	formatted := render(t, CaddyTemplate, data)
+	if !strings.Contains(formatted, "\thandle_path /api {\n") || !strings.Contains(formatted, "\thandle_path /api/* {\n") {
+		t.Errorf("/api 应该拆成 /api 和 /api/* 两个handle_path:\n%s", formatted)
+	}
+}
+
+// TestCaddyUnsupported 测试Caddy核心不支持的设置
+func TestCaddyUnsupported(t *testing.T) {
+	data := nginxData("dev.example.com", "8888")
+	if ignored := data.CaddyUnsupported(); len(ignored) != 0 {
+		t.Errorf("默认配置不应该有被忽略的设置: %v", ignored)
+	}
+	data.Compression = &Compression{Brotli: true}
+	data.Locations = []Location{{Path: "/", Rate: "10r/s"}}
+	if ignored := data.CaddyUnsupported(); len(ignored) != 2 {
+		t.Errorf("brotli和rate应该被忽略: %v", ignored)
+	}
+	for expires, want := range map[string]string{"30d": "max-age=2592000", "1h": "max-age=3600", "epoch": "no-cache", "max": "max-age=315360000"} {
+		if got := (CacheRule{Expires: expires}).CacheControl(); got != want {
+			t.Errorf("%s 的Cache-Control应该是 %s, 实际得到 %s", expires, want, got)
+		}
+	}
+}
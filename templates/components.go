@@ -0,0 +1,15 @@
+package templates
+
+// add命令使用的组件模板名称
+const (
+	// MakefileTemplate 常用构建命令的Makefile模板
+	MakefileTemplate = "components/Makefile.tmpl"
+	// SystemdTemplate systemd服务单元模板
+	SystemdTemplate = "components/systemd.service.tmpl"
+	// CITemplate GitHub Actions持续集成工作流模板
+	CITemplate = "components/ci.yml.tmpl"
+	// HealthTemplate 健康检查包模板
+	HealthTemplate = "components/health.go.tmpl"
+	// HealthScriptTemplate 健康检查探测脚本模板
+	HealthScriptTemplate = "components/healthcheck.sh.tmpl"
+)
@@ -0,0 +1,46 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMakefileTemplate 测试Makefile模板使用tab缩进并按需生成docker目标
+func TestMakefileTemplate(t *testing.T) {
+	data := NewData("demo")
+	formatted := render(t, MakefileTemplate, data)
+	if !strings.Contains(formatted, "build:\n\tgo build -o bin/$(APP) .") {
+		t.Errorf("Makefile模板应该包含build目标:\n%s", formatted)
+	}
+	if strings.Contains(formatted, "docker") {
+		t.Error("未启用Docker时不应该生成docker目标")
+	}
+
+	data.Docker = true
+	if !strings.Contains(render(t, MakefileTemplate, data), "docker build -t $(APP) .") {
+		t.Error("启用Docker时应该生成docker目标")
+	}
+}
+
+// TestSystemdTemplate 测试systemd服务单元模板
+func TestSystemdTemplate(t *testing.T) {
+	data := NewData("demo")
+	data.Port = "9000"
+	formatted := render(t, SystemdTemplate, data)
+	for _, want := range []string{"ExecStart=/opt/demo/demo", "Environment=PORT=9000", "WantedBy=multi-user.target"} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("systemd模板应该包含 %q", want)
+		}
+	}
+}
+
+// TestHealthTemplate 测试健康检查模板
+func TestHealthTemplate(t *testing.T) {
+	data := NewData("demo")
+	if !strings.Contains(render(t, HealthTemplate, data), "func Handler() http.Handler") {
+		t.Error("健康检查包应该提供Handler")
+	}
+	if !strings.Contains(render(t, HealthScriptTemplate, data), "${PORT:-8888}") {
+		t.Error("探测脚本应该默认使用项目端口")
+	}
+}
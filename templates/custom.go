@@ -0,0 +1,173 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CustomManifestFile 自定义模板的清单文件名
+const CustomManifestFile = "template.yaml"
+
+// Prompt 自定义模板在生成前向用户询问的变量
+type Prompt struct {
+	Name    string   `yaml:"name"`
+	Message string   `yaml:"message"`
+	Default string   `yaml:"default"`
+	Choices []string `yaml:"choices"`
+}
+
+// Hooks 自定义模板的生成钩子
+type Hooks struct {
+	PostGenerate []string `yaml:"post_generate"`
+}
+
+// CustomManifest template.yaml 清单内容
+type CustomManifest struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Prompts     []Prompt          `yaml:"prompts"`
+	Defaults    map[string]string `yaml:"defaults"`
+	Hooks       Hooks             `yaml:"hooks"`
+}
+
+// File 渲染后的项目文件
+type File struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+}
+
+// Custom 用户自定义的项目模板目录
+type Custom struct {
+	Dir      string
+	Manifest CustomManifest
+}
+
+// LoadCustom 加载自定义模板目录，template.yaml 可选
+func LoadCustom(dir string) (*Custom, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("模板目录不可用: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s 不是目录", dir)
+	}
+
+	custom := &Custom{Dir: dir}
+	content, err := os.ReadFile(filepath.Join(dir, CustomManifestFile))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, fmt.Errorf("读取 %s 失败: %v", CustomManifestFile, err)
+	default:
+		if err := yaml.Unmarshal(content, &custom.Manifest); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %v", CustomManifestFile, err)
+		}
+	}
+
+	if custom.Manifest.Name == "" {
+		custom.Manifest.Name = filepath.Base(dir)
+	}
+	for _, prompt := range custom.Manifest.Prompts {
+		if prompt.Name == "" {
+			return nil, fmt.Errorf("%s 中的prompt缺少name", CustomManifestFile)
+		}
+	}
+	return custom, nil
+}
+
+// ApplyDefaults 将清单中的默认值写入模板数据，内置字段使用下划线命名
+func (c *Custom) ApplyDefaults(data *Data) {
+	for key, value := range c.Manifest.Defaults {
+		if !data.Set(key, value) {
+			data.Vars[key] = value
+		}
+	}
+	for _, prompt := range c.Manifest.Prompts {
+		if _, ok := data.Vars[prompt.Name]; !ok {
+			data.Vars[prompt.Name] = prompt.Default
+		}
+	}
+}
+
+// Render 渲染整个模板目录：.tmpl 文件渲染后去掉后缀，其余文件原样复制，路径中的模板表达式同样会被渲染
+func (c *Custom) Render(data Data) ([]File, error) {
+	var out []File
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(c.Dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == CustomManifestFile {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		target, err := renderString(filepath.ToSlash(rel), filepath.ToSlash(rel), data)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(target, ".tmpl") {
+			target = strings.TrimSuffix(target, ".tmpl")
+			rendered, err := renderString(rel, string(content), data)
+			if err != nil {
+				return err
+			}
+			content = []byte(rendered)
+		}
+
+		out = append(out, File{
+			Path:    filepath.FromSlash(target),
+			Content: content,
+			Mode:    info.Mode().Perm(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("渲染模板 %s 失败: %v", c.Manifest.Name, err)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// renderString 渲染单个模板字符串
+func renderString(name, text string, data Data) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
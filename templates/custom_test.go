@@ -0,0 +1,133 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCustom 在临时目录中写入自定义模板文件
+func writeCustom(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestLoadCustomWithoutManifest 测试没有template.yaml的模板目录
+func TestLoadCustomWithoutManifest(t *testing.T) {
+	dir := writeCustom(t, map[string]string{"main.go": "package main\n"})
+
+	custom, err := LoadCustom(dir)
+	if err != nil {
+		t.Fatalf("加载模板失败: %v", err)
+	}
+	if custom.Manifest.Name != filepath.Base(dir) {
+		t.Errorf("模板名称应默认为目录名, 实际 %s", custom.Manifest.Name)
+	}
+}
+
+// TestLoadCustomInvalid 测试无效的模板目录和清单
+func TestLoadCustomInvalid(t *testing.T) {
+	if _, err := LoadCustom(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("不存在的目录应该返回错误")
+	}
+
+	dir := writeCustom(t, map[string]string{CustomManifestFile: "prompts:\n  - message: 没有名称\n"})
+	if _, err := LoadCustom(dir); err == nil {
+		t.Error("缺少name的prompt应该返回错误")
+	}
+
+	dir = writeCustom(t, map[string]string{CustomManifestFile: "prompts: [\n"})
+	if _, err := LoadCustom(dir); err == nil {
+		t.Error("无效的yaml应该返回错误")
+	}
+}
+
+// TestCustomRender 测试渲染自定义模板
+func TestCustomRender(t *testing.T) {
+	dir := writeCustom(t, map[string]string{
+		CustomManifestFile: `name: api
+description: 示例模板
+prompts:
+  - name: db
+    message: 数据库
+    default: sqlite
+    choices: [sqlite, postgres]
+defaults:
+  port: "9000"
+  author: tester
+hooks:
+  post_generate:
+    - go mod tidy
+`,
+		"go.mod.tmpl":                   "module {{.ModulePath}}\n",
+		"main.go.tmpl":                  "// port={{.Port}} db={{.Vars.db}} author={{.Vars.author}}\n",
+		"static/logo.txt":               "{{ not rendered }}",
+		"cmd/{{.ProjectName}}/run.tmpl": "{{.ProjectName}}",
+		".git/HEAD":                     "ref: refs/heads/main",
+	})
+
+	custom, err := LoadCustom(dir)
+	if err != nil {
+		t.Fatalf("加载模板失败: %v", err)
+	}
+	if custom.Manifest.Name != "api" || len(custom.Manifest.Hooks.PostGenerate) != 1 {
+		t.Fatalf("清单解析错误: %+v", custom.Manifest)
+	}
+
+	data := NewData("demo")
+	custom.ApplyDefaults(&data)
+	if data.Port != "9000" {
+		t.Errorf("defaults中的port应写入内置字段, 实际 %s", data.Port)
+	}
+	if data.Vars["db"] != "sqlite" || data.Vars["author"] != "tester" {
+		t.Errorf("变量默认值错误: %v", data.Vars)
+	}
+
+	files, err := custom.Render(data)
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, file := range files {
+		got[filepath.ToSlash(file.Path)] = string(file.Content)
+	}
+	want := map[string]string{
+		"go.mod":          "module demo\n",
+		"main.go":         "// port=9000 db=sqlite author=tester\n",
+		"static/logo.txt": "{{ not rendered }}",
+		"cmd/demo/run":    "demo",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("渲染文件列表错误: %v", got)
+	}
+	for path, content := range want {
+		if got[path] != content {
+			t.Errorf("%s 内容错误: %q", path, got[path])
+		}
+	}
+}
+
+// TestCustomRenderMissingVar 测试引用未定义变量时报错
+func TestCustomRenderMissingVar(t *testing.T) {
+	dir := writeCustom(t, map[string]string{"a.txt.tmpl": "{{.Vars.missing}}"})
+	custom, err := LoadCustom(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = custom.Render(NewData("demo"))
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("未定义变量应该返回错误, 实际 %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLookupDatabase 测试按名称查找数据库
+func TestLookupDatabase(t *testing.T) {
+	for name, want := range map[string]string{"postgres": "postgres", "PostgreSQL": "postgres", "mysql": "mysql", "sqlite": "sqlite"} {
+		db, err := LookupDatabase(name)
+		if err != nil || db.Name != want {
+			t.Errorf("LookupDatabase(%q) = %q, %v; 期望 %q", name, db.Name, err, want)
+		}
+	}
+	if _, err := LookupDatabase("oracle"); err == nil {
+		t.Error("不支持的数据库应该返回错误")
+	}
+}
+
+// TestDatabaseTemplates 测试数据库连接文件、go.mod依赖和docker-compose服务
+func TestDatabaseTemplates(t *testing.T) {
+	for _, db := range Databases {
+		t.Run(db.Name, func(t *testing.T) {
+			data := NewData("demo")
+			data.Database = &db
+			data.Docker = true
+
+			code := render(t, DatabaseTemplate, data)
+			if !strings.Contains(code, `_ "`+db.Import+`"`) || !strings.Contains(code, `sql.Open("`+db.DriverName+`"`) {
+				t.Errorf("db.go 应导入并使用 %s 驱动:\n%s", db.Name, code)
+			}
+
+			if goMod := render(t, GoModTemplate, data); !strings.Contains(goMod, db.Driver.Path+" "+db.Driver.Version) {
+				t.Errorf("go.mod 应包含驱动依赖:\n%s", goMod)
+			}
+
+			compose := render(t, DockerComposeTemplate, data)
+			if !strings.Contains(compose, "DATABASE_URL") {
+				t.Errorf("docker-compose.yml 应设置 DATABASE_URL:\n%s", compose)
+			}
+			if db.Image != "" && !strings.Contains(compose, "image: "+db.Image) {
+				t.Errorf("docker-compose.yml 应包含 %s 服务", db.Image)
+			}
+
+			if readme := render(t, ReadmeTemplate, data); !strings.Contains(readme, db.Title) {
+				t.Error("README 应包含数据库说明")
+			}
+		})
+	}
+}
+
+// TestDockerTemplates 测试Dockerfile使用项目名称、Go版本和端口，.dockerignore排除.aigo目录
+func TestDockerTemplates(t *testing.T) {
+	data := NewData("demo")
+	data.Port = "9000"
+
+	dockerfile := render(t, DockerfileTemplate, data)
+	for _, want := range []string{"FROM golang:1.24-alpine", "EXPOSE 9000", "/app/demo"} {
+		if !strings.Contains(dockerfile, want) {
+			t.Errorf("Dockerfile 应包含 %q", want)
+		}
+	}
+
+	compose := render(t, DockerComposeTemplate, data)
+	if !strings.Contains(compose, `"9000:9000"`) || strings.Contains(compose, "DATABASE_URL") {
+		t.Errorf("不使用数据库时docker-compose.yml错误:\n%s", compose)
+	}
+	// .aigo中的备份和生成记录不进入镜像
+	if dockerignore := render(t, DockerignoreTemplate, data); !strings.Contains(dockerignore, ".aigo/\n") {
+		t.Errorf(".dockerignore 应包含 .aigo/:\n%s", dockerignore)
+	}
+}
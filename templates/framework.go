@@ -0,0 +1,95 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Module go.mod中的依赖
+type Module struct {
+	Path    string
+	Version string
+}
+
+// Framework 生成项目可选的HTTP框架
+type Framework struct {
+	Name    string   // 命令行中使用的名称
+	Title   string   // 展示名称
+	Docs    string   // 官方文档地址
+	Require []Module // go.mod依赖，标准库为空
+}
+
+// DefaultFramework 默认使用的框架
+const DefaultFramework = "gin"
+
+// Frameworks 支持的框架列表，main.go与README中的框架说明按Name选择对应模板
+var Frameworks = []Framework{
+	{
+		Name:    "gin",
+		Title:   "Gin",
+		Docs:    "https://gin-gonic.com/docs/",
+		Require: []Module{{"github.com/gin-gonic/gin", "v1.10.1"}},
+	},
+	{
+		Name:    "echo",
+		Title:   "Echo",
+		Docs:    "https://echo.labstack.com/docs",
+		Require: []Module{{"github.com/labstack/echo/v4", "v4.12.0"}},
+	},
+	{
+		Name:    "chi",
+		Title:   "chi",
+		Docs:    "https://go-chi.io/",
+		Require: []Module{{"github.com/go-chi/chi/v5", "v5.1.0"}},
+	},
+	{
+		Name:    "fiber",
+		Title:   "Fiber",
+		Docs:    "https://docs.gofiber.io/",
+		Require: []Module{{"github.com/gofiber/fiber/v2", "v2.52.5"}},
+	},
+	{
+		Name:  "nethttp",
+		Title: "net/http",
+		Docs:  "https://pkg.go.dev/net/http",
+	},
+}
+
+// frameworkAliases 框架名称的别名
+var frameworkAliases = map[string]string{
+	"net/http": "nethttp",
+	"stdlib":   "nethttp",
+	"std":      "nethttp",
+}
+
+// LookupFramework 按名称查找框架，名称不区分大小写
+func LookupFramework(name string) (Framework, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := frameworkAliases[name]; ok {
+		name = alias
+	}
+	for _, fw := range Frameworks {
+		if fw.Name == name {
+			return fw, nil
+		}
+	}
+	return Framework{}, fmt.Errorf("不支持的框架: %s（可选: %s）", name, strings.Join(FrameworkNames(), ", "))
+}
+
+// FrameworkNames 返回所有框架名称
+func FrameworkNames() []string {
+	names := make([]string, 0, len(Frameworks))
+	for _, fw := range Frameworks {
+		names = append(names, fw.Name)
+	}
+	return names
+}
+
+// mustFramework 查找内置框架，仅用于默认值
+func mustFramework(name string) Framework {
+	fw, err := LookupFramework(name)
+	if err != nil {
+		panic(err)
+	}
+	return fw
+}
@@ -0,0 +1,89 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLookupFramework 测试按名称和别名查找框架
+func TestLookupFramework(t *testing.T) {
+	cases := map[string]string{
+		"gin":      "gin",
+		"Echo":     "echo",
+		" chi ":    "chi",
+		"fiber":    "fiber",
+		"nethttp":  "nethttp",
+		"net/http": "nethttp",
+		"stdlib":   "nethttp",
+	}
+	for name, want := range cases {
+		fw, err := LookupFramework(name)
+		if err != nil || fw.Name != want {
+			t.Errorf("LookupFramework(%q) = %q, %v; 期望 %q", name, fw.Name, err, want)
+		}
+	}
+
+	if _, err := LookupFramework("beego"); err == nil || !strings.Contains(err.Error(), "gin, echo") {
+		t.Errorf("不支持的框架应返回包含可选值的错误, 实际 %v", err)
+	}
+}
+
+// TestFrameworkTemplates 测试每个框架的go.mod、main.go和README
+func TestFrameworkTemplates(t *testing.T) {
+	routes := map[string][]string{
+		"gin":     {`r.GET("/"`, `r.GET("/hello"`, `r.GET("/health"`, `r.Group("/api/v1")`, `api.POST("/users"`},
+		"echo":    {`e.GET("/"`, `e.GET("/hello"`, `e.GET("/health"`, `e.Group("/api/v1")`, `api.POST("/users"`},
+		"chi":     {`r.Get("/"`, `r.Get("/hello"`, `r.Get("/health"`, `r.Route("/api/v1"`, `api.Post("/users"`},
+		"fiber":   {`app.Get("/"`, `app.Get("/hello"`, `app.Get("/health"`, `app.Group("/api/v1")`, `api.Post("/users"`},
+		"nethttp": {`"GET /{$}"`, `"GET /hello"`, `"GET /health"`, `"GET /api/v1/users"`, `"POST /api/v1/users"`},
+	}
+
+	for _, fw := range Frameworks {
+		t.Run(fw.Name, func(t *testing.T) {
+			data := NewData("demo")
+			data.Port = "9000"
+			if !data.Set("framework", fw.Name) {
+				t.Fatalf("Set(framework, %s) 应该成功", fw.Name)
+			}
+
+			goMod := render(t, GoModTemplate, data)
+			for _, m := range fw.Require {
+				if !strings.Contains(goMod, m.Path+" "+m.Version) {
+					t.Errorf("go.mod 应包含依赖 %s %s:\n%s", m.Path, m.Version, goMod)
+				}
+			}
+			if len(fw.Require) == 0 && strings.Contains(goMod, "require") {
+				t.Errorf("标准库项目的go.mod不应包含require:\n%s", goMod)
+			}
+
+			main := render(t, MainGoTemplate, data)
+			if !strings.HasPrefix(main, "package main\n") || !strings.HasSuffix(main, "}\n") {
+				t.Errorf("main.go 首尾格式错误:\n%s", main)
+			}
+			for _, route := range routes[fw.Name] {
+				if !strings.Contains(main, route) {
+					t.Errorf("main.go 应包含路由 %s", route)
+				}
+			}
+			if !strings.Contains(main, ":9000") {
+				t.Error("main.go 应使用指定端口")
+			}
+			if !strings.Contains(main, `os.Getenv("TLS_CERT_FILE")`) || !strings.Contains(main, "certFile, keyFile") {
+				t.Error("main.go 应在设置TLS_CERT_FILE时使用HTTPS")
+			}
+			if !strings.Contains(main, "listen(addr, certFile, keyFile)") || !strings.Contains(main, `os.Getenv("LISTEN_FDS")`) {
+				t.Error("main.go 应支持通过LISTEN_FDS继承监听socket")
+			}
+			for _, m := range fw.Require {
+				if !strings.Contains(main, `"`+m.Path) {
+					t.Errorf("main.go 应导入 %s", m.Path)
+				}
+			}
+
+			readme := render(t, ReadmeTemplate, data)
+			if !strings.Contains(readme, "["+fw.Title+"]("+fw.Docs+")") {
+				t.Errorf("README 应包含 %s 的框架说明", fw.Title)
+			}
+		})
+	}
+}
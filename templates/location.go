@@ -0,0 +1,386 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// defaultProxyTimeout 未单独设置时的代理超时
+const defaultProxyTimeout = "60s"
+
+// defaultIndex 静态目录的默认首页
+const defaultIndex = "index.html"
+
+// headerName HTTP头名称
+var headerName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Location 同一域名下按路径转发到不同服务的配置
+type Location struct {
+	Path           string            // 路径前缀，如 /api/
+	Proxy          string            // 代理目标: 端口、host:port 或 http(s):// 地址，为空时使用默认后端
+	StripPrefix    bool              // 转发前去掉路径前缀
+	WebSocket      bool              // 支持WebSocket升级
+	ConnectTimeout string            // proxy_connect_timeout，默认60s
+	ReadTimeout    string            // proxy_read_timeout，默认60s
+	SendTimeout    string            // proxy_send_timeout，默认60s
+	Headers        map[string]string // 额外的请求头，同名时覆盖默认请求头
+	Root           string            // 由nginx直接提供的静态目录，使用root指令（请求路径拼接在目录后）
+	Alias          string            // 由nginx直接提供的静态目录，使用alias指令（路径前缀替换为目录）
+	SPA            bool              // 文件不存在时回退到首页，用于单页应用
+	Index          string            // 静态目录的首页，默认index.html
+	Rate           string            // 每个客户端IP的请求速率限制，如 10r/s、60r/m
+	Burst          int               // 超过速率时允许突发的请求数，需要与Rate一起使用
+	Allow          []string          // 允许访问的IP或CIDR，指定后其余地址均被拒绝
+	Deny           []string          // 拒绝访问的IP或CIDR，在Allow之前匹配
+	Auth           bool              // 使用域名的htpasswd文件进行basic auth认证
+}
+
+// Static 判断是否由nginx直接提供静态文件
+func (l Location) Static() bool {
+	return l.Root != "" || l.Alias != ""
+}
+
+// ParseLocation 解析命令行中的路由，格式为 path[=target][,strip_prefix][,websocket][,timeout=T]
+// [,connect_timeout=T][,read_timeout=T][,send_timeout=T][,header=Name:Value]；
+// 静态目录使用 path,root=DIR 或 path,alias=DIR，可加 spa 和 index=FILE；
+// 访问控制使用 rate=R、burst=N、allow=CIDR、deny=CIDR（可重复）和 auth
+func ParseLocation(spec string) (Location, error) {
+	parts := strings.Split(spec, ",")
+	path, target, _ := strings.Cut(parts[0], "=")
+	location := Location{Path: strings.TrimSpace(path), Proxy: strings.TrimSpace(target)}
+
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "strip_prefix":
+			location.StripPrefix = true
+		case "websocket":
+			location.WebSocket = true
+		case "timeout":
+			location.ConnectTimeout, location.ReadTimeout, location.SendTimeout = value, value, value
+		case "connect_timeout":
+			location.ConnectTimeout = value
+		case "read_timeout":
+			location.ReadTimeout = value
+		case "send_timeout":
+			location.SendTimeout = value
+		case "root":
+			location.Root = value
+		case "alias":
+			location.Alias = value
+		case "spa":
+			location.SPA = true
+		case "index":
+			location.Index = value
+		case "rate":
+			location.Rate = value
+		case "burst":
+			burst, err := strconv.Atoi(value)
+			if err != nil {
+				return location, fmt.Errorf("burst 应为整数: %s", value)
+			}
+			location.Burst = burst
+		case "allow":
+			location.Allow = append(location.Allow, value)
+		case "deny":
+			location.Deny = append(location.Deny, value)
+		case "auth":
+			location.Auth = true
+		case "header":
+			name, headerValue, ok := strings.Cut(value, ":")
+			if !ok {
+				return location, fmt.Errorf("header 格式应为 Name:Value: %s", value)
+			}
+			if location.Headers == nil {
+				location.Headers = map[string]string{}
+			}
+			location.Headers[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+		default:
+			return location, fmt.Errorf("未知的路由参数: %s，可选 strip_prefix、websocket、timeout、connect_timeout、read_timeout、send_timeout、header、root、alias、spa、index、rate、burst、allow、deny、auth", key)
+		}
+	}
+	return location, location.Validate()
+}
+
+// Validate 检查路由配置
+func (l Location) Validate() error {
+	if !strings.HasPrefix(l.Path, "/") || strings.ContainsAny(l.Path, " \t;{}") {
+		return fmt.Errorf("路由路径应以 / 开头且不能包含空白、分号或花括号: %q", l.Path)
+	}
+	if l.StripPrefix && l.Path == "/" {
+		return fmt.Errorf("路由 / 没有可去掉的前缀")
+	}
+	if err := l.validateStatic(); err != nil {
+		return fmt.Errorf("路由 %s: %v", l.Path, err)
+	}
+	if err := l.validateAccess(); err != nil {
+		return fmt.Errorf("路由 %s: %v", l.Path, err)
+	}
+	if l.Proxy != "" && !isDigits(l.Proxy) {
+		if _, err := proxyTarget(l.Proxy); err != nil {
+			return fmt.Errorf("路由 %s: %v", l.Path, err)
+		}
+	}
+	for name, value := range map[string]string{
+		"connect_timeout": l.ConnectTimeout,
+		"read_timeout":    l.ReadTimeout,
+		"send_timeout":    l.SendTimeout,
+	} {
+		if value != "" && !nginxTime.MatchString(value) {
+			return fmt.Errorf("路由 %s 的 %s 应为nginx时间格式（如 10s、1m）: %s", l.Path, name, value)
+		}
+	}
+	for name, value := range l.Headers {
+		if !headerName.MatchString(name) || value == "" || strings.ContainsAny(value, "\"\n;{}") {
+			return fmt.Errorf("路由 %s 的请求头无效: %s: %s", l.Path, name, value)
+		}
+	}
+	return nil
+}
+
+// validateStatic 检查静态目录配置，静态路由不能同时设置代理参数
+func (l Location) validateStatic() error {
+	if !l.Static() {
+		if l.SPA || l.Index != "" {
+			return fmt.Errorf("spa 和 index 需要与 root 或 alias 一起使用")
+		}
+		return nil
+	}
+	if l.Root != "" && l.Alias != "" {
+		return fmt.Errorf("root 和 alias 不能同时指定")
+	}
+	dir := l.Root + l.Alias
+	if !strings.HasPrefix(dir, "/") || strings.ContainsAny(dir, " \t;{}") {
+		return fmt.Errorf("静态目录应为不含空白、分号或花括号的绝对路径: %q", dir)
+	}
+	if strings.ContainsAny(l.Index, " \t;{}/") {
+		return fmt.Errorf("无效的首页文件: %q", l.Index)
+	}
+	if l.Proxy != "" || l.StripPrefix || l.WebSocket || len(l.Headers) > 0 ||
+		l.ConnectTimeout != "" || l.ReadTimeout != "" || l.SendTimeout != "" {
+		return fmt.Errorf("静态目录不能与代理目标、strip_prefix、websocket、超时或请求头一起使用")
+	}
+	return nil
+}
+
+// proxyTarget 将路由的代理目标转换为proxy_pass使用的地址
+func proxyTarget(target string) (string, error) {
+	if isDigits(target) {
+		return "http://localhost:" + target, nil
+	}
+	if strings.ContainsAny(target, " \t;{}\"") {
+		return "", fmt.Errorf("无效的代理目标: %q", target)
+	}
+	if scheme, rest, ok := strings.Cut(target, "://"); ok {
+		if (scheme != "http" && scheme != "https") || rest == "" {
+			return "", fmt.Errorf("代理目标只支持 http:// 或 https://: %s", target)
+		}
+		return target, nil
+	}
+	if err := validateUpstreamAddr(target); err != nil {
+		return "", err
+	}
+	return "http://" + target, nil
+}
+
+// isDigits 判断字符串是否全部由数字组成
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateLocations 检查一组路由，路径不能重复
+func ValidateLocations(locations []Location) error {
+	seen := map[string]bool{}
+	for _, l := range locations {
+		if err := l.Validate(); err != nil {
+			return err
+		}
+		if seen[l.Path] {
+			return fmt.Errorf("路由路径重复: %s", l.Path)
+		}
+		seen[l.Path] = true
+	}
+	return nil
+}
+
+// ProjectLocations 转换为项目清单中的配置
+func ProjectLocations(locations []Location) []config.ProjectLocation {
+	var result []config.ProjectLocation
+	for _, l := range locations {
+		result = append(result, config.ProjectLocation(l))
+	}
+	return result
+}
+
+// NewLocations 从项目清单中的配置创建路由
+func NewLocations(locations []config.ProjectLocation) ([]Location, error) {
+	var result []Location
+	for _, l := range locations {
+		result = append(result, Location(l))
+	}
+	if err := ValidateLocations(result); err != nil {
+		return nil, fmt.Errorf("%s: %v", config.ProjectFile, err)
+	}
+	return result, nil
+}
+
+// Header nginx配置中的一个请求头
+type Header struct {
+	Name  string
+	Value string
+}
+
+// String 返回 proxy_set_header 的参数部分，包含空白的值加引号
+func (h Header) String() string {
+	if strings.ContainsAny(h.Value, " \t") {
+		return h.Name + ` "` + h.Value + `"`
+	}
+	return h.Name + " " + h.Value
+}
+
+// LocationBlock 渲染一个location块使用的数据，代理目标和请求头已解析完成
+type LocationBlock struct {
+	Path           string
+	Static         bool // 由nginx直接提供静态文件，此时只使用Root、Alias、Index和TryFiles
+	Root           string
+	Alias          string
+	Index          string
+	TryFiles       string
+	Prefix         string // 去掉前缀时rewrite匹配的前缀，不含结尾的 /
+	StripPrefix    bool
+	ProxyPass      string
+	Headers        []Header
+	WebSocket      bool
+	Connection     string // Connection请求头，为空时不设置且不强制HTTP/1.1
+	ConnectTimeout string
+	ReadTimeout    string
+	SendTimeout    string
+	LimitZone      string // limit_req使用的共享内存区域，为空时不限速
+	Burst          int
+	Allow          []string
+	Deny           []string
+	AuthFile       string // auth_basic_user_file，为空时不认证
+}
+
+// defaultHeaders 转发到后端时默认设置的请求头
+var defaultHeaders = []Header{
+	{"Host", "$host"},
+	{"X-Real-IP", "$remote_addr"},
+	{"X-Forwarded-For", "$proxy_add_x_forwarded_for"},
+	{"X-Forwarded-Proto", "$scheme"},
+}
+
+// LocationBlocks 返回server块中的全部location，自定义路由在前；
+// 未自定义 / 时追加代理到默认后端（应用端口或upstream）的 / 路由
+func (d Data) LocationBlocks() []LocationBlock {
+	locations := d.siteLocations()
+	blocks := make([]LocationBlock, 0, len(locations))
+	for i, l := range locations {
+		var block LocationBlock
+		if l.Static() {
+			block = staticBlock(l)
+		} else {
+			block = d.proxyBlock(l)
+		}
+		d.setAccess(&block, i, l)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// proxyBlock 解析一个路由的代理目标、请求头和超时
+func (d Data) proxyBlock(l Location) LocationBlock {
+	block := LocationBlock{
+		Path:           l.Path,
+		Prefix:         strings.TrimRight(l.Path, "/"),
+		StripPrefix:    l.StripPrefix,
+		ProxyPass:      d.ProxyPass(),
+		WebSocket:      l.WebSocket,
+		ConnectTimeout: orDefault(l.ConnectTimeout, defaultProxyTimeout),
+		ReadTimeout:    orDefault(l.ReadTimeout, defaultProxyTimeout),
+		SendTimeout:    orDefault(l.SendTimeout, defaultProxyTimeout),
+	}
+	keepalive := d.UpstreamKeepalive()
+	if l.Proxy != "" {
+		// Validate已检查过代理目标
+		block.ProxyPass, _ = proxyTarget(l.Proxy)
+		keepalive = false
+	}
+
+	// 复用upstream长连接时Connection头只在升级请求中为upgrade
+	switch {
+	case keepalive && l.WebSocket:
+		block.Connection = "$" + d.UpstreamName() + "_connection"
+	case keepalive:
+		block.Connection = `""`
+	case l.WebSocket:
+		block.Connection = `"upgrade"`
+	}
+
+	overridden := map[string]bool{}
+	for name := range l.Headers {
+		overridden[strings.ToLower(name)] = true
+	}
+	for _, h := range defaultHeaders {
+		if !overridden[strings.ToLower(h.Name)] {
+			block.Headers = append(block.Headers, h)
+		}
+	}
+	block.Headers = append(block.Headers, sortedHeaders(l.Headers)...)
+	return block
+}
+
+// sortedHeaders 按名称排序返回自定义请求头
+func sortedHeaders(headers map[string]string) []Header {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]Header, 0, len(names))
+	for _, name := range names {
+		result = append(result, Header{name, headers[name]})
+	}
+	return result
+}
+
+// staticBlock 解析静态目录路由，SPA回退到本路由下的首页
+func staticBlock(l Location) LocationBlock {
+	block := LocationBlock{
+		Path:     l.Path,
+		Static:   true,
+		Root:     l.Root,
+		Alias:    l.Alias,
+		Index:    orDefault(l.Index, defaultIndex),
+		TryFiles: "$uri $uri/ =404",
+	}
+	// alias与以 / 结尾的路径搭配时目录也需要以 / 结尾，否则拼接出的文件路径缺少分隔符
+	if block.Alias != "" && strings.HasSuffix(l.Path, "/") && !strings.HasSuffix(block.Alias, "/") {
+		block.Alias += "/"
+	}
+	if l.SPA {
+		block.TryFiles = "$uri $uri/ " + strings.TrimRight(l.Path, "/") + "/" + block.Index
+	}
+	return block
+}
+
+// orDefault 值为空时返回默认值
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
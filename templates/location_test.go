@@ -0,0 +1,200 @@
+package templates
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// TestParseLocation 测试解析命令行中的路由
+func TestParseLocation(t *testing.T) {
+	location, err := ParseLocation("/api/=127.0.0.1:8081,strip_prefix,websocket,timeout=30s,read_timeout=5m,header=X-Service:api")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if location.Path != "/api/" || location.Proxy != "127.0.0.1:8081" || !location.StripPrefix || !location.WebSocket {
+		t.Errorf("路由解析错误: %+v", location)
+	}
+	if location.ConnectTimeout != "30s" || location.SendTimeout != "30s" || location.ReadTimeout != "5m" {
+		t.Errorf("超时解析错误: %+v", location)
+	}
+	if location.Headers["X-Service"] != "api" {
+		t.Errorf("请求头解析错误: %v", location.Headers)
+	}
+
+	valid := []string{"/admin", "/admin=9001", "/docs=https://docs.example.com", "/ws=backend:8080,websocket"}
+	for _, spec := range valid {
+		if _, err := ParseLocation(spec); err != nil {
+			t.Errorf("%s 应该是有效的路由: %v", spec, err)
+		}
+	}
+
+	invalid := []string{
+		"api=9001",
+		"/=9001,strip_prefix",
+		"/api=ftp://files",
+		"/api=127.0.0.1:http",
+		"/api,timeout=soon",
+		"/api,header=X-Service",
+		"/api,header=X Bad:1",
+		"/api,cache",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseLocation(spec); err == nil {
+			t.Errorf("%q 应该返回错误", spec)
+		}
+	}
+}
+
+// TestValidateLocations 测试路由路径不能重复
+func TestValidateLocations(t *testing.T) {
+	if err := ValidateLocations([]Location{{Path: "/api"}, {Path: "/admin"}}); err != nil {
+		t.Errorf("不同路径应该有效: %v", err)
+	}
+	if err := ValidateLocations([]Location{{Path: "/api"}, {Path: "/api"}}); err == nil {
+		t.Error("重复路径应该返回错误")
+	}
+	if _, err := NewLocations([]config.ProjectLocation{{Path: "api"}}); err == nil || !strings.Contains(err.Error(), config.ProjectFile) {
+		t.Errorf("无效配置的错误应该包含清单文件名, 实际得到 %v", err)
+	}
+}
+
+// TestLocationBlocks 测试路由解析为location块
+func TestLocationBlocks(t *testing.T) {
+	data := nginxData("api.example.com", "8888")
+	if blocks := data.LocationBlocks(); len(blocks) != 1 || blocks[0].Path != "/" || blocks[0].ProxyPass != "http://localhost:8888" {
+		t.Fatalf("未配置路由时应该只有代理到应用端口的 /, 实际得到 %+v", blocks)
+	}
+
+	data.Upstream = &Upstream{Keepalive: 8, Servers: []UpstreamServer{{Addr: "127.0.0.1:8001"}}}
+	data.Locations = []Location{
+		{Path: "/api/", Proxy: "8081", StripPrefix: true, Headers: map[string]string{"host": "api.internal"}},
+		{Path: "/admin", WebSocket: true},
+		{Path: "/", Proxy: "static.internal"},
+	}
+	blocks := data.LocationBlocks()
+	if len(blocks) != 3 {
+		t.Fatalf("自定义了 / 时不应该追加默认路由, 实际得到 %d 个", len(blocks))
+	}
+
+	api := blocks[0]
+	if api.ProxyPass != "http://localhost:8081" || api.Prefix != "/api" || api.Connection != "" {
+		t.Errorf("/api/ 路由解析错误: %+v", api)
+	}
+	for _, h := range api.Headers {
+		if h.Name == "Host" {
+			t.Error("自定义的host请求头应该覆盖默认的Host")
+		}
+	}
+	if last := api.Headers[len(api.Headers)-1]; last.String() != "host api.internal" {
+		t.Errorf("自定义请求头应该在最后, 实际得到 %s", last)
+	}
+
+	admin := blocks[1]
+	if admin.ProxyPass != "http://api_example_com_backend" || admin.Connection != "$api_example_com_backend_connection" {
+		t.Errorf("未指定目标的路由应该使用upstream, 实际得到 %+v", admin)
+	}
+	if blocks[2].ProxyPass != "http://static.internal" || blocks[2].ConnectTimeout != "60s" {
+		t.Errorf("/ 路由解析错误: %+v", blocks[2])
+	}
+}
+
+// TestNginxLocationTemplate 测试渲染多个location
+func TestNginxLocationTemplate(t *testing.T) {
+	data := nginxData("api.example.com", "8888")
+	data.Locations = []Location{
+		{Path: "/api/", Proxy: "127.0.0.1:8081", StripPrefix: true, ReadTimeout: "120s", Headers: map[string]string{"X-Service": "api gateway"}},
+		{Path: "/admin/", Proxy: "9001", WebSocket: true},
+	}
+
+	for _, name := range []string{NginxHTTPTemplate, NginxHTTPSTemplate} {
+		data.TLS = nil
+		if name == NginxHTTPSTemplate {
+			data.TLS = &TLS{}
+		}
+		formatted := render(t, name, data)
+		for _, want := range []string{
+			"    location /api/ {\n        # 去掉路径前缀 /api 后转发\n        rewrite ^/api(/|$)(.*)$ /$2 break;\n        proxy_pass http://127.0.0.1:8081;",
+			`proxy_set_header X-Service "api gateway";`,
+			"proxy_read_timeout 120s;",
+			"    location /admin/ {\n        proxy_pass http://localhost:9001;",
+			"    location / {\n        proxy_pass http://localhost:8888;",
+		} {
+			if !strings.Contains(formatted, want) {
+				t.Errorf("%s 应该包含 %q:\n%s", name, want, formatted)
+			}
+		}
+		if strings.Count(formatted, "proxy_set_header Upgrade") != 2 {
+			t.Errorf("%s 只有开启websocket的路由应该设置Upgrade头", name)
+		}
+	}
+}
+
+// TestNginxStripPrefixRewrite 测试去掉前缀的rewrite只匹配前缀本身和它下面的路径
+func TestNginxStripPrefixRewrite(t *testing.T) {
+	data := nginxData("api.example.com", "8888")
+	data.Locations = []Location{{Path: "/api", Proxy: "8081", StripPrefix: true}}
+	formatted := render(t, NginxHTTPTemplate, data)
+	m := regexp.MustCompile(`rewrite (\S+) (\S+) break;`).FindStringSubmatch(formatted)
+	if m == nil {
+		t.Fatalf("应该包含rewrite:\n%s", formatted)
+	}
+	// nginx的rewrite使用PCRE，这里的正则与Go的语法相同
+	pattern := regexp.MustCompile(m[1])
+	for uri, want := range map[string]string{"/api": "/", "/api/": "/", "/api/users/1": "/users/1", "/apiary": "/apiary"} {
+		got := uri
+		if sub := pattern.FindStringSubmatch(uri); sub != nil {
+			got = strings.ReplaceAll(m[2], "$2", sub[2])
+		}
+		if got != want {
+			t.Errorf("%s 应该转发为 %s, 实际得到 %s", uri, want, got)
+		}
+	}
+}
+
+// TestStaticLocation 测试静态目录路由
+func TestStaticLocation(t *testing.T) {
+	location, err := ParseLocation("/,root=/srv/www,spa,index=app.html")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if !location.Static() || location.Root != "/srv/www" || !location.SPA || location.Index != "app.html" {
+		t.Errorf("静态路由解析错误: %+v", location)
+	}
+
+	invalid := []string{
+		"/,root=srv/www",
+		"/,root=/srv/www,alias=/srv/assets",
+		"/=9001,root=/srv/www",
+		"/static,alias=/srv/static,websocket",
+		"/,spa",
+		"/,root=/srv/www,index=a/b.html",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseLocation(spec); err == nil {
+			t.Errorf("%q 应该返回错误", spec)
+		}
+	}
+
+	data := nginxData("www.example.com", "8888")
+	data.Locations = []Location{
+		{Path: "/", Root: "/srv/www", SPA: true},
+		{Path: "/assets/", Alias: "/srv/assets"},
+		{Path: "/api/", Proxy: "9001"},
+	}
+	formatted := render(t, NginxHTTPTemplate, data)
+	for _, want := range []string{
+		"    location / {\n        root /srv/www;\n        index index.html;\n        try_files $uri $uri/ /index.html;\n    }",
+		"    location /assets/ {\n        alias /srv/assets/;\n        index index.html;\n        try_files $uri $uri/ =404;\n    }",
+		"    location /api/ {\n        proxy_pass http://localhost:9001;",
+	} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("nginx配置应该包含 %q:\n%s", want, formatted)
+		}
+	}
+	if strings.Contains(formatted, "proxy_pass http://localhost:8888") {
+		t.Error("静态目录作为 / 时不应该再代理到应用端口")
+	}
+}
@@ -1,17 +1,24 @@
 package templates
 
 import (
-	"fmt"
 	"strings"
 	"testing"
 )
 
+// nginxData 创建nginx模板使用的数据
+func nginxData(domain, port string) Data {
+	data := NewData("")
+	data.Domain = domain
+	data.Port = port
+	return data
+}
+
 // TestNginxHTTPTemplate 测试nginx HTTP模板
 func TestNginxHTTPTemplate(t *testing.T) {
 	// 测试模板格式化
 	domain := "test.example.com"
 	port := "8888"
-	formatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	formatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	// 验证模板内容
 	if !strings.Contains(formatted, "server_name "+domain) {
@@ -80,7 +87,7 @@ func TestNginxHTTPSTemplate(t *testing.T) {
 	// 测试模板格式化
 	domain := "test.example.com"
 	port := "8888"
-	formatted := fmt.Sprintf(NginxHTTPSTemplate, domain, port, domain, domain, domain, domain, domain, domain)
+	formatted := render(t, NginxHTTPSTemplate, nginxData(domain, port))
 	
 	// 验证模板内容
 	if !strings.Contains(formatted, "server_name "+domain) {
@@ -116,38 +123,67 @@ func TestNginxHTTPSTemplate(t *testing.T) {
 	}
 }
 
+// TestNginxHTTPSTemplateCustomCert 测试自定义证书路径和HTTP重定向
+func TestNginxHTTPSTemplateCustomCert(t *testing.T) {
+	data := nginxData("test.example.com", "8888")
+	data.TLS = &TLS{Cert: "/etc/ssl/site.crt", Key: "/etc/ssl/site.key"}
+	if data.NginxTemplate() != NginxHTTPSTemplate {
+		t.Fatalf("设置TLS后应该使用HTTPS模板, 实际为 %s", data.NginxTemplate())
+	}
+	formatted := render(t, data.NginxTemplate(), data)
+
+	for _, want := range []string{
+		"ssl_certificate /etc/ssl/site.crt;",
+		"ssl_certificate_key /etc/ssl/site.key;",
+		"return 301 https://$host$request_uri;",
+		"location /.well-known/acme-challenge/",
+	} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("nginx HTTPS模板应该包含 %q", want)
+		}
+	}
+	if strings.Contains(formatted, "managed by Certbot") || strings.Contains(formatted, "/etc/letsencrypt") {
+		t.Error("自定义证书的HTTPS模板不应该包含certbot生成的内容")
+	}
+
+	data.TLS = nil
+	if data.NginxTemplate() != NginxHTTPTemplate {
+		t.Error("未设置TLS时应该使用HTTP模板")
+	}
+}
+
 // TestNginxSetupScriptTemplate 测试nginx设置脚本模板
 func TestNginxSetupScriptTemplate(t *testing.T) {
 	// 验证模板内容
-	if !strings.Contains(NginxSetupScriptTemplate, "#!/bin/bash") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "#!/bin/bash") {
 		t.Errorf("nginx设置脚本模板应该包含shebang")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "nginx -t") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "nginx -t") {
 		t.Errorf("nginx设置脚本模板应该包含nginx配置测试")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "systemctl reload nginx") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "systemctl reload nginx") {
 		t.Errorf("nginx设置脚本模板应该包含nginx重载命令")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "ln -sf") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "ln -sf") {
 		t.Errorf("nginx设置脚本模板应该包含软链接创建")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "/etc/nginx/sites-available") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "/etc/nginx/sites-available") {
 		t.Errorf("nginx设置脚本模板应该包含sites-available路径")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "/etc/nginx/sites-enabled") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "/etc/nginx/sites-enabled") {
 		t.Errorf("nginx设置脚本模板应该包含sites-enabled路径")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "echo \"nginx配置完成\"") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "echo \"nginx配置完成\"") {
 		t.Errorf("nginx设置脚本模板应该包含完成消息")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "echo \"请访问 http://$DOMAIN\"") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "echo \"请访问 http://$DOMAIN\"") {
 		t.Errorf("nginx设置脚本模板应该包含访问提示")
 	}
 }
@@ -155,67 +191,67 @@ func TestNginxSetupScriptTemplate(t *testing.T) {
 // TestCertbotScriptTemplate 测试certbot脚本模板
 func TestCertbotScriptTemplate(t *testing.T) {
 	// 验证模板内容
-	if !strings.Contains(CertbotScriptTemplate, "#!/bin/bash") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "#!/bin/bash") {
 		t.Errorf("certbot脚本模板应该包含shebang")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "certbot") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "certbot") {
 		t.Errorf("certbot脚本模板应该包含certbot命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "nginx -t") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "nginx -t") {
 		t.Errorf("certbot脚本模板应该包含nginx配置测试")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "systemctl reload nginx") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "systemctl reload nginx") {
 		t.Errorf("certbot脚本模板应该包含nginx重载命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "apt install") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "apt install") {
 		t.Errorf("certbot脚本模板应该包含apt安装命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "yum install") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "yum install") {
 		t.Errorf("certbot脚本模板应该包含yum安装命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "dnf install") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "dnf install") {
 		t.Errorf("certbot脚本模板应该包含dnf安装命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "Debian/Ubuntu") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "Debian/Ubuntu") {
 		t.Errorf("certbot脚本模板应该包含Debian/Ubuntu系统检测")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "CentOS/RHEL") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "CentOS/RHEL") {
 		t.Errorf("certbot脚本模板应该包含CentOS/RHEL系统检测")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "Fedora") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "Fedora") {
 		t.Errorf("certbot脚本模板应该包含Fedora系统检测")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "command -v certbot") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "command -v certbot") {
 		t.Errorf("certbot脚本模板应该包含certbot检查命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "certbot --nginx -d") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "certbot --nginx -d") {
 		t.Errorf("certbot脚本模板应该包含SSL证书申请命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "python3-certbot-nginx") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "python3-certbot-nginx") {
 		t.Errorf("certbot脚本模板应该包含nginx插件")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "echo \"正在为域名") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "echo \"正在为域名") {
 		t.Errorf("certbot脚本模板应该包含进度提示")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "echo \"✅ SSL证书申请成功\"") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "echo \"✅ SSL证书申请成功\"") {
 		t.Errorf("certbot脚本模板应该包含成功消息")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "echo \"❌ SSL证书申请失败\"") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "echo \"❌ SSL证书申请失败\"") {
 		t.Errorf("certbot脚本模板应该包含失败消息")
 	}
 }
@@ -226,26 +262,26 @@ func TestNginxTemplateFormatting(t *testing.T) {
 	testCases := []struct {
 		name     string
 		template string
-		args     []interface{}
+		data     Data
 		expected []string
 	}{
 		{
 			name:     "nginx HTTP模板",
 			template: NginxHTTPTemplate,
-			args:     []interface{}{"test.example.com", "8888", "test.example.com", "test.example.com"},
+			data:     nginxData("test.example.com", "8888"),
 			expected: []string{"server_name test.example.com", "proxy_pass http://localhost:8888", "listen 80"},
 		},
 		{
 			name:     "nginx HTTPS模板",
 			template: NginxHTTPSTemplate,
-			args:     []interface{}{"test.example.com", "8888", "test.example.com", "test.example.com"},
+			data:     nginxData("test.example.com", "8888"),
 			expected: []string{"server_name test.example.com", "listen 443 ssl", "ssl_certificate"},
 		},
 	}
 	
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			formatted := fmt.Sprintf(tc.template, tc.args...)
+			formatted := render(t, tc.template, tc.data)
 			
 			for _, expected := range tc.expected {
 				if !strings.Contains(formatted, expected) {
@@ -261,7 +297,7 @@ func TestNginxTemplateSpecialCharacters(t *testing.T) {
 	// 测试包含特殊字符的域名
 	domain := "test-domain_with.dots-and-dashes.com"
 	port := "8888"
-	formatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	formatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(formatted, "server_name "+domain) {
 		t.Errorf("nginx模板应该正确处理特殊字符域名: %s", domain)
@@ -270,7 +306,7 @@ func TestNginxTemplateSpecialCharacters(t *testing.T) {
 	// 测试包含特殊字符的端口
 	domain = "test.example.com"
 	port = "8080"
-	formatted = fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	formatted = render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(formatted, "proxy_pass http://localhost:"+port) {
 		t.Errorf("nginx模板应该正确处理端口: %s", port)
@@ -282,7 +318,7 @@ func TestNginxTemplateEmptyValues(t *testing.T) {
 	// 测试空域名
 	domain := ""
 	port := "8888"
-	formatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	formatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(formatted, "server_name ") {
 		t.Errorf("nginx模板应该处理空域名")
@@ -291,7 +327,7 @@ func TestNginxTemplateEmptyValues(t *testing.T) {
 	// 测试空端口
 	domain = "test.example.com"
 	port = ""
-	formatted = fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	formatted = render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(formatted, "proxy_pass http://localhost:") {
 		t.Errorf("nginx模板应该处理空端口")
@@ -304,8 +340,8 @@ func TestNginxTemplateConsistency(t *testing.T) {
 	domain := "test.example.com"
 	port := "8888"
 	
-	httpFormatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
-	httpsFormatted := fmt.Sprintf(NginxHTTPSTemplate, domain, port, domain, domain, domain, domain, domain, domain)
+	httpFormatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
+	httpsFormatted := render(t, NginxHTTPSTemplate, nginxData(domain, port))
 	
 	// 两个模板都应该包含相同的server_name
 	if !strings.Contains(httpFormatted, "server_name "+domain) {
@@ -340,11 +376,12 @@ func TestNginxTemplateCompleteness(t *testing.T) {
 	}
 	
 	for _, tc := range templates {
-		if tc.template == "" {
+		rendered := render(t, tc.template, nginxData("test.example.com", "8888"))
+		if rendered == "" {
 			t.Errorf("模板 %s 不能为空", tc.name)
 		}
 		
-		if len(tc.template) < 50 {
+		if len(rendered) < 50 {
 			t.Errorf("模板 %s 内容太短", tc.name)
 		}
 	}
@@ -356,7 +393,7 @@ func TestNginxTemplateSecurity(t *testing.T) {
 	port := "8888"
 	
 	// 测试HTTP模板的安全设置
-	httpFormatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	httpFormatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	// 验证包含安全相关的头设置
 	securityHeaders := []string{
@@ -373,7 +410,7 @@ func TestNginxTemplateSecurity(t *testing.T) {
 	}
 	
 	// 测试HTTPS模板的安全设置
-	httpsFormatted := fmt.Sprintf(NginxHTTPSTemplate, domain, port, domain, domain, domain, domain, domain, domain)
+	httpsFormatted := render(t, NginxHTTPSTemplate, nginxData(domain, port))
 	
 	// 验证包含SSL安全设置
 	sslSecuritySettings := []string{
@@ -395,7 +432,7 @@ func TestNginxTemplatePerformance(t *testing.T) {
 	port := "8888"
 	
 	// 测试HTTP模板的性能设置
-	httpFormatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	httpFormatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	// 验证包含性能相关的设置
 	performanceSettings := []string{
@@ -418,7 +455,7 @@ func TestNginxTemplateWebSocket(t *testing.T) {
 	port := "8888"
 	
 	// 测试HTTP模板的WebSocket支持
-	httpFormatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	httpFormatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	// 验证包含WebSocket相关的设置
 	websocketSettings := []string{
@@ -440,7 +477,7 @@ func TestNginxTemplateLogging(t *testing.T) {
 	port := "8888"
 	
 	// 测试HTTP模板的日志设置
-	httpFormatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	httpFormatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	// 验证包含日志相关的设置
 	loggingSettings := []string{
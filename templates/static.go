@@ -0,0 +1,128 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// BrotliSnippet 服务器上brotli配置片段的默认路径，setup-nginx.sh只在nginx支持brotli模块时安装，
+// 域名配置通过glob引用该片段，片段不存在时nginx忽略该include
+const BrotliSnippet = NginxRoot + "/snippets/aigo-brotli.conf"
+
+// BrotliConfigFile 项目中brotli配置片段的相对路径
+const BrotliConfigFile = "config/brotli.conf"
+
+// fileExtension 缓存规则中的文件扩展名
+var fileExtension = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// compressTypes gzip和brotli压缩的MIME类型，text/html总是被压缩
+var compressTypes = []string{
+	"text/plain", "text/css", "text/xml", "text/javascript",
+	"application/javascript", "application/json", "application/xml",
+	"application/rss+xml", "application/wasm", "image/svg+xml", "font/ttf", "font/otf",
+}
+
+// Compression 响应压缩设置
+type Compression struct {
+	Gzip   bool
+	Brotli bool // 需要服务器安装ngx_brotli模块
+}
+
+// CacheRule 按文件扩展名设置的浏览器缓存规则
+type CacheRule struct {
+	Extensions []string // 不带点的扩展名，如 js、css
+	Expires    string   // nginx expires参数: 时间（如 30d）、epoch（不缓存）、max 或 off
+}
+
+// ParseCacheRule 解析命令行中的缓存规则，格式为 ext[,ext...]=expires，如 js,css=30d
+func ParseCacheRule(spec string) (CacheRule, error) {
+	extensions, expires, ok := strings.Cut(spec, "=")
+	if !ok {
+		return CacheRule{}, fmt.Errorf("缓存规则格式应为 ext[,ext...]=expires: %s", spec)
+	}
+	rule := CacheRule{Expires: strings.TrimSpace(expires)}
+	for _, ext := range strings.Split(extensions, ",") {
+		rule.Extensions = append(rule.Extensions, strings.TrimPrefix(strings.TrimSpace(ext), "."))
+	}
+	return rule, rule.Validate()
+}
+
+// Validate 检查缓存规则
+func (r CacheRule) Validate() error {
+	if len(r.Extensions) == 0 {
+		return fmt.Errorf("缓存规则至少需要一个扩展名")
+	}
+	for _, ext := range r.Extensions {
+		if !fileExtension.MatchString(ext) {
+			return fmt.Errorf("无效的扩展名: %q", ext)
+		}
+	}
+	switch r.Expires {
+	case "epoch", "max", "off":
+		return nil
+	}
+	if !nginxTime.MatchString(r.Expires) {
+		return fmt.Errorf("缓存时间应为nginx时间格式（如 30d、12h）或 epoch、max、off: %q", r.Expires)
+	}
+	return nil
+}
+
+// Pattern 返回匹配这些扩展名的map正则
+func (r CacheRule) Pattern() string {
+	return `~*\.(` + strings.Join(r.Extensions, "|") + `)$`
+}
+
+// ProjectCache 转换为项目清单中的配置
+func ProjectCache(rules []CacheRule) []config.ProjectCacheRule {
+	var result []config.ProjectCacheRule
+	for _, r := range rules {
+		result = append(result, config.ProjectCacheRule(r))
+	}
+	return result
+}
+
+// NewCache 从项目清单中的配置创建缓存规则
+func NewCache(rules []config.ProjectCacheRule) ([]CacheRule, error) {
+	var result []CacheRule
+	for _, r := range rules {
+		rule := CacheRule(r)
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %v", config.ProjectFile, err)
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// ConfigName 返回由域名生成的标识，用于upstream、map变量等http级别的名称，避免多个域名的配置互相冲突
+func (d Data) ConfigName() string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, d.Domain)
+}
+
+// CacheVariable 返回按扩展名计算expires的map变量
+func (d Data) CacheVariable() string {
+	return "$" + d.ConfigName() + "_expires"
+}
+
+// BrotliInclude 返回引用brotli配置片段的glob，片段未安装时不匹配任何文件
+func (d Data) BrotliInclude() string {
+	return strings.TrimSuffix(d.BrotliSnippet(), ".conf") + "*.conf"
+}
+
+// BrotliSnippet 返回服务器上brotli配置片段的路径
+func (d Data) BrotliSnippet() string {
+	return d.nginxRoot() + strings.TrimPrefix(BrotliSnippet, NginxRoot)
+}
+
+// CompressTypes 返回gzip和brotli压缩的MIME类型列表
+func (d Data) CompressTypes() string {
+	return strings.Join(compressTypes, " ")
+}
@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// TestParseCacheRule 测试解析缓存规则
+func TestParseCacheRule(t *testing.T) {
+	rule, err := ParseCacheRule(".js, css=30d")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if strings.Join(rule.Extensions, ",") != "js,css" || rule.Expires != "30d" {
+		t.Errorf("缓存规则解析错误: %+v", rule)
+	}
+	if pattern := rule.Pattern(); pattern != `~*\.(js|css)$` {
+		t.Errorf("扩展名正则错误: %s", pattern)
+	}
+
+	for _, spec := range []string{"html=epoch", "woff2=max", "png,jpg=12h"} {
+		if _, err := ParseCacheRule(spec); err != nil {
+			t.Errorf("%s 应该是有效的缓存规则: %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"js", "=30d", "js=forever", "j.s=30d", "js,=1d"} {
+		if _, err := ParseCacheRule(spec); err == nil {
+			t.Errorf("%q 应该返回错误", spec)
+		}
+	}
+	if _, err := NewCache([]config.ProjectCacheRule{{Extensions: []string{"js"}}}); err == nil || !strings.Contains(err.Error(), config.ProjectFile) {
+		t.Errorf("无效配置的错误应该包含清单文件名, 实际得到 %v", err)
+	}
+}
+
+// TestNginxCompressionAndCache 测试渲染压缩和缓存设置
+func TestNginxCompressionAndCache(t *testing.T) {
+	data := nginxData("www.example.com", "8888")
+	formatted := render(t, NginxHTTPTemplate, data)
+	if strings.Contains(formatted, "gzip") || strings.Contains(formatted, "expires") {
+		t.Errorf("未配置时不应该设置压缩和缓存:\n%s", formatted)
+	}
+
+	data.Compression = &Compression{Gzip: true, Brotli: true}
+	data.Cache = []CacheRule{{Extensions: []string{"js", "css"}, Expires: "30d"}, {Extensions: []string{"html"}, Expires: "epoch"}}
+	for _, name := range []string{NginxHTTPTemplate, NginxHTTPSTemplate} {
+		data.TLS = nil
+		if name == NginxHTTPSTemplate {
+			data.TLS = &TLS{}
+		}
+		formatted := render(t, name, data)
+		for _, want := range []string{
+			"map $uri $www_example_com_expires {\n    default off;\n    ~*\\.(js|css)$ 30d;\n    ~*\\.(html)$ epoch;\n}",
+			"    gzip on;\n",
+			"    gzip_types text/plain",
+			"    include /etc/nginx/snippets/aigo-brotli*.conf;\n",
+			"    expires $www_example_com_expires;\n",
+		} {
+			if !strings.Contains(formatted, want) {
+				t.Errorf("%s 应该包含 %q:\n%s", name, want, formatted)
+			}
+		}
+		if strings.Count(formatted, "gzip on;") != 1 {
+			t.Errorf("%s 只应该在提供服务的server块中设置压缩", name)
+		}
+	}
+
+	snippet := render(t, NginxBrotliTemplate, data)
+	if !strings.Contains(snippet, "brotli on;") || !strings.Contains(snippet, "brotli_types text/plain") {
+		t.Errorf("brotli配置片段错误:\n%s", snippet)
+	}
+	script := render(t, NginxSetupScriptTemplate, data)
+	if !strings.Contains(script, "ln -sf $(pwd)/config/brotli.conf "+BrotliSnippet) {
+		t.Errorf("配置脚本应该安装brotli配置片段:\n%s", script)
+	}
+}
@@ -1,245 +1,278 @@
 package templates
 
-// GoModTemplate go.mod文件模板
-const GoModTemplate = `module %s
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
 
-go 1.24
+	"github.com/yggai/aigo_hotreload/config"
+)
 
-require (
-	github.com/gin-gonic/gin v1.9.1
+//go:embed all:files
+var files embed.FS
+
+// 项目文件模板名称
+const (
+	GoModTemplate     = "project/go.mod.tmpl"
+	MainGoTemplate    = "project/main.go.tmpl"
+	AirTomlTemplate   = "project/air.toml.tmpl"
+	GitignoreTemplate = "project/gitignore.tmpl"
+	ReadmeTemplate    = "project/README.md.tmpl"
+	DatabaseTemplate  = "project/db.go.tmpl"
 )
-`
 
-// MainGoTemplate main.go文件模板
-const MainGoTemplate = `package main
+// Docker相关模板名称
+const (
+	DockerfileTemplate    = "docker/Dockerfile.tmpl"
+	DockerignoreTemplate  = "docker/dockerignore.tmpl"
+	DockerComposeTemplate = "docker/docker-compose.yml.tmpl"
+)
 
-import (
-	"fmt"
-	"net/http"
+// Data 模板渲染使用的数据模型
+type Data struct {
+	ProjectName string
+	ModulePath  string
+	Port        string
+	Domain      string
+	GoVersion   string
+	Framework   Framework
+	Nginx       bool              // 是否生成nginx配置与SSL脚本
+	TLS         *TLS              // nginx的HTTPS配置，nil表示只监听HTTP
+	Upstream    *Upstream         // nginx的负载均衡后端，nil表示代理到本机的Port端口
+	Locations   []Location        // nginx按路径转发的路由，未包含 / 时 / 转发到默认后端
+	Compression *Compression      // nginx响应压缩，nil表示不设置
+	Cache       []CacheRule       // nginx按扩展名设置的浏览器缓存
+	Credentials []Credential      // basic auth用户，Caddy和Traefik的配置中内联密码哈希
+	NginxRoot   string            // 服务器上nginx配置的根目录，为空时使用 /etc/nginx
+	Docker      bool              // 是否生成Dockerfile等容器文件
+	Database    *Database         // 数据库，nil表示不使用
+	Vars        map[string]string // 自定义模板的变量
+}
 
-	"github.com/gin-gonic/gin"
-)
+// NewData 创建带默认值的模板数据
+func NewData(projectName string) Data {
+	return Data{
+		ProjectName: projectName,
+		ModulePath:  projectName,
+		Port:        config.DefaultPort,
+		Domain:      config.DefaultDomain,
+		GoVersion:   config.DefaultGoVersion,
+		Framework:   mustFramework(DefaultFramework),
+		Nginx:       true,
+		Vars:        map[string]string{},
+	}
+}
 
-func main() {
-	// 创建gin路由器
-	r := gin.Default()
+// Set 按下划线命名设置内置字段，key不是内置字段时返回false
+func (d *Data) Set(key, value string) bool {
+	switch key {
+	case "project_name":
+		d.ProjectName = value
+	case "module_path":
+		d.ModulePath = value
+	case "port":
+		d.Port = value
+	case "domain":
+		d.Domain = value
+	case "go_version":
+		d.GoVersion = value
+	case "framework":
+		fw, err := LookupFramework(value)
+		if err != nil {
+			return false
+		}
+		d.Framework = fw
+	default:
+		return false
+	}
+	return true
+}
 
-	// 打印启动信息
-	fmt.Println("正在启动gin服务器...")
-	fmt.Println("服务器将在 http://localhost:8888 启动")
+// Require 返回go.mod中需要的全部依赖
+func (d Data) Require() []Module {
+	require := append([]Module{}, d.Framework.Require...)
+	if d.Database != nil {
+		require = append(require, d.Database.Driver)
+	}
+	return require
+}
 
-	// 添加根路由
-	r.GET("/", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message":   "热更新测试成功！代码已自动重载",
-			"status":    "success",
-			"timestamp": "2024-01-01",
-		})
-	})
+// Project 返回写入 aigo.yaml 的项目清单
+func (d Data) Project() *config.Project {
+	p := &config.Project{
+		Name:      d.ProjectName,
+		Module:    d.ModulePath,
+		Port:      d.Port,
+		Framework: d.Framework.Name,
+	}
+	if d.Nginx {
+		p.Domains = []string{d.Domain}
+		p.AddComponent("nginx")
+	}
+	if d.TLS != nil {
+		p.TLS = &config.ProjectTLS{Cert: d.TLS.Cert, Key: d.TLS.Key}
+	}
+	if d.Upstream != nil {
+		p.Upstream = d.Upstream.Project()
+	}
+	p.Locations = ProjectLocations(d.Locations)
+	if d.Compression != nil {
+		p.Compression = &config.ProjectCompression{Gzip: d.Compression.Gzip, Brotli: d.Compression.Brotli}
+	}
+	p.Cache = ProjectCache(d.Cache)
+	if d.Docker {
+		p.AddComponent("dockerfile")
+	}
+	if d.Database != nil {
+		p.Database = d.Database.Name
+		p.AddComponent("db")
+	}
+	return p
+}
 
-	// 添加另一个打招呼路由
-	r.GET("/hello", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message":  "Hello, World!",
-			"greeting": "欢迎来到gin世界！",
-		})
-	})
+// ApplyProject 使用 aigo.yaml 中的项目清单覆盖模板数据
+func (d *Data) ApplyProject(p *config.Project) error {
+	if p.Name != "" {
+		d.ProjectName = p.Name
+	}
+	if p.Module != "" {
+		d.ModulePath = p.Module
+	}
+	if p.Port != "" {
+		d.Port = p.Port
+	}
+	if len(p.Domains) > 0 {
+		d.Domain = p.Domains[0]
+	}
+	if p.Framework != "" {
+		fw, err := LookupFramework(p.Framework)
+		if err != nil {
+			return fmt.Errorf("%s: %v", config.ProjectFile, err)
+		}
+		d.Framework = fw
+	}
+	if p.Database != "" {
+		db, err := LookupDatabase(p.Database)
+		if err != nil {
+			return fmt.Errorf("%s: %v", config.ProjectFile, err)
+		}
+		d.Database = &db
+	}
+	if p.TLS != nil {
+		d.TLS = &TLS{Cert: p.TLS.Cert, Key: p.TLS.Key}
+	}
+	if p.Upstream != nil {
+		upstream, err := NewUpstream(p.Upstream)
+		if err != nil {
+			return err
+		}
+		d.Upstream = upstream
+	}
+	if len(p.Locations) > 0 {
+		locations, err := NewLocations(p.Locations)
+		if err != nil {
+			return err
+		}
+		d.Locations = locations
+	}
+	if p.Compression != nil {
+		d.Compression = &Compression{Gzip: p.Compression.Gzip, Brotli: p.Compression.Brotli}
+	}
+	if len(p.Cache) > 0 {
+		cache, err := NewCache(p.Cache)
+		if err != nil {
+			return err
+		}
+		d.Cache = cache
+	}
+	d.Nginx = p.HasComponent("nginx")
+	d.Docker = d.Docker || p.HasComponent("dockerfile")
+	return nil
+}
 
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "healthy",
-			"time":   "2024-01-01 00:00:00",
+// Env 以环境变量形式导出模板数据，供生成钩子使用
+func (d *Data) Env() []string {
+	env := []string{
+		"AIGO_PROJECT_NAME=" + d.ProjectName,
+		"AIGO_MODULE_PATH=" + d.ModulePath,
+		"AIGO_PORT=" + d.Port,
+		"AIGO_DOMAIN=" + d.Domain,
+		"AIGO_GO_VERSION=" + d.GoVersion,
+		"AIGO_FRAMEWORK=" + d.Framework.Name,
+	}
+	for key, value := range d.Vars {
+		env = append(env, "AIGO_VAR_"+strings.ToUpper(key)+"="+value)
+	}
+	sort.Strings(env[6:])
+	return env
+}
+
+var (
+	parseOnce sync.Once
+	parsed    *template.Template
+	parseErr  error
+)
+
+// load 解析所有内嵌模板，模板名称为相对files目录的路径
+func load() (*template.Template, error) {
+	parseOnce.Do(func() {
+		root := template.New("").Option("missingkey=error")
+		parseErr = fs.WalkDir(files, "files", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".tmpl") {
+				return err
+			}
+			content, err := files.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimPrefix(path, "files/")
+			if _, err := root.New(name).Parse(string(content)); err != nil {
+				return fmt.Errorf("解析模板 %s 失败: %v", name, err)
+			}
+			return nil
 		})
+		parsed = root
 	})
+	return parsed, parseErr
+}
 
-	// API路由组
-	api := r.Group("/api/v1")
-	{
-		api.GET("/users", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{
-				"users": []string{"Alice", "Bob", "Charlie"},
-			})
-		})
+// Render 使用数据渲染指定模板
+func Render(name string, data Data) (string, error) {
+	root, err := load()
+	if err != nil {
+		return "", err
+	}
 
-		api.POST("/users", func(c *gin.Context) {
-			c.JSON(http.StatusCreated, gin.H{
-				"message": "User created successfully",
-			})
-		})
+	tmpl := root.Lookup(name)
+	if tmpl == nil {
+		return "", fmt.Errorf("模板 %s 不存在", name)
 	}
 
-	// 启动服务器在8888端口
-	r.Run(":8888")
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染模板 %s 失败: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Names 返回所有内嵌模板名称
+func Names() []string {
+	root, err := load()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, tmpl := range root.Templates() {
+		if tmpl.Name() != "" {
+			names = append(names, tmpl.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
 }
-`
-
-// AirTomlTemplate .air.toml文件模板
-const AirTomlTemplate = `root = "."
-testdata_dir = "testdata"
-tmp_dir = "tmp"
-
-[build]
-  args_bin = []
-  bin = "./tmp/main"
-  cmd = "go build -o ./tmp/main ."
-  delay = 1000
-  exclude_dir = ["assets", "tmp", "vendor", "testdata"]
-  exclude_file = []
-  exclude_regex = ["_test.go"]
-  exclude_unchanged = false
-  follow_symlink = false
-  full_bin = ""
-  include_dir = []
-  include_ext = ["go", "tpl", "tmpl", "html"]
-  include_file = []
-  kill_delay = "0s"
-  log = "build-errors.log"
-  poll = false
-  poll_interval = 0
-  rerun = false
-  rerun_delay = 500
-  send_interrupt = false
-  stop_on_root = false
-
-[color]
-  app = ""
-  build = "yellow"
-  main = "magenta"
-  runner = "green"
-  watcher = "cyan"
-
-[log]
-  main_only = false
-  time = false
-
-[misc]
-  clean_on_exit = false
-
-[screen]
-  clear_on_rebuild = false
-  keep_scroll = true
-`
-
-// GitignoreTemplate .gitignore文件模板
-const GitignoreTemplate = `# Binaries for programs and plugins
-*.exe
-*.exe~
-*.dll
-*.so
-*.dylib
-
-# Test binary, built with go test -c
-*.test
-
-# Output of the go coverage tool
-*.out
-
-# Dependency directories
-vendor/
-
-# Go workspace file
-go.work
-
-# Air temporary files
-tmp/
-
-# IDE files
-.vscode/
-.idea/
-*.swp
-*.swo
-*~
-
-# OS generated files
-.DS_Store
-.DS_Store?
-._*
-.Spotlight-V100
-.Trashes
-ehthumbs.db
-Thumbs.db
-
-# Log files
-*.log
-build-errors.log
-`
-
-// ReadmeTemplate README.md文件模板
-const ReadmeTemplate = `# %s
-
-一个使用 aigo_hotreload 创建的 Go 热重载项目。
-
-## 🚀 快速开始
-
-### 环境要求
-- Go 1.24 或更高版本
-
-### 安装依赖
-` + "```bash\n" + `go mod tidy
-` + "```\n\n" + `### 启动开发服务器
-` + "```bash\n" + `air
-` + "```\n\n" + `### 访问应用
-- 主页: http://localhost:8888
-- 健康检查: http://localhost:8888/health
-- API示例: http://localhost:8888/api/v1/users
-
-## 📁 项目结构
-
-` + "```\n" + `%s/
-├── main.go              # 主程序文件
-├── go.mod              # Go模块依赖
-├── .air.toml           # Air热重载配置
-├── .gitignore          # Git忽略文件
-├── config/             # nginx配置文件目录
-│   ├── your-domain.com # nginx配置文件
-│   └── setup-nginx.sh  # nginx配置脚本
-├── scripts/            # 脚本目录
-│   └── apply-ssl.sh    # SSL证书申请脚本
-└── README.md           # 项目说明
-` + "```" + `
-
-## 🛠️ 开发说明
-
-- 修改代码后会自动重新编译和重启
-- 默认端口: 8888
-- 支持热重载，提高开发效率
-
-## 🌐 域名部署
-
-### 1. 配置nginx
-` + "```bash\n" + `# 编辑nginx配置文件
-vim config/your-domain.com
-
-# 运行nginx配置脚本
-chmod +x config/setup-nginx.sh
-./config/setup-nginx.sh your-domain.com 8888
-` + "```\n\n" + `### 2. 申请SSL证书
-` + "```bash\n" + `# 安装certbot
-sudo apt update
-sudo apt install -y certbot python3-certbot-nginx
-
-# 申请SSL证书
-chmod +x scripts/apply-ssl.sh
-./scripts/apply-ssl.sh your-domain.com
-` + "```\n\n" + `### 3. 启动服务
-` + "```bash\n" + `# 启动热重载服务
-air
-` + "```\n\n" + `## 📝 API接口
-
-### GET /
-返回欢迎信息
-
-### GET /health
-健康检查接口
-
-### GET /api/v1/users
-获取用户列表
-
-### POST /api/v1/users
-创建新用户
-
----
-
-由 [aigo_hotreload](https://github.com/yggai/aigo_hotreload) 生成
-`
\ No newline at end of file
@@ -6,11 +6,27 @@ import (
 	"testing"
 )
 
+// render 渲染模板，失败时终止测试
+func render(t *testing.T, name string, data Data) string {
+	t.Helper()
+	out, err := Render(name, data)
+	if err != nil {
+		t.Fatalf("渲染模板 %s 失败: %v", name, err)
+	}
+	return out
+}
+
+// content 使用默认数据渲染模板
+func content(t *testing.T, name string) string {
+	t.Helper()
+	return render(t, name, NewData("test-project"))
+}
+
 // TestGoModTemplate 测试go.mod模板
 func TestGoModTemplate(t *testing.T) {
 	// 测试模板格式化
 	projectName := "test-project"
-	formatted := fmt.Sprintf(GoModTemplate, projectName)
+	formatted := render(t, GoModTemplate, NewData(projectName))
 	
 	// 验证模板内容
 	if !strings.Contains(formatted, "module "+projectName) {
@@ -33,39 +49,39 @@ func TestGoModTemplate(t *testing.T) {
 // TestMainGoTemplate 测试main.go模板
 func TestMainGoTemplate(t *testing.T) {
 	// 验证模板内容
-	if !strings.Contains(MainGoTemplate, "package main") {
+	if !strings.Contains(content(t, MainGoTemplate), "package main") {
 		t.Errorf("main.go模板应该包含package main")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "import") {
+	if !strings.Contains(content(t, MainGoTemplate), "import") {
 		t.Errorf("main.go模板应该包含import语句")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "gin.Default()") {
+	if !strings.Contains(content(t, MainGoTemplate), "gin.Default()") {
 		t.Errorf("main.go模板应该包含gin.Default()")
 	}
 	
-	if !strings.Contains(MainGoTemplate, ":8888") {
+	if !strings.Contains(content(t, MainGoTemplate), ":8888") {
 		t.Errorf("main.go模板应该包含端口8888")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "r.GET(\"/\"") {
+	if !strings.Contains(content(t, MainGoTemplate), "r.GET(\"/\"") {
 		t.Errorf("main.go模板应该包含根路由")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "r.GET(\"/hello\"") {
+	if !strings.Contains(content(t, MainGoTemplate), "r.GET(\"/hello\"") {
 		t.Errorf("main.go模板应该包含hello路由")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "r.GET(\"/health\"") {
+	if !strings.Contains(content(t, MainGoTemplate), "r.GET(\"/health\"") {
 		t.Errorf("main.go模板应该包含health路由")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "/api/v1") {
+	if !strings.Contains(content(t, MainGoTemplate), "/api/v1") {
 		t.Errorf("main.go模板应该包含API路由组")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "/users") {
+	if !strings.Contains(content(t, MainGoTemplate), "/users") {
 		t.Errorf("main.go模板应该包含users路由")
 	}
 }
@@ -73,27 +89,27 @@ func TestMainGoTemplate(t *testing.T) {
 // TestAirTomlTemplate 测试.air.toml模板
 func TestAirTomlTemplate(t *testing.T) {
 	// 验证模板内容
-	if !strings.Contains(AirTomlTemplate, "[build]") {
+	if !strings.Contains(content(t, AirTomlTemplate), "[build]") {
 		t.Errorf(".air.toml模板应该包含[build]配置")
 	}
 	
-	if !strings.Contains(AirTomlTemplate, "cmd = \"go build -o ./tmp/main .\"") {
+	if !strings.Contains(content(t, AirTomlTemplate), "cmd = \"go build -o ./tmp/main .\"") {
 		t.Errorf(".air.toml模板应该包含构建命令")
 	}
 	
-	if !strings.Contains(AirTomlTemplate, "bin = \"./tmp/main\"") {
+	if !strings.Contains(content(t, AirTomlTemplate), "bin = \"./tmp/main\"") {
 		t.Errorf(".air.toml模板应该包含二进制文件路径")
 	}
 	
-	if !strings.Contains(AirTomlTemplate, "include_ext = [\"go\", \"tpl\", \"tmpl\", \"html\"]") {
+	if !strings.Contains(content(t, AirTomlTemplate), "include_ext = [\"go\", \"tpl\", \"tmpl\", \"html\"]") {
 		t.Errorf(".air.toml模板应该包含包含的文件扩展名")
 	}
 	
-	if !strings.Contains(AirTomlTemplate, "exclude_dir = [\"assets\", \"tmp\", \"vendor\"]") {
+	if !strings.Contains(content(t, AirTomlTemplate), "exclude_dir = [\"assets\", \"tmp\", \"vendor\", \"testdata\"]") {
 		t.Errorf(".air.toml模板应该包含排除的目录")
 	}
 	
-	if !strings.Contains(AirTomlTemplate, "delay = 1000") {
+	if !strings.Contains(content(t, AirTomlTemplate), "delay = 1000") {
 		t.Errorf(".air.toml模板应该包含延迟配置")
 	}
 }
@@ -101,39 +117,39 @@ func TestAirTomlTemplate(t *testing.T) {
 // TestGitignoreTemplate 测试.gitignore模板
 func TestGitignoreTemplate(t *testing.T) {
 	// 验证模板内容
-	if !strings.Contains(GitignoreTemplate, "*.exe") {
+	if !strings.Contains(content(t, GitignoreTemplate), "*.exe") {
 		t.Errorf(".gitignore模板应该包含*.exe")
 	}
 	
-	if !strings.Contains(GitignoreTemplate, "*.exe~") {
+	if !strings.Contains(content(t, GitignoreTemplate), "*.exe~") {
 		t.Errorf(".gitignore模板应该包含*.exe~")
 	}
 	
-	if !strings.Contains(GitignoreTemplate, "*.dll") {
+	if !strings.Contains(content(t, GitignoreTemplate), "*.dll") {
 		t.Errorf(".gitignore模板应该包含*.dll")
 	}
 	
-	if !strings.Contains(GitignoreTemplate, "*.so") {
+	if !strings.Contains(content(t, GitignoreTemplate), "*.so") {
 		t.Errorf(".gitignore模板应该包含*.so")
 	}
 	
-	if !strings.Contains(GitignoreTemplate, "*.dylib") {
+	if !strings.Contains(content(t, GitignoreTemplate), "*.dylib") {
 		t.Errorf(".gitignore模板应该包含*.dylib")
 	}
 	
-	if !strings.Contains(GitignoreTemplate, "*.test") {
+	if !strings.Contains(content(t, GitignoreTemplate), "*.test") {
 		t.Errorf(".gitignore模板应该包含*.test")
 	}
 	
-	if !strings.Contains(GitignoreTemplate, "*.out") {
+	if !strings.Contains(content(t, GitignoreTemplate), "*.out") {
 		t.Errorf(".gitignore模板应该包含*.out")
 	}
 	
-	if !strings.Contains(GitignoreTemplate, "tmp/") {
+	if !strings.Contains(content(t, GitignoreTemplate), "tmp/") {
 		t.Errorf(".gitignore模板应该包含tmp/目录")
 	}
 	
-	if !strings.Contains(GitignoreTemplate, ".air.toml") {
+	if !strings.Contains(content(t, GitignoreTemplate), ".air.toml") {
 		t.Errorf(".gitignore模板应该包含.air.toml")
 	}
 }
@@ -142,7 +158,7 @@ func TestGitignoreTemplate(t *testing.T) {
 func TestReadmeTemplate(t *testing.T) {
 	// 测试模板格式化
 	projectName := "test-project"
-	formatted := fmt.Sprintf(ReadmeTemplate, projectName, projectName)
+	formatted := render(t, ReadmeTemplate, NewData(projectName))
 	
 	// 验证模板内容
 	if !strings.Contains(formatted, "# "+projectName) {
@@ -179,7 +195,7 @@ func TestNginxHTTPTemplateInTemplates(t *testing.T) {
 	// 测试模板格式化
 	domain := "test.example.com"
 	port := "8888"
-	formatted := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	formatted := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	// 验证模板内容
 	if !strings.Contains(formatted, "server_name "+domain) {
@@ -248,7 +264,7 @@ func TestNginxHTTPSTemplateInTemplates(t *testing.T) {
 	// 测试模板格式化
 	domain := "test.example.com"
 	port := "8888"
-	formatted := fmt.Sprintf(NginxHTTPSTemplate, domain, port, domain, domain, domain, domain, domain, domain)
+	formatted := render(t, NginxHTTPSTemplate, nginxData(domain, port))
 	
 	// 验证模板内容
 	if !strings.Contains(formatted, "server_name "+domain) {
@@ -275,27 +291,27 @@ func TestNginxHTTPSTemplateInTemplates(t *testing.T) {
 // TestNginxSetupScriptTemplateInTemplates 测试nginx设置脚本模板
 func TestNginxSetupScriptTemplateInTemplates(t *testing.T) {
 	// 验证模板内容
-	if !strings.Contains(NginxSetupScriptTemplate, "#!/bin/bash") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "#!/bin/bash") {
 		t.Errorf("nginx设置脚本模板应该包含shebang")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "nginx -t") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "nginx -t") {
 		t.Errorf("nginx设置脚本模板应该包含nginx配置测试")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "systemctl reload nginx") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "systemctl reload nginx") {
 		t.Errorf("nginx设置脚本模板应该包含nginx重载命令")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "ln -sf") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "ln -sf") {
 		t.Errorf("nginx设置脚本模板应该包含软链接创建")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "/etc/nginx/sites-available") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "/etc/nginx/sites-available") {
 		t.Errorf("nginx设置脚本模板应该包含sites-available路径")
 	}
 	
-	if !strings.Contains(NginxSetupScriptTemplate, "/etc/nginx/sites-enabled") {
+	if !strings.Contains(content(t, NginxSetupScriptTemplate), "/etc/nginx/sites-enabled") {
 		t.Errorf("nginx设置脚本模板应该包含sites-enabled路径")
 	}
 }
@@ -303,51 +319,51 @@ func TestNginxSetupScriptTemplateInTemplates(t *testing.T) {
 // TestCertbotScriptTemplateInTemplates 测试certbot脚本模板
 func TestCertbotScriptTemplateInTemplates(t *testing.T) {
 	// 验证模板内容
-	if !strings.Contains(CertbotScriptTemplate, "#!/bin/bash") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "#!/bin/bash") {
 		t.Errorf("certbot脚本模板应该包含shebang")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "certbot") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "certbot") {
 		t.Errorf("certbot脚本模板应该包含certbot命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "nginx -t") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "nginx -t") {
 		t.Errorf("certbot脚本模板应该包含nginx配置测试")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "systemctl reload nginx") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "systemctl reload nginx") {
 		t.Errorf("certbot脚本模板应该包含nginx重载命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "apt install") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "apt install") {
 		t.Errorf("certbot脚本模板应该包含apt安装命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "yum install") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "yum install") {
 		t.Errorf("certbot脚本模板应该包含yum安装命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "dnf install") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "dnf install") {
 		t.Errorf("certbot脚本模板应该包含dnf安装命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "Debian/Ubuntu") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "Debian/Ubuntu") {
 		t.Errorf("certbot脚本模板应该包含Debian/Ubuntu系统检测")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "CentOS/RHEL") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "CentOS/RHEL") {
 		t.Errorf("certbot脚本模板应该包含CentOS/RHEL系统检测")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "Fedora") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "Fedora") {
 		t.Errorf("certbot脚本模板应该包含Fedora系统检测")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "command -v certbot") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "command -v certbot") {
 		t.Errorf("certbot脚本模板应该包含certbot检查命令")
 	}
 	
-	if !strings.Contains(CertbotScriptTemplate, "certbot --nginx -d") {
+	if !strings.Contains(content(t, CertbotScriptTemplate), "certbot --nginx -d") {
 		t.Errorf("certbot脚本模板应该包含SSL证书申请命令")
 	}
 }
@@ -356,7 +372,7 @@ func TestCertbotScriptTemplateInTemplates(t *testing.T) {
 func TestTemplateVariables(t *testing.T) {
 	// 测试go.mod模板变量替换
 	projectName := "my-test-project"
-	formatted := fmt.Sprintf(GoModTemplate, projectName)
+	formatted := render(t, GoModTemplate, NewData(projectName))
 	
 	if !strings.Contains(formatted, "module "+projectName) {
 		t.Errorf("go.mod模板应该正确替换项目名: %s", projectName)
@@ -365,7 +381,7 @@ func TestTemplateVariables(t *testing.T) {
 	// 测试nginx模板变量替换
 	domain := "example.com"
 	port := "8080"
-	nginxConfig := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	nginxConfig := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(nginxConfig, "server_name "+domain) {
 		t.Errorf("nginx模板应该正确替换域名: %s", domain)
@@ -380,7 +396,7 @@ func TestTemplateVariables(t *testing.T) {
 func TestTemplateSpecialCharacters(t *testing.T) {
 	// 测试包含特殊字符的项目名
 	projectName := "test-project-with-special-chars_123"
-	formatted := fmt.Sprintf(GoModTemplate, projectName)
+	formatted := render(t, GoModTemplate, NewData(projectName))
 	
 	if !strings.Contains(formatted, "module "+projectName) {
 		t.Errorf("go.mod模板应该正确处理特殊字符项目名: %s", projectName)
@@ -389,7 +405,7 @@ func TestTemplateSpecialCharacters(t *testing.T) {
 	// 测试包含特殊字符的域名
 	domain := "test.example.com"
 	port := "8888"
-	nginxConfig := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	nginxConfig := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(nginxConfig, "server_name "+domain) {
 		t.Errorf("nginx模板应该正确处理特殊字符域名: %s", domain)
@@ -400,7 +416,7 @@ func TestTemplateSpecialCharacters(t *testing.T) {
 func TestTemplateEmptyValues(t *testing.T) {
 	// 测试空项目名
 	projectName := ""
-	formatted := fmt.Sprintf(GoModTemplate, projectName)
+	formatted := render(t, GoModTemplate, NewData(projectName))
 	
 	if !strings.Contains(formatted, "module ") {
 		t.Error("go.mod模板应该能处理空项目名")
@@ -409,7 +425,7 @@ func TestTemplateEmptyValues(t *testing.T) {
 	// 测试空域名
 	domain := ""
 	port := "8888"
-	nginxConfig := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	nginxConfig := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(nginxConfig, "server_name ") {
 		t.Error("nginx模板应该能处理空域名")
@@ -420,7 +436,7 @@ func TestTemplateEmptyValues(t *testing.T) {
 func TestTemplateLargeValues(t *testing.T) {
 	// 测试长项目名
 	projectName := strings.Repeat("a", 100)
-	formatted := fmt.Sprintf(GoModTemplate, projectName)
+	formatted := render(t, GoModTemplate, NewData(projectName))
 	
 	if !strings.Contains(formatted, "module "+projectName) {
 		t.Errorf("go.mod模板应该能处理长项目名")
@@ -429,7 +445,7 @@ func TestTemplateLargeValues(t *testing.T) {
 	// 测试长域名
 	domain := strings.Repeat("a", 50) + ".com"
 	port := "8888"
-	nginxConfig := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	nginxConfig := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(nginxConfig, "server_name "+domain) {
 		t.Errorf("nginx模板应该能处理长域名")
@@ -440,7 +456,7 @@ func TestTemplateLargeValues(t *testing.T) {
 func TestTemplateUnicode(t *testing.T) {
 	// 测试包含Unicode字符的项目名
 	projectName := "测试项目-🚀-🎉"
-	formatted := fmt.Sprintf(GoModTemplate, projectName)
+	formatted := render(t, GoModTemplate, NewData(projectName))
 	
 	if !strings.Contains(formatted, "module "+projectName) {
 		t.Errorf("go.mod模板应该能处理Unicode项目名: %s", projectName)
@@ -449,7 +465,7 @@ func TestTemplateUnicode(t *testing.T) {
 	// 测试包含Unicode字符的域名
 	domain := "测试域名.example.com"
 	port := "8888"
-	nginxConfig := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	nginxConfig := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	
 	if !strings.Contains(nginxConfig, "server_name "+domain) {
 		t.Errorf("nginx模板应该能处理Unicode域名: %s", domain)
@@ -460,7 +476,7 @@ func TestTemplateUnicode(t *testing.T) {
 func TestTemplateFormatting(t *testing.T) {
 	// 测试go.mod模板格式化
 	projectName := "test-project"
-	formatted := fmt.Sprintf(GoModTemplate, projectName)
+	formatted := render(t, GoModTemplate, NewData(projectName))
 	
 	// 验证基本结构
 	expectedLines := []string{
@@ -476,15 +492,15 @@ func TestTemplateFormatting(t *testing.T) {
 	}
 	
 	// 测试main.go模板格式化
-	if !strings.Contains(MainGoTemplate, "package main") {
+	if !strings.Contains(content(t, MainGoTemplate), "package main") {
 		t.Error("main.go模板应该包含package main")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "import") {
+	if !strings.Contains(content(t, MainGoTemplate), "import") {
 		t.Error("main.go模板应该包含import语句")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "func main()") {
+	if !strings.Contains(content(t, MainGoTemplate), "func main()") {
 		t.Error("main.go模板应该包含main函数")
 	}
 }
@@ -492,20 +508,20 @@ func TestTemplateFormatting(t *testing.T) {
 // TestTemplateConsistency 测试模板一致性
 func TestTemplateConsistency(t *testing.T) {
 	// 测试端口号一致性
-	if !strings.Contains(MainGoTemplate, ":8888") {
+	if !strings.Contains(content(t, MainGoTemplate), ":8888") {
 		t.Error("main.go模板应该使用端口8888")
 	}
 	
-	if !strings.Contains(NginxHTTPTemplate, "8888") {
+	if !strings.Contains(content(t, NginxHTTPTemplate), "8888") {
 		t.Error("nginx模板应该使用端口8888")
 	}
 	
 	// 测试路由一致性
-	if !strings.Contains(MainGoTemplate, "/health") {
+	if !strings.Contains(content(t, MainGoTemplate), "/health") {
 		t.Error("main.go模板应该包含health路由")
 	}
 	
-	if !strings.Contains(MainGoTemplate, "/api/v1") {
+	if !strings.Contains(content(t, MainGoTemplate), "/api/v1") {
 		t.Error("main.go模板应该包含API路由")
 	}
 }
@@ -514,7 +530,7 @@ func TestTemplateConsistency(t *testing.T) {
 func TestTemplateSecurity(t *testing.T) {
 	// 测试XSS防护
 	maliciousProjectName := "<script>alert('xss')</script>"
-	formatted := fmt.Sprintf(GoModTemplate, maliciousProjectName)
+	formatted := render(t, GoModTemplate, NewData(maliciousProjectName))
 	
 	// 验证恶意脚本没有被执行（只是作为字符串）
 	if !strings.Contains(formatted, maliciousProjectName) {
@@ -524,7 +540,7 @@ func TestTemplateSecurity(t *testing.T) {
 	// 测试SQL注入防护
 	sqlInjectionDomain := "'; DROP TABLE users; --"
 	port := "8888"
-	nginxConfig := fmt.Sprintf(NginxHTTPTemplate, sqlInjectionDomain, port, sqlInjectionDomain, sqlInjectionDomain)
+	nginxConfig := render(t, NginxHTTPTemplate, nginxData(sqlInjectionDomain, port))
 	
 	if !strings.Contains(nginxConfig, sqlInjectionDomain) {
 		t.Error("模板应该正确处理SQL注入尝试")
@@ -537,7 +553,7 @@ func TestTemplatePerformance(t *testing.T) {
 	projectName := "performance-test-project"
 	
 	for i := 0; i < 1000; i++ {
-		formatted := fmt.Sprintf(GoModTemplate, projectName)
+		formatted := render(t, GoModTemplate, NewData(projectName))
 		if !strings.Contains(formatted, "module "+projectName) {
 			t.Errorf("模板格式化失败，迭代 %d", i)
 		}
@@ -548,7 +564,7 @@ func TestTemplatePerformance(t *testing.T) {
 func TestTemplateEdgeCases(t *testing.T) {
 	// 测试非常长的项目名
 	veryLongProjectName := strings.Repeat("a", 1000)
-	formatted := fmt.Sprintf(GoModTemplate, veryLongProjectName)
+	formatted := render(t, GoModTemplate, NewData(veryLongProjectName))
 	
 	if !strings.Contains(formatted, "module "+veryLongProjectName) {
 		t.Error("模板应该能处理非常长的项目名")
@@ -556,7 +572,7 @@ func TestTemplateEdgeCases(t *testing.T) {
 	
 	// 测试包含换行符的项目名
 	projectNameWithNewlines := "test\nproject\nname"
-	formatted = fmt.Sprintf(GoModTemplate, projectNameWithNewlines)
+	formatted = render(t, GoModTemplate, NewData(projectNameWithNewlines))
 	
 	if !strings.Contains(formatted, "module "+projectNameWithNewlines) {
 		t.Error("模板应该能处理包含换行符的项目名")
@@ -564,7 +580,7 @@ func TestTemplateEdgeCases(t *testing.T) {
 	
 	// 测试包含制表符的项目名
 	projectNameWithTabs := "test\tproject\tname"
-	formatted = fmt.Sprintf(GoModTemplate, projectNameWithTabs)
+	formatted = render(t, GoModTemplate, NewData(projectNameWithTabs))
 	
 	if !strings.Contains(formatted, "module "+projectNameWithTabs) {
 		t.Error("模板应该能处理包含制表符的项目名")
@@ -575,7 +591,7 @@ func TestTemplateEdgeCases(t *testing.T) {
 func TestTemplateEncoding(t *testing.T) {
 	// 测试UTF-8编码
 	chineseProjectName := "中文项目名"
-	formatted := fmt.Sprintf(GoModTemplate, chineseProjectName)
+	formatted := render(t, GoModTemplate, NewData(chineseProjectName))
 	
 	if !strings.Contains(formatted, "module "+chineseProjectName) {
 		t.Errorf("模板应该正确处理UTF-8编码: %s", chineseProjectName)
@@ -583,7 +599,7 @@ func TestTemplateEncoding(t *testing.T) {
 	
 	// 测试emoji
 	emojiProjectName := "🚀🎉💻"
-	formatted = fmt.Sprintf(GoModTemplate, emojiProjectName)
+	formatted = render(t, GoModTemplate, NewData(emojiProjectName))
 	
 	if !strings.Contains(formatted, "module "+emojiProjectName) {
 		t.Errorf("模板应该正确处理emoji: %s", emojiProjectName)
@@ -609,22 +625,33 @@ func TestTemplateValidation(t *testing.T) {
 	}
 	
 	for _, tc := range templates {
-		if tc.template == "" {
+		raw, err := files.ReadFile("files/" + tc.template)
+		if err != nil {
+			t.Errorf("模板 %s 不存在: %v", tc.name, err)
+			continue
+		}
+		
+		rendered := content(t, tc.template)
+		if rendered == "" {
 			t.Errorf("模板 %s 不能为空", tc.name)
 		}
 		
 		// 检查模板长度
-		if len(tc.template) < 10 {
-			t.Errorf("模板 %s 内容太短: %d 字符", tc.name, len(tc.template))
+		if len(rendered) < 10 {
+			t.Errorf("模板 %s 内容太短: %d 字符", tc.name, len(rendered))
 		}
 		
-		// 检查模板是否包含必要的占位符
-		if tc.name == "GoModTemplate" && !strings.Contains(tc.template, "%s") {
-			t.Errorf("模板 %s 应该包含 %%s 占位符", tc.name)
+		// 检查模板不再使用位置占位符，而是引用数据模型字段
+		if strings.Contains(string(raw), "%s") {
+			t.Errorf("模板 %s 不应该包含 %%s 占位符", tc.name)
 		}
 		
-		if strings.Contains(tc.name, "Nginx") && !strings.Contains(tc.template, "%s") {
-			t.Errorf("nginx模板 %s 应该包含 %%s 占位符", tc.name)
+		if tc.name == "GoModTemplate" && !strings.Contains(string(raw), "{{.ModulePath}}") {
+			t.Errorf("模板 %s 应该引用 {{.ModulePath}}", tc.name)
+		}
+		
+		if strings.HasPrefix(tc.name, "NginxHTTP") && !strings.Contains(string(raw), "{{.Domain}}") {
+			t.Errorf("nginx模板 %s 应该引用 {{.Domain}}", tc.name)
 		}
 	}
 }
@@ -637,19 +664,19 @@ func TestTemplateIntegration(t *testing.T) {
 	port := "8888"
 	
 	// 测试go.mod模板
-	goModContent := fmt.Sprintf(GoModTemplate, projectName)
+	goModContent := render(t, GoModTemplate, NewData(projectName))
 	if !strings.Contains(goModContent, "module "+projectName) {
 		t.Error("go.mod模板集成测试失败")
 	}
 	
 	// 测试nginx模板
-	nginxContent := fmt.Sprintf(NginxHTTPTemplate, domain, port, domain, domain)
+	nginxContent := render(t, NginxHTTPTemplate, nginxData(domain, port))
 	if !strings.Contains(nginxContent, "server_name "+domain) {
 		t.Error("nginx模板集成测试失败")
 	}
 	
 	// 测试README模板
-	readmeContent := fmt.Sprintf(ReadmeTemplate, projectName, projectName)
+	readmeContent := render(t, ReadmeTemplate, NewData(projectName))
 	if !strings.Contains(readmeContent, projectName) {
 		t.Error("README模板集成测试失败")
 	}
@@ -663,9 +690,9 @@ func TestTemplateConcurrency(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		go func(id int) {
 			projectName := fmt.Sprintf("concurrent-project-%d", id)
-			formatted := fmt.Sprintf(GoModTemplate, projectName)
+			formatted, err := Render(GoModTemplate, NewData(projectName))
 			
-			if !strings.Contains(formatted, "module "+projectName) {
+			if err != nil || !strings.Contains(formatted, "module "+projectName) {
 				t.Errorf("并发模板格式化失败: %s", projectName)
 			}
 			
@@ -677,4 +704,104 @@ func TestTemplateConcurrency(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		<-done
 	}
-} 
\ No newline at end of file
+} 
+// TestNewData 测试模板数据默认值
+func TestNewData(t *testing.T) {
+	data := NewData("my-api")
+
+	if data.ProjectName != "my-api" || data.ModulePath != "my-api" {
+		t.Errorf("项目名和模块路径应该为 my-api, 实际得到 %s / %s", data.ProjectName, data.ModulePath)
+	}
+	if data.Port != "8888" {
+		t.Errorf("Port 期望 '8888', 实际得到 '%s'", data.Port)
+	}
+	if data.Domain != "your-domain.com" {
+		t.Errorf("Domain 期望 'your-domain.com', 实际得到 '%s'", data.Domain)
+	}
+	if data.GoVersion != "1.24" {
+		t.Errorf("GoVersion 期望 '1.24', 实际得到 '%s'", data.GoVersion)
+	}
+}
+
+// TestRenderDataFields 测试数据模型字段渲染到所有相关模板
+func TestRenderDataFields(t *testing.T) {
+	data := NewData("my-api")
+	data.ModulePath = "github.com/acme/my-api"
+	data.Port = "9000"
+	data.Domain = "api.acme.com"
+	data.GoVersion = "1.23"
+
+	goMod := render(t, GoModTemplate, data)
+	if !strings.Contains(goMod, "module github.com/acme/my-api") || !strings.Contains(goMod, "go 1.23") {
+		t.Errorf("go.mod应该使用模块路径和Go版本, 实际内容: %s", goMod)
+	}
+
+	if mainGo := render(t, MainGoTemplate, data); !strings.Contains(mainGo, `addr := ":9000"`) || !strings.Contains(mainGo, `os.Getenv("PORT")`) {
+		t.Error("main.go应该优先读取PORT环境变量，默认使用指定端口")
+	}
+
+	readme := render(t, ReadmeTemplate, data)
+	for _, expected := range []string{"# my-api", "http://localhost:9000", "config/api.acme.com"} {
+		if !strings.Contains(readme, expected) {
+			t.Errorf("README应该包含 %s", expected)
+		}
+	}
+
+	if script := render(t, NginxSetupScriptTemplate, data); !strings.Contains(script, "PORT=${PORT:-9000}") {
+		t.Error("nginx设置脚本的默认端口应该来自数据模型")
+	}
+}
+
+// TestDataProject 测试模板数据与项目清单互相转换
+func TestDataProject(t *testing.T) {
+	data := NewData("my-api")
+	data.Port = "9000"
+	data.Domain = "api.acme.com"
+	data.Docker = true
+	db, _ := LookupDatabase("mysql")
+	data.Database = &db
+
+	p := data.Project()
+	if p.Port != "9000" || p.Domain() != "api.acme.com" || p.Database != "mysql" {
+		t.Errorf("项目清单字段错误: %+v", p)
+	}
+	for _, component := range []string{"nginx", "dockerfile", "db"} {
+		if !p.HasComponent(component) {
+			t.Errorf("项目清单应该启用组件 %s", component)
+		}
+	}
+
+	restored := NewData("")
+	if err := restored.ApplyProject(p); err != nil {
+		t.Fatal(err)
+	}
+	if restored.ProjectName != "my-api" || restored.Port != "9000" || !restored.Nginx || !restored.Docker || restored.Database.Name != "mysql" {
+		t.Errorf("应用项目清单后的数据错误: %+v", restored)
+	}
+}
+
+// TestRenderUnknownTemplate 测试渲染不存在的模板
+func TestRenderUnknownTemplate(t *testing.T) {
+	if _, err := Render("project/missing.tmpl", NewData("x")); err == nil {
+		t.Error("渲染不存在的模板应该返回错误")
+	}
+}
+
+// TestNames 测试内嵌模板列表
+func TestNames(t *testing.T) {
+	names := Names()
+	expected := []string{GoModTemplate, MainGoTemplate, AirTomlTemplate, GitignoreTemplate, ReadmeTemplate,
+		NginxHTTPTemplate, NginxHTTPSTemplate, NginxSetupScriptTemplate, CertbotScriptTemplate}
+
+	for _, name := range expected {
+		found := false
+		for _, n := range names {
+			if n == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("模板列表应该包含 %s", name)
+		}
+	}
+}
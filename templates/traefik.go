@@ -0,0 +1,234 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TraefikTemplate Traefik动态配置模板
+const TraefikTemplate = "traefik/dynamic.yml.tmpl"
+
+// TraefikSetupScriptTemplate Traefik配置脚本模板
+const TraefikSetupScriptTemplate = "traefik/setup-traefik.sh.tmpl"
+
+// Traefik静态配置中约定的入口和证书解析器名称
+const (
+	TraefikWebEntryPoint    = "web"
+	TraefikSecureEntryPoint = "websecure"
+	TraefikCertResolver     = "letsencrypt"
+)
+
+// TraefikRouter Traefik动态配置中的一个router
+type TraefikRouter struct {
+	Name         string
+	Rule         string // 已加引号的YAML字符串
+	EntryPoint   string
+	Service      string
+	Middlewares  []string
+	TLS          bool
+	CertResolver string // 为空时使用tls.certificates中的自定义证书
+}
+
+// TraefikMiddleware Traefik动态配置中的一个middleware，只设置其中一种
+type TraefikMiddleware struct {
+	Name         string
+	StripPrefix  string   // 已加引号的前缀
+	RateAverage  int      // 每个周期的平均请求数
+	RatePeriod   string   // 1s 或 1m
+	RateBurst    int      // 0表示使用Traefik默认值
+	AllowList    []string // 已加引号的IP或CIDR
+	BasicAuth    []string // 已加引号的 user:hash
+	Headers      []Header // 值已加引号
+	Compress     bool
+	Encodings    string // 为空时使用Traefik默认的压缩算法
+	RedirectHTTP bool   // 重定向到HTTPS
+}
+
+// TraefikService Traefik动态配置中的一个service
+type TraefikService struct {
+	Name   string
+	URLs   []string // 已加引号的后端地址
+	Sticky bool     // 使用cookie保持会话，对应nginx的ip_hash
+}
+
+// TraefikConfig 一个域名的Traefik动态配置
+type TraefikConfig struct {
+	Routers     []TraefikRouter
+	Middlewares []TraefikMiddleware
+	Services    []TraefikService
+}
+
+// yamlQuote 返回YAML双引号字符串
+func yamlQuote(s string) string {
+	return strconv.Quote(s)
+}
+
+// TraefikEntryPoints 返回配置使用的入口
+func (d Data) TraefikEntryPoints() string {
+	if d.TLS != nil {
+		return TraefikWebEntryPoint + " 和 " + TraefikSecureEntryPoint
+	}
+	return TraefikWebEntryPoint
+}
+
+// TraefikCertResolver 返回使用Let's Encrypt时的证书解析器名称
+func (d Data) TraefikCertResolver() string {
+	return TraefikCertResolver
+}
+
+// TraefikCertificate 返回自定义证书，使用Let's Encrypt时返回nil
+func (d Data) TraefikCertificate() *TLS {
+	if d.TLS == nil || d.TLS.Cert == "" {
+		return nil
+	}
+	return d.TLS
+}
+
+// Traefik 返回域名的routers、middlewares和services，每个路由对应一个router；
+// Traefik不能直接提供静态文件，配置了root或alias时返回错误
+func (d Data) Traefik() (TraefikConfig, error) {
+	var c TraefikConfig
+	name := d.ConfigName()
+	entryPoint := TraefikWebEntryPoint
+	if d.TLS != nil {
+		entryPoint = TraefikSecureEntryPoint
+	}
+
+	// 站点级别的middleware
+	var siteMiddlewares []string
+	if d.Compression != nil && (d.Compression.Gzip || d.Compression.Brotli) {
+		compress := TraefikMiddleware{Name: name + "_compress", Compress: true}
+		if !d.Compression.Brotli {
+			compress.Encodings = "gzip"
+		}
+		c.Middlewares = append(c.Middlewares, compress)
+		siteMiddlewares = append(siteMiddlewares, compress.Name)
+	}
+
+	backend := TraefikService{Name: name + "_backend", Sticky: d.Upstream != nil && d.Upstream.Method == BalanceIPHash}
+	if d.Upstream == nil {
+		backend.URLs = []string{yamlQuote("http://localhost:" + d.Port)}
+	} else {
+		for _, s := range d.Upstream.Servers {
+			backend.URLs = append(backend.URLs, yamlQuote("http://"+s.Addr))
+		}
+	}
+	c.Services = append(c.Services, backend)
+
+	for i, l := range d.siteLocations() {
+		if l.Static() {
+			return c, fmt.Errorf("路由 %s: Traefik不能直接提供静态文件，请使用nginx或caddy", l.Path)
+		}
+		router := TraefikRouter{
+			Name:       name + "_" + strconv.Itoa(i+1),
+			Rule:       "Host(`" + d.Domain + "`)",
+			EntryPoint: entryPoint,
+			Service:    backend.Name,
+			TLS:        d.TLS != nil,
+		}
+		switch {
+		case l.Path == "/":
+		case strings.HasSuffix(l.Path, "/"):
+			router.Rule += " && PathPrefix(`" + l.Path + "`)"
+		default:
+			// /api 不能匹配 /apifoo
+			router.Rule += " && (Path(`" + l.Path + "`) || PathPrefix(`" + l.Path + "/`))"
+		}
+		router.Rule = yamlQuote(router.Rule)
+		if router.TLS && d.TLS.Cert == "" {
+			router.CertResolver = TraefikCertResolver
+		}
+		if l.Proxy != "" {
+			target, err := proxyTarget(l.Proxy)
+			if err != nil {
+				return c, err
+			}
+			router.Service = router.Name
+			c.Services = append(c.Services, TraefikService{Name: router.Name, URLs: []string{yamlQuote(target)}})
+		}
+
+		var middlewares []TraefikMiddleware
+		if len(l.Allow) > 0 {
+			allow := TraefikMiddleware{Name: router.Name + "_allow"}
+			for _, addr := range l.Allow {
+				allow.AllowList = append(allow.AllowList, yamlQuote(addr))
+			}
+			middlewares = append(middlewares, allow)
+		}
+		if l.Rate != "" {
+			average, period, _ := strings.Cut(l.Rate, "r/")
+			limit := TraefikMiddleware{Name: router.Name + "_ratelimit", RatePeriod: "1" + period, RateBurst: l.Burst}
+			limit.RateAverage, _ = strconv.Atoi(average)
+			middlewares = append(middlewares, limit)
+		}
+		if l.Auth {
+			auth := TraefikMiddleware{Name: router.Name + "_auth"}
+			for _, cred := range d.Credentials {
+				auth.BasicAuth = append(auth.BasicAuth, yamlQuote(cred.User+":"+cred.Hash))
+			}
+			middlewares = append(middlewares, auth)
+		}
+		if l.StripPrefix {
+			middlewares = append(middlewares, TraefikMiddleware{Name: router.Name + "_strip", StripPrefix: yamlQuote(strings.TrimRight(l.Path, "/"))})
+		}
+		if len(l.Headers) > 0 {
+			headers := TraefikMiddleware{Name: router.Name + "_headers"}
+			for _, h := range sortedHeaders(l.Headers) {
+				headers.Headers = append(headers.Headers, Header{h.Name, yamlQuote(h.Value)})
+			}
+			middlewares = append(middlewares, headers)
+		}
+		for _, m := range middlewares {
+			router.Middlewares = append(router.Middlewares, m.Name)
+		}
+		router.Middlewares = append(router.Middlewares, siteMiddlewares...)
+		c.Middlewares = append(c.Middlewares, middlewares...)
+		c.Routers = append(c.Routers, router)
+	}
+
+	// HTTPS站点把HTTP请求重定向到HTTPS
+	if d.TLS != nil {
+		redirect := TraefikMiddleware{Name: name + "_https", RedirectHTTP: true}
+		c.Middlewares = append(c.Middlewares, redirect)
+		c.Routers = append(c.Routers, TraefikRouter{
+			Name:        name + "_http",
+			Rule:        yamlQuote("Host(`" + d.Domain + "`)"),
+			EntryPoint:  TraefikWebEntryPoint,
+			Service:     "noop@internal",
+			Middlewares: []string{redirect.Name},
+		})
+	}
+	return c, nil
+}
+
+// TraefikUnsupported 返回Traefik不支持、生成时被忽略的设置
+func (d Data) TraefikUnsupported() []string {
+	var ignored []string
+	if len(d.Cache) > 0 {
+		ignored = append(ignored, "按扩展名的缓存规则")
+	}
+	if d.Upstream != nil {
+		if d.Upstream.Method == BalanceLeastConn {
+			ignored = append(ignored, "least_conn（使用轮询）")
+		}
+		if d.upstreamWeighted() {
+			ignored = append(ignored, "后端的 weight")
+		}
+		for _, s := range d.Upstream.Servers {
+			if s.MaxFails != nil || s.FailTimeout != "" {
+				ignored = append(ignored, "后端的 max_fails 和 fail_timeout（需要在service中配置healthCheck）")
+				break
+			}
+		}
+	}
+	for _, l := range d.Locations {
+		if len(l.Deny) > 0 {
+			ignored = append(ignored, fmt.Sprintf("路由 %s 的 deny（Traefik只支持allow列表）", l.Path))
+		}
+		if l.ConnectTimeout != "" || l.ReadTimeout != "" || l.SendTimeout != "" {
+			ignored = append(ignored, fmt.Sprintf("路由 %s 的超时（需要在serversTransport中配置）", l.Path))
+		}
+	}
+	return ignored
+}
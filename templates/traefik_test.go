@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestTraefikTemplate 测试使用与nginx相同的站点配置渲染Traefik动态配置
+func TestTraefikTemplate(t *testing.T) {
+	data := nginxData("dev.example.com", "8888")
+	formatted := render(t, TraefikTemplate, data)
+	for _, want := range []string{
+		"    dev_example_com_1:\n      rule: \"Host(`dev.example.com`)\"\n      entryPoints:\n        - web\n      service: dev_example_com_backend\n",
+		"          - url: \"http://localhost:8888\"\n",
+	} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("Traefik配置应该包含 %q:\n%s", want, formatted)
+		}
+	}
+	if strings.Contains(formatted, "tls:") || strings.Contains(formatted, "middlewares:") {
+		t.Errorf("HTTP站点不应该包含tls和middlewares:\n%s", formatted)
+	}
+
+	data.TLS = &TLS{}
+	data.Upstream = &Upstream{Method: BalanceIPHash, Servers: []UpstreamServer{{Addr: "10.0.0.1:8080"}, {Addr: "10.0.0.2:8080"}}}
+	data.Locations = []Location{
+		{Path: "/api/", Proxy: "9001", StripPrefix: true, Rate: "10r/s", Burst: 20},
+		{Path: "/", Allow: []string{"10.0.0.0/8"}, Auth: true},
+	}
+	data.Compression = &Compression{Gzip: true}
+	data.Credentials = []Credential{{User: "alice", Hash: "$apr1$salt$hash"}}
+	formatted = render(t, TraefikTemplate, data)
+	for _, want := range []string{
+		"rule: \"Host(`dev.example.com`) && PathPrefix(`/api/`)\"",
+		"      service: dev_example_com_1\n      middlewares:\n        - dev_example_com_1_ratelimit\n        - dev_example_com_1_strip\n        - dev_example_com_compress\n" +
+			"      tls:\n        certResolver: letsencrypt\n",
+		"      rateLimit:\n        average: 10\n        period: 1s\n        burst: 20\n",
+		"      stripPrefix:\n        prefixes:\n          - \"/api\"\n",
+		"      ipAllowList:\n        sourceRange:\n          - \"10.0.0.0/8\"\n",
+		"      basicAuth:\n        users:\n          - \"alice:$apr1$salt$hash\"\n",
+		"      compress:\n        encodings:\n          - gzip\n",
+		"    dev_example_com_http:\n      rule: \"Host(`dev.example.com`)\"\n      entryPoints:\n        - web\n      service: noop@internal\n",
+		"      redirectScheme:\n        scheme: https\n",
+		"        sticky:\n          cookie: {}\n",
+		"          - url: \"http://localhost:9001\"\n",
+	} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("Traefik配置应该包含 %q:\n%s", want, formatted)
+		}
+	}
+
+	data.TLS = &TLS{Cert: "/etc/ssl/dev.pem", Key: "/etc/ssl/dev.key"}
+	formatted = render(t, TraefikTemplate, data)
+	if strings.Contains(formatted, "certResolver") || !strings.Contains(formatted, "    - certFile: /etc/ssl/dev.pem\n      keyFile: /etc/ssl/dev.key") {
+		t.Errorf("自定义证书应该写入tls.certificates:\n%s", formatted)
+	}
+
+	data.Locations = []Location{{Path: "/", Root: "/srv/www"}}
+	if _, err := data.Traefik(); err == nil {
+		t.Error("静态文件路由应该返回错误")
+	}
+}
+
+// TestTraefikStripPrefixMatch 测试去掉前缀的路由只匹配前缀本身和它下面的路径，与TestNginxStripPrefixRewrite对应
+func TestTraefikStripPrefixMatch(t *testing.T) {
+	data := nginxData("dev.example.com", "8888")
+	data.Locations = []Location{{Path: "/api", Proxy: "9001", StripPrefix: true}}
+	c, err := data.Traefik()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule, _ := strconv.Unquote(c.Routers[0].Rule)
+	prefix, _ := strconv.Unquote(c.Middlewares[0].StripPrefix)
+	paths := regexp.MustCompile("\\bPath\\(`([^`]*)`\\)").FindAllStringSubmatch(rule, -1)
+	prefixes := regexp.MustCompile("PathPrefix\\(`([^`]*)`\\)").FindAllStringSubmatch(rule, -1)
+	// 按Traefik的规则匹配路径，stripPrefix去掉前缀后路径为空时转发 /；没有匹配时由 / 路由原样转发
+	forward := func(uri string) string {
+		matched := false
+		for _, m := range paths {
+			matched = matched || uri == m[1]
+		}
+		for _, m := range prefixes {
+			matched = matched || strings.HasPrefix(uri, m[1])
+		}
+		if !matched {
+			return uri
+		}
+		if rest := strings.TrimPrefix(uri, prefix); rest != "" {
+			return rest
+		}
+		return "/"
+	}
+	for uri, want := range map[string]string{"/api": "/", "/api/": "/", "/api/users/1": "/users/1", "/apifoo": "/apifoo"} {
+		if got := forward(uri); got != want {
+			t.Errorf("%s 应该转发为 %s, 实际得到 %s (rule: %s)", uri, want, got, rule)
+		}
+	}
+}
+
+// TestTraefikUnsupported 测试Traefik不支持的设置
+func TestTraefikUnsupported(t *testing.T) {
+	data := nginxData("dev.example.com", "8888")
+	if ignored := data.TraefikUnsupported(); len(ignored) != 0 {
+		t.Errorf("默认配置不应该有被忽略的设置: %v", ignored)
+	}
+	data.Cache = []CacheRule{{Extensions: []string{"js"}, Expires: "30d"}}
+	data.Upstream = &Upstream{Method: BalanceLeastConn, Servers: []UpstreamServer{{Addr: "10.0.0.1:8080", Weight: 3}}}
+	data.Locations = []Location{{Path: "/", Deny: []string{"10.0.0.5"}, ReadTimeout: "60s"}}
+	if ignored := data.TraefikUnsupported(); len(ignored) != 5 {
+		t.Errorf("缓存、least_conn、weight、deny和超时应该被忽略: %v", ignored)
+	}
+}
@@ -0,0 +1,194 @@
+package templates
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// 负载均衡方式
+const (
+	BalanceRoundRobin = "round_robin"
+	BalanceLeastConn  = "least_conn"
+	BalanceIPHash     = "ip_hash"
+)
+
+// BalanceMethods 支持的负载均衡方式，第一个为默认值
+var BalanceMethods = []string{BalanceRoundRobin, BalanceLeastConn, BalanceIPHash}
+
+// nginxTime nginx的时间参数，如 10、500ms、30s、1m
+var nginxTime = regexp.MustCompile(`^[0-9]+(ms|s|m|h|d)?$`)
+
+// UpstreamServer upstream中的一个后端
+type UpstreamServer struct {
+	Addr        string // host:port 或 unix:/path
+	Weight      int    // 权重，0表示使用nginx默认值1
+	MaxFails    *int   // 失败次数阈值，nil表示使用nginx默认值1
+	FailTimeout string // 失败统计周期和摘除时间，如 30s
+}
+
+// Upstream nginx负载均衡配置
+type Upstream struct {
+	Method    string // 负载均衡方式，空值等同于round_robin
+	Keepalive int    // 每个worker保留的空闲长连接数，0表示不复用连接
+	Servers   []UpstreamServer
+}
+
+// ParseUpstreamServer 解析命令行中的后端，格式为 addr[,weight=N][,max_fails=N][,fail_timeout=T]
+func ParseUpstreamServer(spec string) (UpstreamServer, error) {
+	parts := strings.Split(spec, ",")
+	server := UpstreamServer{Addr: strings.TrimSpace(parts[0])}
+	if err := validateUpstreamAddr(server.Addr); err != nil {
+		return server, err
+	}
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return server, fmt.Errorf("后端参数格式应为 key=value: %s", part)
+		}
+		if err := server.SetOption(key, value); err != nil {
+			return server, err
+		}
+	}
+	return server, nil
+}
+
+// SetOption 设置后端参数，key为nginx的参数名称 weight、max_fails 或 fail_timeout
+func (s *UpstreamServer) SetOption(key, value string) error {
+	switch key {
+	case "weight":
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 1 {
+			return fmt.Errorf("weight 应为正整数: %s", value)
+		}
+		s.Weight = weight
+	case "max_fails":
+		maxFails, err := strconv.Atoi(value)
+		if err != nil || maxFails < 0 {
+			return fmt.Errorf("max_fails 应为非负整数: %s", value)
+		}
+		s.MaxFails = &maxFails
+	case "fail_timeout":
+		if !nginxTime.MatchString(value) {
+			return fmt.Errorf("fail_timeout 应为nginx时间格式（如 10s、1m）: %s", value)
+		}
+		s.FailTimeout = value
+	default:
+		return fmt.Errorf("未知的后端参数: %s，可选 weight、max_fails、fail_timeout", key)
+	}
+	return nil
+}
+
+// String 返回nginx server指令的参数部分
+func (s UpstreamServer) String() string {
+	parts := []string{s.Addr}
+	if s.Weight > 0 {
+		parts = append(parts, fmt.Sprintf("weight=%d", s.Weight))
+	}
+	if s.MaxFails != nil {
+		parts = append(parts, fmt.Sprintf("max_fails=%d", *s.MaxFails))
+	}
+	if s.FailTimeout != "" {
+		parts = append(parts, "fail_timeout="+s.FailTimeout)
+	}
+	return strings.Join(parts, " ")
+}
+
+// validateUpstreamAddr 检查后端地址，不带端口的主机名使用nginx默认的80端口
+func validateUpstreamAddr(addr string) error {
+	if addr == "" || strings.ContainsAny(addr, " \t;{}") {
+		return fmt.Errorf("无效的后端地址: %q", addr)
+	}
+	if strings.HasPrefix(addr, "unix:") {
+		return nil
+	}
+	if !strings.Contains(addr, ":") {
+		return nil
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("无效的后端地址 %s: %v", addr, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("无效的后端端口: %s", addr)
+	}
+	return nil
+}
+
+// Validate 检查负载均衡配置
+func (u *Upstream) Validate() error {
+	if len(u.Servers) == 0 {
+		return fmt.Errorf("upstream 至少需要一个后端")
+	}
+	if u.Method != "" && !contains(BalanceMethods, u.Method) {
+		return fmt.Errorf("不支持的负载均衡方式: %s，可选 %s", u.Method, strings.Join(BalanceMethods, ", "))
+	}
+	if u.Keepalive < 0 {
+		return fmt.Errorf("keepalive 不能为负数: %d", u.Keepalive)
+	}
+	for _, server := range u.Servers {
+		if err := validateUpstreamAddr(server.Addr); err != nil {
+			return err
+		}
+		if server.Weight < 0 || (server.MaxFails != nil && *server.MaxFails < 0) {
+			return fmt.Errorf("后端 %s 的参数无效", server.Addr)
+		}
+		if server.FailTimeout != "" && !nginxTime.MatchString(server.FailTimeout) {
+			return fmt.Errorf("后端 %s 的 fail_timeout 无效: %s", server.Addr, server.FailTimeout)
+		}
+	}
+	return nil
+}
+
+// Project 转换为项目清单中的配置
+func (u *Upstream) Project() *config.ProjectUpstream {
+	p := &config.ProjectUpstream{Method: u.Method, Keepalive: u.Keepalive}
+	for _, server := range u.Servers {
+		p.Servers = append(p.Servers, config.ProjectUpstreamServer(server))
+	}
+	return p
+}
+
+// NewUpstream 从项目清单中的配置创建负载均衡配置
+func NewUpstream(p *config.ProjectUpstream) (*Upstream, error) {
+	u := &Upstream{Method: p.Method, Keepalive: p.Keepalive}
+	for _, server := range p.Servers {
+		u.Servers = append(u.Servers, UpstreamServer(server))
+	}
+	if err := u.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", config.ProjectFile, err)
+	}
+	return u, nil
+}
+
+// UpstreamName 返回域名配置中upstream块的名称，由域名生成以免多个域名的配置互相冲突
+func (d Data) UpstreamName() string {
+	return d.ConfigName() + "_backend"
+}
+
+// ProxyPass 返回proxy_pass的代理目标，配置了upstream时指向upstream块
+func (d Data) ProxyPass() string {
+	if d.Upstream != nil {
+		return "http://" + d.UpstreamName()
+	}
+	return "http://localhost:" + d.Port
+}
+
+// UpstreamKeepalive 判断是否复用到后端的长连接，此时Connection头需要按请求是否升级协议设置
+func (d Data) UpstreamKeepalive() bool {
+	return d.Upstream != nil && d.Upstream.Keepalive > 0
+}
+
+// contains 判断字符串切片是否包含指定值
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// TestParseUpstreamServer 测试解析命令行中的后端
+func TestParseUpstreamServer(t *testing.T) {
+	server, err := ParseUpstreamServer("127.0.0.1:8001,weight=3,max_fails=0,fail_timeout=30s")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if got := server.String(); got != "127.0.0.1:8001 weight=3 max_fails=0 fail_timeout=30s" {
+		t.Errorf("String() 返回 %q", got)
+	}
+
+	for _, spec := range []string{"backend.internal", "[::1]:8001", "unix:/run/app.sock"} {
+		if _, err := ParseUpstreamServer(spec); err != nil {
+			t.Errorf("%s 应该是有效的后端: %v", spec, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"127.0.0.1:http",
+		"127.0.0.1:70000",
+		"127.0.0.1:8001,weight=0",
+		"127.0.0.1:8001,max_fails=-1",
+		"127.0.0.1:8001,fail_timeout=soon",
+		"127.0.0.1:8001,backup",
+		"127.0.0.1:8001,slow_start=10s",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseUpstreamServer(spec); err == nil {
+			t.Errorf("%q 应该返回错误", spec)
+		}
+	}
+}
+
+// TestUpstreamValidate 测试负载均衡配置检查
+func TestUpstreamValidate(t *testing.T) {
+	servers := []UpstreamServer{{Addr: "127.0.0.1:8001"}}
+	cases := []struct {
+		name     string
+		upstream Upstream
+		valid    bool
+	}{
+		{"默认方式", Upstream{Servers: servers}, true},
+		{"least_conn", Upstream{Method: BalanceLeastConn, Keepalive: 32, Servers: servers}, true},
+		{"没有后端", Upstream{Method: BalanceIPHash}, false},
+		{"未知方式", Upstream{Method: "random", Servers: servers}, false},
+		{"负数keepalive", Upstream{Keepalive: -1, Servers: servers}, false},
+	}
+	for _, c := range cases {
+		if err := c.upstream.Validate(); (err == nil) != c.valid {
+			t.Errorf("%s: Validate() 返回 %v", c.name, err)
+		}
+	}
+}
+
+// TestNewUpstream 测试与项目清单之间的转换
+func TestNewUpstream(t *testing.T) {
+	maxFails := 2
+	p := &config.ProjectUpstream{
+		Method:    BalanceIPHash,
+		Keepalive: 8,
+		Servers:   []config.ProjectUpstreamServer{{Addr: "10.0.0.1:80", Weight: 2, MaxFails: &maxFails, FailTimeout: "10s"}},
+	}
+	upstream, err := NewUpstream(p)
+	if err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+	if got := upstream.Servers[0].String(); got != "10.0.0.1:80 weight=2 max_fails=2 fail_timeout=10s" {
+		t.Errorf("后端参数转换错误: %s", got)
+	}
+	if back := upstream.Project(); back.Method != p.Method || back.Keepalive != p.Keepalive || *back.Servers[0].MaxFails != 2 {
+		t.Errorf("转换回项目清单错误: %+v", back)
+	}
+
+	if _, err := NewUpstream(&config.ProjectUpstream{Method: "random"}); err == nil || !strings.Contains(err.Error(), config.ProjectFile) {
+		t.Errorf("无效配置的错误应该包含清单文件名, 实际得到 %v", err)
+	}
+}
+
+// TestNginxUpstreamTemplate 测试渲染upstream块
+func TestNginxUpstreamTemplate(t *testing.T) {
+	data := nginxData("api.example.com", "8888")
+	maxFails := 3
+	data.Upstream = &Upstream{
+		Method:    BalanceLeastConn,
+		Keepalive: 16,
+		Servers: []UpstreamServer{
+			{Addr: "127.0.0.1:8001", Weight: 2, MaxFails: &maxFails, FailTimeout: "30s"},
+			{Addr: "127.0.0.1:8002"},
+		},
+	}
+
+	for _, name := range []string{NginxHTTPTemplate, NginxHTTPSTemplate} {
+		formatted := render(t, name, data)
+		for _, want := range []string{
+			"upstream api_example_com_backend {",
+			"    least_conn;",
+			"    server 127.0.0.1:8001 weight=2 max_fails=3 fail_timeout=30s;",
+			"    server 127.0.0.1:8002;",
+			"    keepalive 16;",
+			"map $http_upgrade $api_example_com_backend_connection {",
+			"proxy_pass http://api_example_com_backend;",
+			"proxy_set_header Connection $api_example_com_backend_connection;",
+		} {
+			if !strings.Contains(formatted, want) {
+				t.Errorf("%s 应该包含 %q", name, want)
+			}
+		}
+		if strings.Contains(formatted, "localhost:8888") {
+			t.Errorf("%s 配置了upstream时不应该代理到本机端口", name)
+		}
+	}
+
+	// 轮询和不复用连接时不输出对应指令
+	data.Upstream.Method = ""
+	data.Upstream.Keepalive = 0
+	formatted := render(t, NginxHTTPTemplate, data)
+	for _, unwanted := range []string{"least_conn", "round_robin", "keepalive", "map $http_upgrade"} {
+		if strings.Contains(formatted, unwanted) {
+			t.Errorf("不应该包含 %q", unwanted)
+		}
+	}
+	if !strings.Contains(formatted, `proxy_set_header Connection "upgrade";`) {
+		t.Error("不复用连接时应该保持原有的Connection头")
+	}
+	if !strings.HasPrefix(formatted, "# 负载均衡后端\nupstream") {
+		t.Errorf("upstream块应该位于配置开头:\n%s", formatted)
+	}
+}
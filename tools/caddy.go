@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/nginx"
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// CaddyManager Caddy配置管理器
+type CaddyManager struct {
+	proxySite
+}
+
+// NewCaddyManager 创建新的Caddy管理器
+func NewCaddyManager() *CaddyManager {
+	return &CaddyManager{newProxySite()}
+}
+
+// GenerateConfig 生成域名的Caddyfile站点配置
+func (cm *CaddyManager) GenerateConfig(domain, projectPath string, port string) error {
+	if strings.TrimSpace(domain) == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	if len(cm.authUsers) > 0 && cm.authHash != nginx.HashBcrypt {
+		return fmt.Errorf("Caddy只支持bcrypt密码哈希，请使用 --auth-hash bcrypt")
+	}
+	data := cm.siteData(domain, port)
+	// Caddy的basic_auth只支持bcrypt哈希，写入htpasswd文件之前检查
+	credentials, err := cm.generateHtpasswd(data, projectPath, func(c templates.Credential) error {
+		if !strings.HasPrefix(c.Hash, "$2") {
+			return fmt.Errorf("Caddy只支持bcrypt密码哈希，用户 %s 需要使用 --auth-hash bcrypt 重新设置密码", c.User)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	data.Credentials = credentials
+	cm.warnUnsupported("Caddy", data.CaddyUnsupported())
+
+	configFile := filepath.Join(projectPath, "config", domain+".caddy")
+	content, err := templates.Render(templates.CaddyTemplate, data)
+	if err != nil {
+		return fmt.Errorf("渲染Caddy配置失败: %v", err)
+	}
+	if err := cm.writer.WriteFile(configFile, []byte(content), config.FilePermission); err != nil {
+		return fmt.Errorf("生成Caddy配置文件失败: %v", err)
+	}
+	cm.fileSuccess(configFile, "Caddy配置文件已生成: %s", configFile)
+	return nil
+}
+
+// GenerateAll 生成所有Caddy相关文件
+func (cm *CaddyManager) GenerateAll(domain, projectPath string, port string) error {
+	if err := cm.GenerateConfig(domain, projectPath, port); err != nil {
+		return err
+	}
+	setupScript := filepath.Join(projectPath, "config", "setup-caddy.sh")
+	if err := cm.writeScript(templates.CaddySetupScriptTemplate, setupScript, templates.NewData("")); err != nil {
+		return err
+	}
+	cm.success("所有Caddy相关文件已生成完成")
+	return nil
+}
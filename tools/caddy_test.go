@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/nginx"
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// TestCaddyGenerateAll 测试生成Caddyfile站点配置和配置脚本
+func TestCaddyGenerateAll(t *testing.T) {
+	manager := NewCaddyManager()
+	tempDir := t.TempDir()
+	manager.SetSite(templates.Data{Locations: []templates.Location{{Path: "/", Auth: true}}})
+	manager.SetAuthUsers([]AuthUser{{Name: "alice", Password: "secret"}}, nginx.HashBcrypt)
+	if err := manager.GenerateAll("test.example.com", tempDir, "9000"); err != nil {
+		t.Fatalf("生成Caddy配置失败: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "config", "test.example.com.caddy"))
+	if err != nil {
+		t.Fatalf("读取Caddy配置失败: %v", err)
+	}
+	for _, want := range []string{"http://test.example.com {", "reverse_proxy localhost:9000", "alice $2a$"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Caddy配置应该包含 %q:\n%s", want, content)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(tempDir, "config", "setup-caddy.sh")); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("配置脚本应该被生成且可执行: %v", err)
+	}
+
+	// htpasswd中已有非bcrypt哈希时返回错误
+	manager = NewCaddyManager()
+	manager.SetSite(templates.Data{Locations: []templates.Location{{Path: "/", Auth: true}}})
+	htpasswd := filepath.Join(tempDir, "config", "test.example.com.htpasswd")
+	if err := os.WriteFile(htpasswd, []byte("bob:$apr1$salt$hash\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.GenerateConfig("test.example.com", tempDir, "9000"); err == nil {
+		t.Error("apr1密码哈希应该返回错误")
+	}
+	manager.SetAuthUsers([]AuthUser{{Name: "bob", Password: "secret"}}, nginx.HashAPR1)
+	if err := manager.GenerateConfig("test.example.com", tempDir, "9000"); err == nil {
+		t.Error("指定apr1算法应该返回错误")
+	}
+
+	// 合并后仍有apr1哈希时不修改htpasswd文件
+	manager.SetAuthUsers([]AuthUser{{Name: "carol", Password: "secret"}}, nginx.HashBcrypt)
+	if err := manager.GenerateConfig("test.example.com", tempDir, "9000"); err == nil {
+		t.Error("htpasswd中保留的apr1哈希应该返回错误")
+	}
+	if content, _ := os.ReadFile(htpasswd); string(content) != "bob:$apr1$salt$hash\n" {
+		t.Errorf("检查失败时不应该修改htpasswd文件: %q", content)
+	}
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestUnifiedDiffEqual 测试内容相同时没有输出
+func TestUnifiedDiffEqual(t *testing.T) {
+	if diff := UnifiedDiff("a", "b", "x\ny\n", "x\ny\n"); diff != "" {
+		t.Errorf("内容相同时应返回空字符串, 实际 %q", diff)
+	}
+}
+
+// TestUnifiedDiff 测试统一diff格式与上下文
+func TestUnifiedDiff(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, "line"+string(rune('a'+i)))
+	}
+	old := strings.Join(lines, "\n") + "\n"
+	lines[2] = "changed"
+	lines[17] = "changed too"
+	updated := strings.Join(lines, "\n") + "\n"
+
+	diff := UnifiedDiff("a/conf", "b/conf", old, updated)
+	expect := `--- a/conf
++++ b/conf
+@@ -1,6 +1,6 @@
+ lineb
+ linec
+-lined
++changed
+ linee
+ linef
+ lineg
+@@ -15,6 +15,6 @@
+ linep
+ lineq
+ liner
+-lines
++changed too
+ linet
+ lineu
+`
+	if diff != expect {
+		t.Errorf("diff输出错误:\n%s\n期望:\n%s", diff, expect)
+	}
+}
+
+// TestUnifiedDiffNewFile 测试新文件的diff
+func TestUnifiedDiffNewFile(t *testing.T) {
+	diff := UnifiedDiff("/dev/null", "b/new", "", "one\ntwo\n")
+	expect := "--- /dev/null\n+++ b/new\n@@ -0,0 +1,2 @@\n+one\n+two\n"
+	if diff != expect {
+		t.Errorf("新文件diff错误:\n%q\n期望:\n%q", diff, expect)
+	}
+}
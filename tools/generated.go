@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// GeneratedFile 清单中记录的单个生成文件
+type GeneratedFile struct {
+	SHA256    string    `json:"sha256"`           // 工具最后一次写入的内容哈希
+	Action    string    `json:"action"`           // 最后一次生成时的处理方式
+	Backup    string    `json:"backup,omitempty"` // 覆盖前的备份文件
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GeneratedManifest 项目中由工具生成的文件清单，用于识别被手动修改过的文件
+type GeneratedManifest struct {
+	root    string
+	changed bool
+	Files   map[string]GeneratedFile `json:"files"` // 键为相对项目根目录的路径
+}
+
+// LoadGeneratedManifest 加载项目根目录下的生成文件清单，文件不存在时返回空清单
+func LoadGeneratedManifest(root string) (*GeneratedManifest, error) {
+	m := &GeneratedManifest{root: root, Files: map[string]GeneratedFile{}}
+
+	content, err := os.ReadFile(filepath.Join(root, config.GeneratedManifest))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取生成文件清单失败: %v", err)
+	}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("解析生成文件清单失败: %v", err)
+	}
+	if m.Files == nil {
+		m.Files = map[string]GeneratedFile{}
+	}
+	return m, nil
+}
+
+// Root 返回清单所属的项目根目录
+func (m *GeneratedManifest) Root() string {
+	return m.root
+}
+
+// key 返回文件在清单中的键，文件不在项目目录内时返回false
+func (m *GeneratedManifest) key(path string) (string, bool) {
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Modified 判断已有文件是否被手动修改过：未被工具记录或内容与记录的哈希不一致
+func (m *GeneratedManifest) Modified(path string, content []byte) bool {
+	key, ok := m.key(path)
+	if !ok {
+		return true
+	}
+	file, ok := m.Files[key]
+	return !ok || file.SHA256 != hashContent(content)
+}
+
+// Record 记录一次生成结果，跳过的文件保留原来的哈希
+func (m *GeneratedManifest) Record(path string, content []byte, action, backup string) {
+	key, ok := m.key(path)
+	if !ok {
+		return
+	}
+
+	file := m.Files[key]
+	if action != ChangeSkip {
+		file.SHA256 = hashContent(content)
+	}
+	file.Action = action
+	file.Backup = backup
+	file.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	m.Files[key] = file
+	m.changed = true
+}
+
+// BackupPath 返回文件在本次生成中的备份路径
+func (m *GeneratedManifest) BackupPath(path string, at time.Time) string {
+	key, ok := m.key(path)
+	if !ok {
+		return path + "." + at.Format(config.BackupTimeFormat) + ".bak"
+	}
+	return filepath.Join(m.root, config.BackupDir, at.Format(config.BackupTimeFormat), filepath.FromSlash(key))
+}
+
+// Save 清单有变化时写回磁盘
+func (m *GeneratedManifest) Save() error {
+	if !m.changed {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(m.root, config.GeneratedManifest)
+	if err := os.MkdirAll(filepath.Dir(path), config.DirPermission); err != nil {
+		return fmt.Errorf("创建状态目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), config.FilePermission); err != nil {
+		return fmt.Errorf("保存生成文件清单失败: %v", err)
+	}
+	m.changed = false
+	return nil
+}
+
+// hashContent 计算内容的SHA-256
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestGeneratedManifest 测试清单记录、修改检测和保存
+func TestGeneratedManifest(t *testing.T) {
+	root := t.TempDir()
+	m, err := LoadGeneratedManifest(root)
+	if err != nil {
+		t.Fatalf("加载空清单失败: %v", err)
+	}
+
+	path := filepath.Join(root, "config", "site.conf")
+	if !m.Modified(path, []byte("a")) {
+		t.Error("未记录的文件应视为手动修改")
+	}
+
+	m.Record(path, []byte("a"), ChangeCreate, "")
+	if m.Modified(path, []byte("a")) {
+		t.Error("内容与记录一致时不应视为修改")
+	}
+	if !m.Modified(path, []byte("b")) {
+		t.Error("内容与记录不一致时应视为修改")
+	}
+
+	// 跳过时保留原来的哈希
+	m.Record(path, []byte("c"), ChangeSkip, "")
+	if m.Modified(path, []byte("a")) {
+		t.Error("跳过的文件不应更新哈希")
+	}
+
+	if err := m.Save(); err != nil {
+		t.Fatalf("保存清单失败: %v", err)
+	}
+	loaded, err := LoadGeneratedManifest(root)
+	if err != nil {
+		t.Fatalf("重新加载清单失败: %v", err)
+	}
+	file, ok := loaded.Files["config/site.conf"]
+	if !ok || file.Action != ChangeSkip {
+		t.Errorf("清单内容错误: %+v", loaded.Files)
+	}
+}
+
+// TestGeneratedManifestBackupPath 测试备份路径按时间戳分目录，同一秒内的两次生成使用不同的目录
+func TestGeneratedManifestBackupPath(t *testing.T) {
+	root := t.TempDir()
+	m, _ := LoadGeneratedManifest(root)
+	at := time.Date(2024, 5, 1, 12, 30, 0, 250000000, time.UTC)
+
+	site := filepath.Join(root, "config", "site.conf")
+	got := m.BackupPath(site, at)
+	want := filepath.Join(root, ".aigo", "backups", "20240501-123000.250000", "config", "site.conf")
+	if got != want {
+		t.Errorf("备份路径错误: got %s want %s", got, want)
+	}
+	if m.BackupPath(site, at.Add(time.Millisecond)) == got {
+		t.Error("同一秒内的两次生成不应该使用相同的备份路径")
+	}
+
+	outside := filepath.Join(t.TempDir(), "site.conf")
+	if got := m.BackupPath(outside, at); got != outside+".20240501-123000.250000.bak" {
+		t.Errorf("项目外文件应在原位置备份: %s", got)
+	}
+}
+
+// TestLoadGeneratedManifestInvalid 测试清单格式错误
+func TestLoadGeneratedManifestInvalid(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".aigo"), 0755)
+	os.WriteFile(filepath.Join(root, ".aigo", "generated.json"), []byte("{"), 0644)
+
+	if _, err := LoadGeneratedManifest(root); err == nil {
+		t.Error("清单格式错误时应该返回错误")
+	}
+}
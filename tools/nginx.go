@@ -2,7 +2,6 @@ package tools
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -12,14 +11,12 @@ import (
 
 // NginxManager nginx配置管理器
 type NginxManager struct {
-	logger *Logger
+	proxySite
 }
 
 // NewNginxManager 创建新的nginx管理器
 func NewNginxManager() *NginxManager {
-	return &NginxManager{
-		logger: NewLogger(),
-	}
+	return &NginxManager{newProxySite()}
 }
 
 // GenerateConfig 生成nginx配置文件
@@ -28,25 +25,37 @@ func (nm *NginxManager) GenerateConfig(domain, projectPath string, port string)
 		return fmt.Errorf("域名不能为空")
 	}
 
-	if port == "" {
-		port = config.DefaultPort
+	// 生成nginx配置文件
+	configFile := filepath.Join(projectPath, "config", domain)
+	data := nm.siteData(domain, port)
+	content, err := templates.Render(data.NginxTemplate(), data)
+	if err != nil {
+		return fmt.Errorf("渲染nginx配置失败: %v", err)
 	}
 
-	// 创建config目录
-	configDir := filepath.Join(projectPath, "config")
-	if err := os.MkdirAll(configDir, config.DirPermission); err != nil {
-		return fmt.Errorf("创建config目录失败: %v", err)
+	// 先生成htpasswd文件，没有用户时不写入引用它的配置
+	if _, err := nm.generateHtpasswd(data, projectPath, nil); err != nil {
+		return err
 	}
 
-	// 生成nginx配置文件
-	configFile := filepath.Join(configDir, domain)
-	content := fmt.Sprintf(templates.NginxHTTPTemplate, domain, port, domain, domain)
-	
-	if err := os.WriteFile(configFile, []byte(content), config.FilePermission); err != nil {
+	if err := nm.writer.WriteFile(configFile, []byte(content), config.FilePermission); err != nil {
 		return fmt.Errorf("生成nginx配置文件失败: %v", err)
 	}
 
-	nm.logger.Success("nginx配置文件已生成: %s", configFile)
+	nm.fileSuccess(configFile, "nginx配置文件已生成: %s", configFile)
+
+	// brotli配置片段由 setup-nginx.sh 在nginx支持brotli模块时安装
+	if data.Compression != nil && data.Compression.Brotli {
+		brotliFile := filepath.Join(projectPath, templates.BrotliConfigFile)
+		content, err := templates.Render(templates.NginxBrotliTemplate, data)
+		if err != nil {
+			return fmt.Errorf("渲染brotli配置失败: %v", err)
+		}
+		if err := nm.writer.WriteFile(brotliFile, []byte(content), config.FilePermission); err != nil {
+			return fmt.Errorf("生成brotli配置文件失败: %v", err)
+		}
+		nm.fileSuccess(brotliFile, "brotli配置片段已生成: %s", brotliFile)
+	}
 	return nil
 }
 
@@ -54,39 +63,34 @@ func (nm *NginxManager) GenerateConfig(domain, projectPath string, port string)
 func (nm *NginxManager) GenerateSetupScript(projectPath string) error {
 	configDir := filepath.Join(projectPath, "config")
 	setupScript := filepath.Join(configDir, "setup-nginx.sh")
-	
-	if err := os.WriteFile(setupScript, []byte(templates.NginxSetupScriptTemplate), config.FilePermission); err != nil {
-		return fmt.Errorf("生成nginx配置脚本失败: %v", err)
+	content, err := templates.Render(templates.NginxSetupScriptTemplate, templates.NewData(""))
+	if err != nil {
+		return fmt.Errorf("渲染nginx配置脚本失败: %v", err)
 	}
 
-	// 添加执行权限
-	if err := os.Chmod(setupScript, 0755); err != nil {
-		return fmt.Errorf("设置脚本执行权限失败: %v", err)
+	// 脚本需要执行权限
+	if err := nm.writer.WriteFile(setupScript, []byte(content), config.ScriptPermission); err != nil {
+		return fmt.Errorf("生成nginx配置脚本失败: %v", err)
 	}
 
-	nm.logger.Success("nginx配置脚本已生成: %s", setupScript)
+	nm.fileSuccess(setupScript, "nginx配置脚本已生成: %s", setupScript)
 	return nil
 }
 
 // GenerateSSLScript 生成SSL证书申请脚本
 func (nm *NginxManager) GenerateSSLScript(projectPath string) error {
-	scriptsDir := filepath.Join(projectPath, "scripts")
-	if err := os.MkdirAll(scriptsDir, config.DirPermission); err != nil {
-		return fmt.Errorf("创建scripts目录失败: %v", err)
+	sslScript := filepath.Join(projectPath, "scripts", "apply-ssl.sh")
+	content, err := templates.Render(templates.CertbotScriptTemplate, templates.NewData(""))
+	if err != nil {
+		return fmt.Errorf("渲染SSL证书申请脚本失败: %v", err)
 	}
 
-	sslScript := filepath.Join(scriptsDir, "apply-ssl.sh")
-	
-	if err := os.WriteFile(sslScript, []byte(templates.CertbotScriptTemplate), config.FilePermission); err != nil {
+	// 脚本需要执行权限
+	if err := nm.writer.WriteFile(sslScript, []byte(content), config.ScriptPermission); err != nil {
 		return fmt.Errorf("生成SSL证书申请脚本失败: %v", err)
 	}
 
-	// 添加执行权限
-	if err := os.Chmod(sslScript, 0755); err != nil {
-		return fmt.Errorf("设置脚本执行权限失败: %v", err)
-	}
-
-	nm.logger.Success("SSL证书申请脚本已生成: %s", sslScript)
+	nm.fileSuccess(sslScript, "SSL证书申请脚本已生成: %s", sslScript)
 	return nil
 }
 
@@ -104,6 +108,6 @@ func (nm *NginxManager) GenerateAll(domain, projectPath string, port string) err
 		return err
 	}
 
-	nm.logger.Success("所有nginx相关文件已生成完成")
+	nm.success("所有nginx相关文件已生成完成")
 	return nil
 } 
\ No newline at end of file
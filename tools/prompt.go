@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Prompter 终端交互提问工具
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter 创建新的交互提问工具
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+// IsTerminal 判断文件是否为交互式终端（/dev/null等字符设备不算终端）
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// readLine 读取一行输入，输入结束时返回已读内容
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(err == io.EOF && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Printf 向提问输出写入提示信息
+func (p *Prompter) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(p.out, format, args...)
+}
+
+// Ask 提问并返回回答，直接回车时使用默认值
+func (p *Prompter) Ask(message, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", message, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", message)
+	}
+
+	answer, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// Choose 从候选项中选择，可以输入序号或选项名称
+func (p *Prompter) Choose(message string, choices []string, def string) (string, error) {
+	for {
+		fmt.Fprintln(p.out, message)
+		for i, choice := range choices {
+			marker := " "
+			if choice == def {
+				marker = "*"
+			}
+			fmt.Fprintf(p.out, " %s %d) %s\n", marker, i+1, choice)
+		}
+
+		answer, err := p.Ask("请选择", def)
+		if err != nil {
+			return "", err
+		}
+		for i, choice := range choices {
+			if answer == choice || answer == fmt.Sprint(i+1) {
+				return choice, nil
+			}
+		}
+		fmt.Fprintf(p.out, "无效的选项: %s\n", answer)
+	}
+}
+
+// Confirm 提问是/否，直接回车时使用默认值
+func (p *Prompter) Confirm(message string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", message, hint)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes", "是":
+			return true, nil
+		case "n", "no", "否":
+			return false, nil
+		}
+		fmt.Fprintf(p.out, "请输入 y 或 n\n")
+	}
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestPrompterAsk 测试提问与默认值
+func TestPrompterAsk(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrompter(strings.NewReader("\nmy-api\n"), &out)
+
+	answer, err := p.Ask("项目名称", "demo")
+	if err != nil || answer != "demo" {
+		t.Errorf("直接回车应返回默认值, 实际 %q %v", answer, err)
+	}
+	answer, err = p.Ask("项目名称", "demo")
+	if err != nil || answer != "my-api" {
+		t.Errorf("应返回输入值, 实际 %q %v", answer, err)
+	}
+	if !strings.Contains(out.String(), "项目名称 [demo]: ") {
+		t.Errorf("提示应包含默认值: %q", out.String())
+	}
+
+	if _, err := p.Ask("项目名称", ""); err == nil {
+		t.Error("输入结束时应返回错误")
+	}
+}
+
+// TestPrompterChoose 测试选择候选项
+func TestPrompterChoose(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrompter(strings.NewReader("9\n2\nchi\n\n"), &out)
+	choices := []string{"gin", "echo", "chi"}
+
+	answer, err := p.Choose("框架", choices, "gin")
+	if err != nil || answer != "echo" {
+		t.Errorf("应按序号选择echo, 实际 %q %v", answer, err)
+	}
+	if !strings.Contains(out.String(), "无效的选项: 9") {
+		t.Errorf("无效输入应提示: %q", out.String())
+	}
+
+	if answer, _ := p.Choose("框架", choices, "gin"); answer != "chi" {
+		t.Errorf("应按名称选择chi, 实际 %q", answer)
+	}
+	if answer, _ := p.Choose("框架", choices, "gin"); answer != "gin" {
+		t.Errorf("直接回车应选择默认值, 实际 %q", answer)
+	}
+}
+
+// TestPrompterConfirm 测试是/否确认
+func TestPrompterConfirm(t *testing.T) {
+	p := NewPrompter(strings.NewReader("maybe\ny\n\nno"), &bytes.Buffer{})
+
+	if ok, err := p.Confirm("继续", false); err != nil || !ok {
+		t.Errorf("应返回true, 实际 %v %v", ok, err)
+	}
+	if ok, _ := p.Confirm("继续", true); !ok {
+		t.Error("直接回车应返回默认值true")
+	}
+	if ok, _ := p.Confirm("继续", true); ok {
+		t.Error("输入no应返回false")
+	}
+}
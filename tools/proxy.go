@@ -0,0 +1,216 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/nginx"
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// ProxyGenerator 反向代理配置生成器，nginx、Caddy和Traefik使用同一份域名、负载均衡和路由配置
+type ProxyGenerator interface {
+	SetWriter(writer *FileWriter)
+	SetSite(site templates.Data)
+	SetAuthUsers(users []AuthUser, hash string)
+	GenerateAll(domain, projectPath string, port string) error
+}
+
+// ProxyBackend 支持的反向代理服务器
+type ProxyBackend struct {
+	Name        string
+	Description string
+	AuthHash    string // 默认的htpasswd密码哈希算法
+	New         func() ProxyGenerator
+}
+
+// ProxyBackends 支持的反向代理服务器，第一个为默认值
+var ProxyBackends = []ProxyBackend{
+	{
+		Name:        "nginx",
+		Description: "nginx配置、配置脚本和certbot证书申请脚本",
+		AuthHash:    nginx.HashAPR1,
+		New:         func() ProxyGenerator { return NewNginxManager() },
+	},
+	{
+		Name:        "caddy",
+		Description: "Caddyfile站点配置和配置脚本，HTTPS证书由Caddy自动申请",
+		AuthHash:    nginx.HashBcrypt,
+		New:         func() ProxyGenerator { return NewCaddyManager() },
+	},
+	{
+		Name:        "traefik",
+		Description: "Traefik动态配置（file provider）和配置脚本",
+		AuthHash:    nginx.HashAPR1,
+		New:         func() ProxyGenerator { return NewTraefikManager() },
+	},
+}
+
+// ProxyBackendNames 返回全部反向代理服务器的名称
+func ProxyBackendNames() []string {
+	names := make([]string, 0, len(ProxyBackends))
+	for _, b := range ProxyBackends {
+		names = append(names, b.Name)
+	}
+	return names
+}
+
+// LookupProxyBackend 按名称查找反向代理服务器
+func LookupProxyBackend(name string) (ProxyBackend, error) {
+	for _, b := range ProxyBackends {
+		if b.Name == name {
+			return b, nil
+		}
+	}
+	return ProxyBackend{}, fmt.Errorf("未知的反向代理: %s，可选 %s", name, strings.Join(ProxyBackendNames(), ", "))
+}
+
+// AuthUser basic auth用户，密码只写入htpasswd哈希，不记录到项目清单
+type AuthUser struct {
+	Name     string
+	Password string
+}
+
+// proxySite 各反向代理生成器共用的站点配置和文件写入
+type proxySite struct {
+	logger      *Logger
+	writer      *FileWriter
+	tls         *templates.TLS
+	upstream    *templates.Upstream
+	locations   []templates.Location
+	compression *templates.Compression
+	cache       []templates.CacheRule
+	nginxRoot   string
+	authUsers   []AuthUser
+	authHash    string
+}
+
+// newProxySite 创建直接写入文件的站点配置
+func newProxySite() proxySite {
+	return proxySite{
+		logger: NewLogger(),
+		writer: NewFileWriter(WriteFiles, os.Stdout),
+	}
+}
+
+// SetWriter 设置文件写入器，用于dry-run和diff模式
+func (ps *proxySite) SetWriter(writer *FileWriter) {
+	ps.writer = writer
+}
+
+// SetSite 使用site中的证书、负载均衡、路由、压缩、缓存配置和nginx根目录
+func (ps *proxySite) SetSite(site templates.Data) {
+	ps.tls = site.TLS
+	ps.upstream = site.Upstream
+	ps.locations = site.Locations
+	ps.compression = site.Compression
+	ps.cache = site.Cache
+	ps.nginxRoot = site.NginxRoot
+}
+
+// SetAuthUsers 设置写入htpasswd文件的用户和哈希算法，已有同名用户时修改密码
+func (ps *proxySite) SetAuthUsers(users []AuthUser, hash string) {
+	ps.authUsers = users
+	ps.authHash = hash
+}
+
+// success 非预览模式下打印成功信息
+func (ps *proxySite) success(format string, args ...interface{}) {
+	if !ps.writer.Preview() {
+		ps.logger.Success(format, args...)
+	}
+}
+
+// fileSuccess 输出文件生成成功的信息，文件按覆盖策略被跳过时不输出
+func (ps *proxySite) fileSuccess(path, format string, args ...interface{}) {
+	if !ps.writer.Skipped(path) {
+		ps.success(format, args...)
+	}
+}
+
+// siteData 返回域名的模板数据
+func (ps *proxySite) siteData(domain, port string) templates.Data {
+	if port == "" {
+		port = config.DefaultPort
+	}
+	data := templates.NewData("")
+	data.Domain = domain
+	data.Port = port
+	data.TLS = ps.tls
+	data.Upstream = ps.upstream
+	data.Locations = ps.locations
+	data.Compression = ps.compression
+	data.Cache = ps.cache
+	data.NginxRoot = ps.nginxRoot
+	return data
+}
+
+// warnUnsupported 提示反向代理不支持、生成时被忽略的设置
+func (ps *proxySite) warnUnsupported(backend string, ignored []string) {
+	if len(ignored) > 0 {
+		ps.logger.Warning("%s不支持以下设置，已忽略: %s", backend, strings.Join(ignored, "；"))
+	}
+}
+
+// writeScript 渲染并写入配置脚本
+func (ps *proxySite) writeScript(name, path string, data templates.Data) error {
+	content, err := templates.Render(name, data)
+	if err != nil {
+		return fmt.Errorf("渲染配置脚本失败: %v", err)
+	}
+	// 脚本需要执行权限
+	if err := ps.writer.WriteFile(path, []byte(content), config.ScriptPermission); err != nil {
+		return fmt.Errorf("生成配置脚本失败: %v", err)
+	}
+	ps.fileSuccess(path, "配置脚本已生成: %s", path)
+	return nil
+}
+
+// generateHtpasswd 将用户合并到域名的htpasswd文件，保留文件中已有的其他用户，返回文件中的全部用户；
+// 没有路由启用basic auth且未指定用户时不处理。check不为nil时在写入前检查合并后的每个用户，
+// 反向代理不支持其中的哈希格式时不修改文件
+func (ps *proxySite) generateHtpasswd(data templates.Data, projectPath string, check func(templates.Credential) error) ([]templates.Credential, error) {
+	if !data.AuthEnabled() && len(ps.authUsers) == 0 {
+		return nil, nil
+	}
+	htpasswdFile := filepath.Join(projectPath, data.HtpasswdConfigFile())
+	existing, err := os.ReadFile(htpasswdFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取htpasswd文件失败: %v", err)
+	}
+	htpasswd, err := nginx.ParseHtpasswd(existing)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", htpasswdFile, err)
+	}
+
+	if len(ps.authUsers) == 0 && len(htpasswd.Entries) == 0 {
+		return nil, fmt.Errorf("路由启用了basic auth，但 %s 中没有用户，请使用 --auth-user 添加", htpasswdFile)
+	}
+	for _, user := range ps.authUsers {
+		if err := htpasswd.Set(user.Name, user.Password, ps.authHash); err != nil {
+			return nil, err
+		}
+	}
+	credentials := make([]templates.Credential, 0, len(htpasswd.Entries))
+	for _, e := range htpasswd.Entries {
+		c := templates.Credential{User: e.User, Hash: e.Hash}
+		if check != nil {
+			if err := check(c); err != nil {
+				return nil, err
+			}
+		}
+		credentials = append(credentials, c)
+	}
+
+	if len(ps.authUsers) > 0 {
+		// 密码文件由用户维护，合并写入时不做手动修改检测
+		if err := ps.writer.Patch(htpasswdFile, htpasswd.Bytes(), config.SecretPermission); err != nil {
+			return nil, fmt.Errorf("生成htpasswd文件失败: %v", err)
+		}
+		ps.success("htpasswd文件已生成: %s (用户: %s)", htpasswdFile, strings.Join(htpasswd.Users(), ", "))
+	}
+	return credentials, nil
+}
@@ -0,0 +1,16 @@
+package tools
+
+import "testing"
+
+// TestLookupProxyBackend 测试按名称查找反向代理
+func TestLookupProxyBackend(t *testing.T) {
+	for _, name := range ProxyBackendNames() {
+		backend, err := LookupProxyBackend(name)
+		if err != nil || backend.New() == nil {
+			t.Errorf("%s 应该可以创建生成器: %v", name, err)
+		}
+	}
+	if _, err := LookupProxyBackend("apache"); err == nil {
+		t.Error("未知的反向代理应该返回错误")
+	}
+}
@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// TemplateManager 自定义项目模板管理器，已注册的模板保存在 ~/.aigo/templates/<name>
+type TemplateManager struct {
+	logger *Logger
+}
+
+// NewTemplateManager 创建新的模板管理器
+func NewTemplateManager() *TemplateManager {
+	return &TemplateManager{
+		logger: NewLogger(),
+	}
+}
+
+// Resolve 解析模板引用：本地目录、git仓库地址或已注册的模板名称
+// 返回的cleanup用于清理git克隆产生的临时目录
+func (tm *TemplateManager) Resolve(ref string) (*templates.Custom, func(), error) {
+	noop := func() {}
+
+	if isGitURL(ref) {
+		tmp, err := os.MkdirTemp("", "aigo-template-")
+		if err != nil {
+			return nil, noop, fmt.Errorf("创建临时目录失败: %v", err)
+		}
+		cleanup := func() { os.RemoveAll(tmp) }
+
+		dir := filepath.Join(tmp, "template")
+		if err := tm.clone(ref, dir); err != nil {
+			cleanup()
+			return nil, noop, err
+		}
+		custom, err := templates.LoadCustom(dir)
+		if err != nil {
+			cleanup()
+			return nil, noop, err
+		}
+		return custom, cleanup, nil
+	}
+
+	if info, err := os.Stat(ref); err == nil && info.IsDir() {
+		custom, err := templates.LoadCustom(ref)
+		return custom, noop, err
+	}
+
+	if err := validateTemplateName(ref); err != nil {
+		return nil, noop, fmt.Errorf("模板 %s 不存在", ref)
+	}
+	root, err := config.TemplatesDir()
+	if err != nil {
+		return nil, noop, err
+	}
+	dir := filepath.Join(root, ref)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, noop, fmt.Errorf("模板 %s 不存在，可使用 aigo_hotreload template add 注册", ref)
+	}
+	custom, err := templates.LoadCustom(dir)
+	return custom, noop, err
+}
+
+// Add 注册模板：复制本地目录或克隆git仓库到模板目录
+func (tm *TemplateManager) Add(name, src string) error {
+	if err := validateTemplateName(name); err != nil {
+		return err
+	}
+	root, err := config.TemplatesDir()
+	if err != nil {
+		return err
+	}
+
+	dst := filepath.Join(root, name)
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("模板 %s 已存在", name)
+	}
+	if err := os.MkdirAll(root, config.DirPermission); err != nil {
+		return fmt.Errorf("创建模板目录失败: %v", err)
+	}
+
+	if isGitURL(src) {
+		err = tm.clone(src, dst)
+	} else {
+		err = copyDir(src, dst)
+	}
+	if err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+
+	if _, err := templates.LoadCustom(dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+
+	tm.logger.Success("模板 %s 已注册: %s", name, dst)
+	return nil
+}
+
+// Remove 删除已注册的模板
+func (tm *TemplateManager) Remove(name string) error {
+	if err := validateTemplateName(name); err != nil {
+		return err
+	}
+	root, err := config.TemplatesDir()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(root, name)
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("模板 %s 不存在", name)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("删除模板 %s 失败: %v", name, err)
+	}
+
+	tm.logger.Success("模板 %s 已删除", name)
+	return nil
+}
+
+// List 列出所有已注册的模板
+func (tm *TemplateManager) List() ([]*templates.Custom, error) {
+	root, err := config.TemplatesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取模板目录失败: %v", err)
+	}
+
+	var list []*templates.Custom
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		custom, err := templates.LoadCustom(filepath.Join(root, entry.Name()))
+		if err != nil {
+			tm.logger.Warning("跳过无效模板 %s: %v", entry.Name(), err)
+			continue
+		}
+		list = append(list, custom)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Dir < list[j].Dir })
+	return list, nil
+}
+
+// clone 浅克隆git仓库
+func (tm *TemplateManager) clone(url, dst string) error {
+	tm.logger.Info("正在克隆模板仓库 %s ...", url)
+	cmd := exec.Command("git", "clone", "--depth", "1", url, dst)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("克隆模板仓库失败: %v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// isGitURL 判断模板引用是否为git仓库地址
+func isGitURL(ref string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "git@", "file://"} {
+		if strings.HasPrefix(ref, prefix) {
+			return true
+		}
+	}
+	return strings.HasSuffix(ref, ".git")
+}
+
+// validateTemplateName 检查模板名称，防止路径穿越
+func validateTemplateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("无效的模板名称: %q", name)
+	}
+	return nil
+}
+
+// copyDir 递归复制目录，跳过.git
+func copyDir(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("模板目录不可用: %v", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s 不是目录", src)
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, config.DirPermission)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// setupTemplateHome 使用临时目录作为工具数据目录，并创建一个模板源目录
+func setupTemplateHome(t *testing.T) string {
+	t.Helper()
+	t.Setenv(config.HomeEnv, t.TempDir())
+
+	src := t.TempDir()
+	manifest := "name: api\ndescription: 示例模板\n"
+	if err := os.WriteFile(filepath.Join(src, "template.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "main.go.tmpl"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+// TestTemplateManagerAddListRemove 测试注册、列出和删除模板
+func TestTemplateManagerAddListRemove(t *testing.T) {
+	src := setupTemplateHome(t)
+	tm := NewTemplateManager()
+
+	if err := tm.Add("api", src); err != nil {
+		t.Fatalf("注册模板失败: %v", err)
+	}
+	if err := tm.Add("api", src); err == nil {
+		t.Error("重复注册应该返回错误")
+	}
+
+	list, err := tm.List()
+	if err != nil {
+		t.Fatalf("列出模板失败: %v", err)
+	}
+	if len(list) != 1 || list[0].Manifest.Description != "示例模板" {
+		t.Fatalf("模板列表错误: %+v", list)
+	}
+
+	custom, cleanup, err := tm.Resolve("api")
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("按名称解析模板失败: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(custom.Dir, "main.go.tmpl")); err != nil {
+		t.Errorf("模板文件应已复制: %v", err)
+	}
+
+	if err := tm.Remove("api"); err != nil {
+		t.Fatalf("删除模板失败: %v", err)
+	}
+	if err := tm.Remove("api"); err == nil {
+		t.Error("删除不存在的模板应该返回错误")
+	}
+}
+
+// TestTemplateManagerResolve 测试解析本地目录与无效引用
+func TestTemplateManagerResolve(t *testing.T) {
+	src := setupTemplateHome(t)
+	tm := NewTemplateManager()
+
+	custom, cleanup, err := tm.Resolve(src)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("解析本地目录失败: %v", err)
+	}
+	if custom.Manifest.Name != "api" {
+		t.Errorf("模板名称错误: %s", custom.Manifest.Name)
+	}
+
+	if _, _, err := tm.Resolve("missing"); err == nil {
+		t.Error("未注册的模板应该返回错误")
+	}
+	if err := tm.Add("../escape", src); err == nil {
+		t.Error("包含路径分隔符的名称应该返回错误")
+	}
+}
+
+// TestIsGitURL 测试git仓库地址识别
+func TestIsGitURL(t *testing.T) {
+	cases := map[string]bool{
+		"https://github.com/user/tpl": true,
+		"git@github.com:user/tpl.git": true,
+		"ssh://git@example.com/tpl":   true,
+		"file:///tmp/tpl":             true,
+		"../local/tpl.git":            true,
+		"./templates/api":             false,
+		"api":                         false,
+	}
+	for ref, want := range cases {
+		if got := isGitURL(ref); got != want {
+			t.Errorf("isGitURL(%q) = %v, 期望 %v", ref, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yggai/aigo_hotreload/config"
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// TraefikManager Traefik配置管理器
+type TraefikManager struct {
+	proxySite
+}
+
+// NewTraefikManager 创建新的Traefik管理器
+func NewTraefikManager() *TraefikManager {
+	return &TraefikManager{newProxySite()}
+}
+
+// GenerateConfig 生成域名的Traefik动态配置
+func (tm *TraefikManager) GenerateConfig(domain, projectPath string, port string) error {
+	if strings.TrimSpace(domain) == "" {
+		return fmt.Errorf("域名不能为空")
+	}
+
+	data := tm.siteData(domain, port)
+	// 先检查Traefik能否表示这些路由，再写入htpasswd文件
+	if _, err := data.Traefik(); err != nil {
+		return err
+	}
+	credentials, err := tm.generateHtpasswd(data, projectPath, nil)
+	if err != nil {
+		return err
+	}
+	data.Credentials = credentials
+	tm.warnUnsupported("Traefik", data.TraefikUnsupported())
+
+	configFile := filepath.Join(projectPath, "config", domain+".traefik.yml")
+	content, err := templates.Render(templates.TraefikTemplate, data)
+	if err != nil {
+		return fmt.Errorf("渲染Traefik配置失败: %v", err)
+	}
+	if err := tm.writer.WriteFile(configFile, []byte(content), config.FilePermission); err != nil {
+		return fmt.Errorf("生成Traefik配置文件失败: %v", err)
+	}
+	tm.fileSuccess(configFile, "Traefik配置文件已生成: %s", configFile)
+	return nil
+}
+
+// GenerateAll 生成所有Traefik相关文件
+func (tm *TraefikManager) GenerateAll(domain, projectPath string, port string) error {
+	if err := tm.GenerateConfig(domain, projectPath, port); err != nil {
+		return err
+	}
+	setupScript := filepath.Join(projectPath, "config", "setup-traefik.sh")
+	if err := tm.writeScript(templates.TraefikSetupScriptTemplate, setupScript, templates.NewData("")); err != nil {
+		return err
+	}
+	tm.success("所有Traefik相关文件已生成完成")
+	return nil
+}
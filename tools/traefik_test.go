@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yggai/aigo_hotreload/templates"
+)
+
+// TestTraefikGenerateAll 测试生成Traefik动态配置和配置脚本
+func TestTraefikGenerateAll(t *testing.T) {
+	manager := NewTraefikManager()
+	tempDir := t.TempDir()
+	manager.SetSite(templates.Data{TLS: &templates.TLS{}})
+	if err := manager.GenerateAll("test.example.com", tempDir, "9000"); err != nil {
+		t.Fatalf("生成Traefik配置失败: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "config", "test.example.com.traefik.yml"))
+	if err != nil {
+		t.Fatalf("读取Traefik配置失败: %v", err)
+	}
+	for _, want := range []string{"rule: \"Host(`test.example.com`)\"", "certResolver: letsencrypt", "url: \"http://localhost:9000\""} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Traefik配置应该包含 %q:\n%s", want, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "config", "setup-traefik.sh")); err != nil {
+		t.Errorf("配置脚本应该被生成: %v", err)
+	}
+
+	// 静态文件路由返回错误，且不写入任何文件
+	staticDir := t.TempDir()
+	manager.SetSite(templates.Data{Locations: []templates.Location{{Path: "/", Root: "/srv/www"}}})
+	if err := manager.GenerateAll("test.example.com", staticDir, "9000"); err == nil {
+		t.Error("静态文件路由应该返回错误")
+	}
+	if _, err := os.Stat(filepath.Join(staticDir, "config")); !os.IsNotExist(err) {
+		t.Errorf("出错时不应该写入文件: %v", err)
+	}
+}
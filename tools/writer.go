@@ -0,0 +1,417 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yggai/aigo_hotreload/config"
+)
+
+// WriteMode 文件写入模式
+type WriteMode int
+
+const (
+	// WriteFiles 直接写入磁盘
+	WriteFiles WriteMode = iota
+	// WriteDryRun 只列出将要写入的文件、权限和大小
+	WriteDryRun
+	// WriteDiff 只显示与磁盘上已有文件的统一diff
+	WriteDiff
+)
+
+// SelectWriteMode 根据 --dry-run 和 --diff 参数选择写入模式，同时指定时diff优先
+func SelectWriteMode(dryRun, diff bool) WriteMode {
+	switch {
+	case diff:
+		return WriteDiff
+	case dryRun:
+		return WriteDryRun
+	}
+	return WriteFiles
+}
+
+// OverwritePolicy 目标文件已存在且内容不同时的处理策略
+type OverwritePolicy string
+
+const (
+	PolicySkip      OverwritePolicy = "skip"      // 保留已有文件
+	PolicyOverwrite OverwritePolicy = "overwrite" // 直接覆盖
+	PolicyBackup    OverwritePolicy = "backup"    // 备份后覆盖
+	PolicyPrompt    OverwritePolicy = "prompt"    // 逐个询问
+)
+
+// OverwriteEnv 设置默认覆盖策略的环境变量
+const OverwriteEnv = "AIGO_OVERWRITE"
+
+// OverwritePolicies 所有覆盖策略名称
+var OverwritePolicies = []string{string(PolicySkip), string(PolicyOverwrite), string(PolicyBackup), string(PolicyPrompt)}
+
+// ParseOverwritePolicy 解析覆盖策略名称
+func ParseOverwritePolicy(name string) (OverwritePolicy, error) {
+	for _, policy := range OverwritePolicies {
+		if name == policy {
+			return OverwritePolicy(name), nil
+		}
+	}
+	return "", fmt.Errorf("无效的覆盖策略: %s（可选: %s）", name, strings.Join(OverwritePolicies, ", "))
+}
+
+// DefaultOverwritePolicy 返回默认覆盖策略，可通过 AIGO_OVERWRITE 环境变量全局修改
+func DefaultOverwritePolicy() string {
+	if policy := os.Getenv(OverwriteEnv); policy != "" {
+		return policy
+	}
+	return string(PolicyBackup)
+}
+
+// 文件变更类型
+const (
+	ChangeCreate    = "create"
+	ChangeOverwrite = "overwrite"
+	ChangeBackup    = "backup"
+	ChangeSkip      = "skip"
+	ChangeUnchanged = "unchanged"
+	ChangeConflict  = "conflict" // 预览模式下被手动修改、需要 --force 的文件
+	ChangePrompt    = "prompt"   // 预览模式下需要询问的文件
+)
+
+// changeOrder 汇总信息中变更类型的顺序和名称
+var changeOrder = []struct {
+	action string
+	label  string
+}{
+	{ChangeCreate, "新建"},
+	{ChangeOverwrite, "覆盖"},
+	{ChangeBackup, "备份后覆盖"},
+	{ChangeSkip, "跳过"},
+	{ChangeUnchanged, "未变化"},
+	{ChangeConflict, "冲突"},
+	{ChangePrompt, "待确认"},
+}
+
+// ConflictError 目标文件已被手动修改，未指定 --force 时拒绝覆盖
+type ConflictError struct {
+	Path string
+}
+
+// Error 实现error接口
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("文件 %s 已被手动修改，使用 --force 覆盖或 --overwrite skip 跳过", displayPath(e.Path))
+}
+
+// FileChange 一次文件写入的结果
+type FileChange struct {
+	Path   string
+	Mode   os.FileMode
+	Size   int
+	Action string
+	Backup string
+}
+
+// FileWriter 统一处理生成文件的写入，支持dry-run、diff模式和覆盖策略
+type FileWriter struct {
+	mode     WriteMode
+	out      io.Writer
+	policy   OverwritePolicy
+	force    bool
+	manifest *GeneratedManifest
+	prompter *Prompter
+	now      time.Time
+	changes  []FileChange
+}
+
+// NewFileWriter 创建文件写入器，out用于输出dry-run列表和diff，默认直接覆盖已有文件
+func NewFileWriter(mode WriteMode, out io.Writer) *FileWriter {
+	return &FileWriter{
+		mode:   mode,
+		out:    out,
+		policy: PolicyOverwrite,
+		now:    time.Now(),
+	}
+}
+
+// SetPolicy 设置覆盖策略，force为true时允许覆盖被手动修改的文件
+func (w *FileWriter) SetPolicy(policy OverwritePolicy, force bool) {
+	w.policy = policy
+	w.force = force
+}
+
+// SetManifest 设置生成文件清单，用于识别被手动修改的文件并记录生成结果
+func (w *FileWriter) SetManifest(manifest *GeneratedManifest) {
+	w.manifest = manifest
+}
+
+// SetPrompter 设置prompt策略使用的交互提问工具
+func (w *FileWriter) SetPrompter(prompter *Prompter) {
+	w.prompter = prompter
+}
+
+// Mode 返回写入模式
+func (w *FileWriter) Mode() WriteMode {
+	return w.mode
+}
+
+// Preview 是否只预览而不写入磁盘
+func (w *FileWriter) Preview() bool {
+	return w.mode != WriteFiles
+}
+
+// Changes 返回已处理的文件变更
+func (w *FileWriter) Changes() []FileChange {
+	return w.changes
+}
+
+// WriteFile 按写入模式和覆盖策略处理文件：写入磁盘、列出文件信息或显示diff
+func (w *FileWriter) WriteFile(path string, content []byte, perm os.FileMode) error {
+	change := FileChange{Path: path, Mode: perm, Size: len(content), Action: ChangeCreate}
+
+	old, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		change.Action, err = w.resolve(path, old, content, perm)
+		if err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("读取文件 %s 失败: %v", path, err)
+	}
+
+	switch w.mode {
+	case WriteDryRun:
+		w.changes = append(w.changes, change)
+		fmt.Fprintf(w.out, "  %-10s %s %8d  %s\n", change.Action, perm, change.Size, displayPath(path))
+		return nil
+	case WriteDiff:
+		w.changes = append(w.changes, change)
+		if change.Action != ChangeSkip {
+			w.printDiff(change, old, content)
+		}
+		return nil
+	}
+
+	switch change.Action {
+	case ChangeSkip:
+		fmt.Fprintf(w.out, "⚠️  跳过已存在的文件: %s\n", displayPath(path))
+	case ChangeUnchanged:
+	case ChangeBackup:
+		change.Backup = w.backupPath(path)
+		if err := copyFile(path, change.Backup); err != nil {
+			return fmt.Errorf("备份文件 %s 失败: %v", path, err)
+		}
+		fmt.Fprintf(w.out, "已备份 %s -> %s\n", displayPath(path), displayPath(change.Backup))
+		fallthrough
+	default:
+		if err := writeFile(path, content, perm); err != nil {
+			return err
+		}
+	}
+
+	w.changes = append(w.changes, change)
+	if w.manifest != nil {
+		// 立即保存清单，后面的文件冲突或生成失败时已写入的文件仍然有记录
+		w.manifest.Record(path, content, change.Action, change.Backup)
+		return w.manifest.Save()
+	}
+	return nil
+}
+
+// Skipped 判断文件在本次生成中是否按覆盖策略被跳过
+func (w *FileWriter) Skipped(path string) bool {
+	for i := len(w.changes) - 1; i >= 0; i-- {
+		if w.changes[i].Path == path {
+			return w.changes[i].Action == ChangeSkip
+		}
+	}
+	return false
+}
+
+// Patch 修改用户维护的文件（如向go.mod添加依赖），不受覆盖策略和手动修改检测限制，写入前仍会备份
+func (w *FileWriter) Patch(path string, content []byte, perm os.FileMode) error {
+	policy, force := w.policy, w.force
+	w.policy, w.force = PolicyBackup, true
+	defer func() { w.policy, w.force = policy, force }()
+	return w.WriteFile(path, content, perm)
+}
+
+// resolve 根据覆盖策略决定如何处理已存在的文件
+func (w *FileWriter) resolve(path string, old, content []byte, perm os.FileMode) (string, error) {
+	if bytes.Equal(old, content) {
+		if fileMode(path) == perm {
+			return ChangeUnchanged, nil
+		}
+		return ChangeOverwrite, nil
+	}
+
+	modified := w.manifest != nil && w.manifest.Modified(path, old)
+	switch w.policy {
+	case PolicySkip:
+		return ChangeSkip, nil
+	case PolicyPrompt:
+		if w.Preview() {
+			return ChangePrompt, nil
+		}
+		return w.ask(path, old, content, modified)
+	}
+
+	if modified && !w.force {
+		if w.Preview() {
+			return ChangeConflict, nil
+		}
+		return "", &ConflictError{Path: path}
+	}
+	if w.policy == PolicyBackup {
+		return ChangeBackup, nil
+	}
+	return ChangeOverwrite, nil
+}
+
+// ask prompt策略下询问如何处理已存在的文件，明确的回答视为用户同意覆盖
+func (w *FileWriter) ask(path string, old, content []byte, modified bool) (string, error) {
+	if w.prompter == nil {
+		return "", fmt.Errorf("文件 %s 已存在，prompt 策略需要在交互式终端中运行", displayPath(path))
+	}
+
+	message := fmt.Sprintf("文件 %s 已存在", displayPath(path))
+	if modified {
+		message = fmt.Sprintf("文件 %s 已被手动修改", displayPath(path))
+	}
+	choices := []string{ChangeBackup, ChangeOverwrite, ChangeSkip, "diff"}
+	for {
+		answer, err := w.prompter.Choose(message+"，如何处理?", choices, ChangeBackup)
+		if err != nil {
+			return "", err
+		}
+		if answer != "diff" {
+			return answer, nil
+		}
+		name := filepath.ToSlash(displayPath(path))
+		fmt.Fprint(w.out, UnifiedDiff("a/"+name, "b/"+name, string(old), string(content)))
+	}
+}
+
+// backupPath 返回文件本次生成的备份路径
+func (w *FileWriter) backupPath(path string) string {
+	if w.manifest != nil {
+		return w.manifest.BackupPath(path, w.now)
+	}
+	return path + "." + w.now.Format(config.BackupTimeFormat) + ".bak"
+}
+
+// Finish 完成本次生成：预览模式输出汇总信息，写入模式保存生成文件清单
+func (w *FileWriter) Finish() error {
+	if w.Preview() {
+		w.Summary()
+		return nil
+	}
+	if w.manifest != nil {
+		return w.manifest.Save()
+	}
+	return nil
+}
+
+// Summary 输出预览模式的汇总信息
+func (w *FileWriter) Summary() {
+	if !w.Preview() {
+		return
+	}
+
+	counts := map[string]int{}
+	for _, change := range w.changes {
+		counts[change.Action]++
+	}
+
+	var parts []string
+	for _, item := range changeOrder {
+		if counts[item.action] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", item.label, counts[item.action]))
+		}
+	}
+	fmt.Fprintf(w.out, "共 %d 个文件: %s（未写入磁盘）\n", len(w.changes), strings.Join(parts, ", "))
+	if counts[ChangeConflict] > 0 {
+		fmt.Fprintln(w.out, "存在被手动修改的文件，实际执行时需要 --force 或 --overwrite skip")
+	}
+}
+
+// printDiff 输出单个文件的统一diff
+func (w *FileWriter) printDiff(change FileChange, old, content []byte) {
+	if change.Action == ChangeUnchanged {
+		return
+	}
+
+	name := strings.TrimPrefix(filepath.ToSlash(displayPath(change.Path)), "/")
+	fmt.Fprintf(w.out, "diff a/%s b/%s\n", name, name)
+
+	oldName := "a/" + name
+	if change.Action == ChangeCreate {
+		oldName = "/dev/null"
+		fmt.Fprintf(w.out, "new file mode %s\n", change.Mode)
+	} else if mode := fileMode(change.Path); mode != change.Mode {
+		fmt.Fprintf(w.out, "old mode %s\nnew mode %s\n", mode, change.Mode)
+	}
+
+	fmt.Fprint(w.out, UnifiedDiff(oldName, "b/"+name, string(old), string(content)))
+}
+
+// writeFile 创建目录并写入文件
+func writeFile(path string, content []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), config.DirPermission); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, content, perm); err != nil {
+		return err
+	}
+	// 文件已存在时WriteFile不会修改权限
+	return os.Chmod(path, perm)
+}
+
+// copyFile 复制文件并保留权限
+func copyFile(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return writeFile(dst, content, fileMode(src))
+}
+
+// displayPath 尽量以相对当前目录的路径显示文件
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// fileMode 返回已有文件的权限
+func fileMode(path string) os.FileMode {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Mode().Perm()
+}
+
+// NewProjectWriter 为项目目录创建文件写入器：加载生成文件清单并应用覆盖策略
+func NewProjectWriter(root string, mode WriteMode, policy OverwritePolicy, force bool) (*FileWriter, error) {
+	manifest, err := LoadGeneratedManifest(root)
+	if err != nil {
+		return nil, err
+	}
+
+	w := NewFileWriter(mode, os.Stdout)
+	w.SetPolicy(policy, force)
+	w.SetManifest(manifest)
+	if policy == PolicyPrompt && IsTerminal(os.Stdin) {
+		w.SetPrompter(NewPrompter(os.Stdin, os.Stdout))
+	}
+	return w, nil
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFileWriterWrite 测试直接写入模式创建目录并设置权限
+func TestFileWriterWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scripts", "run.sh")
+	w := NewFileWriter(WriteFiles, &bytes.Buffer{})
+
+	if err := w.WriteFile(path, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if err := w.WriteFile(path, []byte("#!/bin/sh\necho hi\n"), 0755); err != nil {
+		t.Fatalf("覆盖失败: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("覆盖时应更新权限: %v %v", info.Mode(), err)
+	}
+
+	changes := w.Changes()
+	if len(changes) != 2 || changes[0].Action != ChangeCreate || changes[1].Action != ChangeOverwrite {
+		t.Errorf("变更记录错误: %+v", changes)
+	}
+}
+
+// TestFileWriterDryRun 测试dry-run模式只列出文件
+func TestFileWriterDryRun(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "same.txt")
+	os.WriteFile(existing, []byte("same"), 0644)
+
+	var out bytes.Buffer
+	w := NewFileWriter(WriteDryRun, &out)
+	w.WriteFile(filepath.Join(dir, "new", "file.txt"), []byte("hello"), 0644)
+	w.WriteFile(existing, []byte("same"), 0644)
+	w.Summary()
+
+	if _, err := os.Stat(filepath.Join(dir, "new")); !os.IsNotExist(err) {
+		t.Error("dry-run 不应该创建目录或文件")
+	}
+	for _, want := range []string{"create", "-rw-r--r--", "5", "file.txt", "unchanged", "新建 1", "未变化 1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry-run 输出应包含 %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// TestFileWriterDiff 测试diff模式输出统一diff且不写入
+func TestFileWriterDiff(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "site.conf")
+	os.WriteFile(path, []byte("listen 80;\nproxy_pass http://localhost:8888;\n"), 0644)
+
+	var out bytes.Buffer
+	w := NewFileWriter(WriteDiff, &out)
+	if err := w.WriteFile(path, []byte("listen 80;\nproxy_pass http://localhost:9000;\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"old mode -rw-r--r--", "new mode -rwxr-xr-x", "-proxy_pass http://localhost:8888;", "+proxy_pass http://localhost:9000;"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("diff 输出应包含 %q:\n%s", want, out.String())
+		}
+	}
+
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "8888") {
+		t.Error("diff 模式不应该修改文件")
+	}
+}
+
+// TestSelectWriteMode 测试根据参数选择写入模式
+func TestSelectWriteMode(t *testing.T) {
+	if SelectWriteMode(false, false) != WriteFiles || SelectWriteMode(true, false) != WriteDryRun || SelectWriteMode(true, true) != WriteDiff {
+		t.Error("写入模式选择错误")
+	}
+}
+
+// newPolicyWriter 创建带生成文件清单的写入器
+func newPolicyWriter(t *testing.T, root string, policy OverwritePolicy, force bool) (*FileWriter, *bytes.Buffer) {
+	t.Helper()
+	manifest, err := LoadGeneratedManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	w := NewFileWriter(WriteFiles, &out)
+	w.SetPolicy(policy, force)
+	w.SetManifest(manifest)
+	return w, &out
+}
+
+// TestFileWriterBackupPolicy 测试备份策略和手动修改检测
+func TestFileWriterBackupPolicy(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "site.conf")
+
+	w, _ := newPolicyWriter(t, root, PolicyBackup, false)
+	w.WriteFile(path, []byte("v1\n"), 0644)
+	if err := w.WriteFile(path, []byte("v2\n"), 0644); err != nil {
+		t.Fatalf("覆盖工具生成的文件应该成功: %v", err)
+	}
+	change := w.Changes()[1]
+	if change.Action != ChangeBackup {
+		t.Fatalf("应该备份后覆盖: %+v", change)
+	}
+	if backup, _ := os.ReadFile(change.Backup); string(backup) != "v1\n" {
+		t.Errorf("备份内容错误: %q", backup)
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 手动修改后拒绝覆盖
+	os.WriteFile(path, []byte("edited\n"), 0644)
+	w, _ = newPolicyWriter(t, root, PolicyBackup, false)
+	err := w.WriteFile(path, []byte("v3\n"), 0644)
+	if _, ok := err.(*ConflictError); !ok {
+		t.Fatalf("手动修改的文件应该返回ConflictError, 实际: %v", err)
+	}
+
+	// --force 时允许覆盖
+	w, _ = newPolicyWriter(t, root, PolicyBackup, true)
+	if err := w.WriteFile(path, []byte("v3\n"), 0644); err != nil {
+		t.Fatalf("force 时应该覆盖: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "v3\n" {
+		t.Errorf("文件内容错误: %q", content)
+	}
+}
+
+// TestFileWriterConflictKeepsManifest 测试中途遇到冲突时已写入的文件仍然记录在清单中
+func TestFileWriterConflictKeepsManifest(t *testing.T) {
+	root := t.TempDir()
+	first, second := filepath.Join(root, "config", "a.com"), filepath.Join(root, "scripts", "apply-ssl.sh")
+	os.MkdirAll(filepath.Dir(second), 0755)
+	os.WriteFile(second, []byte("edited\n"), 0755)
+
+	w, _ := newPolicyWriter(t, root, PolicyBackup, false)
+	if err := w.WriteFile(first, []byte("v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := w.WriteFile(second, []byte("generated\n"), 0755).(*ConflictError); !ok {
+		t.Fatal("手动修改的文件应该返回ConflictError")
+	}
+
+	manifest, err := LoadGeneratedManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Modified(first, []byte("v1\n")) {
+		t.Error("冲突之前写入的文件应该已经记录在清单中")
+	}
+	if w.Skipped(first) {
+		t.Error("没有被跳过的文件不应该报告为跳过")
+	}
+}
+
+// TestFileWriterSkipPolicy 测试跳过策略保留已有文件
+func TestFileWriterSkipPolicy(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	os.WriteFile(path, []byte("mine"), 0644)
+
+	w, _ := newPolicyWriter(t, root, PolicySkip, false)
+	if err := w.WriteFile(path, []byte("generated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "mine" {
+		t.Error("skip 策略不应该修改已有文件")
+	}
+	if w.Changes()[0].Action != ChangeSkip || !w.Skipped(path) {
+		t.Errorf("变更记录错误: %+v", w.Changes())
+	}
+}
+
+// TestFileWriterPromptPolicy 测试prompt策略逐个询问
+func TestFileWriterPromptPolicy(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	os.WriteFile(path, []byte("old\n"), 0644)
+
+	w, out := newPolicyWriter(t, root, PolicyPrompt, false)
+	if err := w.WriteFile(path, []byte("new\n"), 0644); err == nil {
+		t.Error("没有交互终端时 prompt 策略应该返回错误")
+	}
+
+	w.SetPrompter(NewPrompter(strings.NewReader("diff\noverwrite\n"), out))
+	if err := w.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "+new") {
+		t.Errorf("选择diff时应该显示差异:\n%s", out.String())
+	}
+	if content, _ := os.ReadFile(path); string(content) != "new\n" {
+		t.Errorf("选择overwrite后应该覆盖: %q", content)
+	}
+}
+
+// TestParseOverwritePolicy 测试覆盖策略解析和环境变量默认值
+func TestParseOverwritePolicy(t *testing.T) {
+	if policy, err := ParseOverwritePolicy("skip"); err != nil || policy != PolicySkip {
+		t.Errorf("解析skip失败: %v %v", policy, err)
+	}
+	if _, err := ParseOverwritePolicy("never"); err == nil {
+		t.Error("无效策略应该返回错误")
+	}
+
+	t.Setenv(OverwriteEnv, "")
+	if DefaultOverwritePolicy() != "backup" {
+		t.Error("默认策略应该是backup")
+	}
+	t.Setenv(OverwriteEnv, "prompt")
+	if DefaultOverwritePolicy() != "prompt" {
+		t.Error("应该使用环境变量指定的策略")
+	}
+}
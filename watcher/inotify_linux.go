@@ -0,0 +1,178 @@
+//go:build linux
+
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_DELETE_SELF
+
+// inotify 基于Linux inotify的监听实现
+type inotify struct {
+	w    *Watcher
+	file *os.File
+	fd   int
+
+	mu    sync.Mutex
+	paths map[int]string
+	wds   map[string]int
+}
+
+// newInotify 创建inotify监听并递归添加目录
+func newInotify(w *Watcher) (backend, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("初始化inotify失败: %v", err)
+	}
+
+	in := &inotify{
+		w:     w,
+		fd:    fd,
+		file:  os.NewFile(uintptr(fd), "inotify"),
+		paths: make(map[int]string),
+		wds:   make(map[string]int),
+	}
+
+	if err := w.walkDirs(w.root, in.add); err != nil {
+		in.file.Close()
+		return nil, err
+	}
+
+	go in.loop()
+	return in, nil
+}
+
+// add 为目录添加inotify监听
+func (in *inotify) add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(in.fd, dir, inotifyMask)
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) {
+			return nil
+		}
+		return fmt.Errorf("监听目录 %s 失败: %v", dir, err)
+	}
+
+	in.mu.Lock()
+	in.paths[wd] = dir
+	in.wds[dir] = wd
+	in.mu.Unlock()
+	return nil
+}
+
+// forget 移除已失效的监听描述符
+func (in *inotify) forget(wd int) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if dir, ok := in.paths[wd]; ok {
+		delete(in.wds, dir)
+		delete(in.paths, wd)
+	}
+}
+
+// loop 读取inotify事件
+func (in *inotify) loop() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := in.file.Read(buf)
+		if err != nil {
+			select {
+			case <-in.w.done:
+			default:
+				in.w.fail(fmt.Errorf("读取inotify事件失败: %v", err))
+			}
+			return
+		}
+		in.parse(buf[:n])
+	}
+}
+
+// parse 解析一批inotify原始事件
+func (in *inotify) parse(buf []byte) {
+	offset := 0
+	for offset+syscall.SizeofInotifyEvent <= len(buf) {
+		raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameStart := offset + syscall.SizeofInotifyEvent
+		nameEnd := nameStart + int(raw.Len)
+		if nameEnd > len(buf) {
+			return
+		}
+		name := strings.TrimRight(string(buf[nameStart:nameEnd]), "\x00")
+		offset = nameEnd
+
+		mask := raw.Mask
+		if mask&syscall.IN_Q_OVERFLOW != 0 {
+			in.w.fail(fmt.Errorf("inotify事件队列溢出"))
+			continue
+		}
+		if mask&syscall.IN_IGNORED != 0 {
+			in.forget(int(raw.Wd))
+			continue
+		}
+
+		in.mu.Lock()
+		dir, ok := in.paths[int(raw.Wd)]
+		in.mu.Unlock()
+		if !ok || name == "" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+
+		if mask&syscall.IN_ISDIR != 0 {
+			in.handleDir(path, mask)
+			continue
+		}
+
+		switch {
+		case mask&syscall.IN_CREATE != 0:
+			in.w.emit(path, Create)
+		case mask&(syscall.IN_CLOSE_WRITE|syscall.IN_MODIFY) != 0:
+			in.w.emit(path, Write)
+		case mask&syscall.IN_DELETE != 0:
+			in.w.emit(path, Remove)
+		case mask&(syscall.IN_MOVED_FROM|syscall.IN_MOVED_TO) != 0:
+			in.w.emit(path, Rename)
+		}
+	}
+}
+
+// handleDir 处理目录的创建与移入，新目录会被递归监听
+func (in *inotify) handleDir(path string, mask uint32) {
+	if mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) == 0 {
+		return
+	}
+	if !in.w.rules.ShouldWatchDir(in.w.rel(path)) {
+		return
+	}
+
+	err := in.w.walkDirs(path, func(dir string) error {
+		if err := in.add(dir); err != nil {
+			return err
+		}
+		// 添加监听前已写入新目录的文件同样需要触发
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				in.w.emit(filepath.Join(dir, entry.Name()), Create)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		in.w.fail(err)
+	}
+}
+
+// close 关闭inotify文件描述符
+func (in *inotify) close() error {
+	return in.file.Close()
+}
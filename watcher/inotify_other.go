@@ -0,0 +1,10 @@
+//go:build !linux
+
+package watcher
+
+import "fmt"
+
+// newInotify 非Linux平台不支持inotify，由调用方回退到轮询
+func newInotify(w *Watcher) (backend, error) {
+	return nil, fmt.Errorf("当前平台不支持inotify")
+}
@@ -0,0 +1,93 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileState 轮询时记录的文件状态
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// poller 基于定时扫描的监听实现
+type poller struct {
+	w        *Watcher
+	interval time.Duration
+	files    map[string]fileState
+}
+
+// newPoller 创建轮询监听并记录初始快照
+func newPoller(w *Watcher, interval time.Duration) backend {
+	p := &poller{
+		w:        w,
+		interval: interval,
+	}
+	p.files = p.scan()
+	go p.loop()
+	return p
+}
+
+// scan 扫描所有需要监听的文件
+func (p *poller) scan() map[string]fileState {
+	files := make(map[string]fileState)
+	p.w.walkDirs(p.w.root, func(dir string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			files[filepath.Join(dir, entry.Name())] = fileState{
+				modTime: info.ModTime(),
+				size:    info.Size(),
+			}
+		}
+		return nil
+	})
+	return files
+}
+
+// loop 定时比较快照并发送变更事件
+func (p *poller) loop() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.w.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := p.scan()
+		for path, state := range current {
+			old, ok := p.files[path]
+			switch {
+			case !ok:
+				p.w.emit(path, Create)
+			case !old.modTime.Equal(state.modTime) || old.size != state.size:
+				p.w.emit(path, Write)
+			}
+		}
+		for path := range p.files {
+			if _, ok := current[path]; !ok {
+				p.w.emit(path, Remove)
+			}
+		}
+		p.files = current
+	}
+}
+
+// close 轮询在done关闭后自动退出
+func (p *poller) close() error {
+	return nil
+}
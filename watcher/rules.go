@@ -0,0 +1,104 @@
+package watcher
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Rules 文件监听的包含/排除规则
+type Rules struct {
+	IncludeExt   []string
+	IncludeFile  []string
+	ExcludeDir   []string
+	ExcludeFile  []string
+	ExcludeRegex []string
+
+	compiled []*regexp.Regexp
+}
+
+// DefaultRules 返回默认监听规则，与生成项目的.air.toml保持一致
+func DefaultRules() *Rules {
+	return &Rules{
+		IncludeExt:   []string{"go", "tpl", "tmpl", "html"},
+		ExcludeDir:   []string{"assets", "tmp", "vendor", "testdata", "node_modules"},
+		ExcludeRegex: []string{"_test\\.go$"},
+	}
+}
+
+// Compile 编译排除正则表达式
+func (r *Rules) Compile() error {
+	r.compiled = r.compiled[:0]
+	for _, expr := range r.ExcludeRegex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("无效的排除正则 %s: %v", expr, err)
+		}
+		r.compiled = append(r.compiled, re)
+	}
+	return nil
+}
+
+// ShouldWatchDir 判断目录是否需要监听，rel为相对监听根目录的路径
+func (r *Rules) ShouldWatchDir(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == "" {
+		return true
+	}
+
+	for _, name := range strings.Split(rel, "/") {
+		// 隐藏目录（.git、.idea等）不监听
+		if strings.HasPrefix(name, ".") && name != "." && name != ".." {
+			return false
+		}
+		for _, dir := range r.ExcludeDir {
+			if name == strings.Trim(filepath.ToSlash(dir), "/") {
+				return false
+			}
+		}
+	}
+
+	for _, dir := range r.ExcludeDir {
+		dir = strings.Trim(filepath.ToSlash(dir), "/")
+		if rel == dir || strings.HasPrefix(rel, dir+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// ShouldTrigger 判断文件变更是否需要触发重新构建，rel为相对监听根目录的路径
+func (r *Rules) ShouldTrigger(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	dir := filepath.ToSlash(filepath.Dir(rel))
+	if !r.ShouldWatchDir(dir) {
+		return false
+	}
+
+	for _, file := range r.ExcludeFile {
+		if rel == filepath.ToSlash(file) {
+			return false
+		}
+	}
+
+	for _, re := range r.compiled {
+		if re.MatchString(rel) {
+			return false
+		}
+	}
+
+	for _, file := range r.IncludeFile {
+		if rel == filepath.ToSlash(file) {
+			return true
+		}
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(rel), ".")
+	for _, include := range r.IncludeExt {
+		if ext == strings.TrimPrefix(include, ".") {
+			return true
+		}
+	}
+	return false
+}
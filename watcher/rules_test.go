@@ -0,0 +1,89 @@
+package watcher
+
+import "testing"
+
+// TestDefaultRules 测试默认规则
+func TestDefaultRules(t *testing.T) {
+	rules := DefaultRules()
+	if err := rules.Compile(); err != nil {
+		t.Fatalf("编译默认规则失败: %v", err)
+	}
+
+	cases := []struct {
+		path   string
+		expect bool
+	}{
+		{"main.go", true},
+		{"handlers/user.go", true},
+		{"views/index.html", true},
+		{"views/layout.tmpl", true},
+		{"main_test.go", false},
+		{"README.md", false},
+		{"tmp/main", false},
+		{"vendor/github.com/x/y.go", false},
+		{".git/HEAD", false},
+		{"internal/testdata/a.go", false},
+	}
+
+	for _, c := range cases {
+		if got := rules.ShouldTrigger(c.path); got != c.expect {
+			t.Errorf("ShouldTrigger(%s) 期望 %v, 实际得到 %v", c.path, c.expect, got)
+		}
+	}
+}
+
+// TestShouldWatchDir 测试目录过滤
+func TestShouldWatchDir(t *testing.T) {
+	rules := DefaultRules()
+	rules.ExcludeDir = append(rules.ExcludeDir, "web/dist")
+
+	cases := []struct {
+		dir    string
+		expect bool
+	}{
+		{".", true},
+		{"handlers", true},
+		{"tmp", false},
+		{".idea", false},
+		{"web", true},
+		{"web/dist", false},
+		{"web/dist/js", false},
+		{"pkg/vendor", false},
+	}
+
+	for _, c := range cases {
+		if got := rules.ShouldWatchDir(c.dir); got != c.expect {
+			t.Errorf("ShouldWatchDir(%s) 期望 %v, 实际得到 %v", c.dir, c.expect, got)
+		}
+	}
+}
+
+// TestIncludeAndExcludeFile 测试单独指定的文件
+func TestIncludeAndExcludeFile(t *testing.T) {
+	rules := &Rules{
+		IncludeExt:  []string{".go"},
+		IncludeFile: []string{"config.yaml"},
+		ExcludeFile: []string{"gen.go"},
+	}
+	if err := rules.Compile(); err != nil {
+		t.Fatalf("编译规则失败: %v", err)
+	}
+
+	if !rules.ShouldTrigger("config.yaml") {
+		t.Error("include_file中的文件应该触发构建")
+	}
+	if rules.ShouldTrigger("gen.go") {
+		t.Error("exclude_file中的文件不应该触发构建")
+	}
+	if !rules.ShouldTrigger("app.go") {
+		t.Error("带点号的扩展名也应该匹配")
+	}
+}
+
+// TestInvalidRegex 测试无效的正则表达式
+func TestInvalidRegex(t *testing.T) {
+	rules := &Rules{ExcludeRegex: []string{"("}}
+	if err := rules.Compile(); err == nil {
+		t.Error("无效正则应该返回错误")
+	}
+}
@@ -0,0 +1,211 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Op 文件变更类型
+type Op uint32
+
+// 文件变更类型
+const (
+	Create Op = 1 << iota
+	Write
+	Remove
+	Rename
+)
+
+// String 返回变更类型的可读名称
+func (op Op) String() string {
+	var names []string
+	if op&Create != 0 {
+		names = append(names, "CREATE")
+	}
+	if op&Write != 0 {
+		names = append(names, "WRITE")
+	}
+	if op&Remove != 0 {
+		names = append(names, "REMOVE")
+	}
+	if op&Rename != 0 {
+		names = append(names, "RENAME")
+	}
+	if len(names) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.Join(names, "|")
+}
+
+// Event 文件变更事件
+type Event struct {
+	Path string
+	Op   Op
+}
+
+// 监听模式
+const (
+	ModeInotify = "inotify"
+	ModePoll    = "poll"
+)
+
+// DefaultPollInterval 默认轮询间隔
+const DefaultPollInterval = 500 * time.Millisecond
+
+// backend 底层监听实现
+type backend interface {
+	close() error
+}
+
+// Watcher 递归目录监听器
+type Watcher struct {
+	root   string
+	rules  *Rules
+	mode   string
+	events chan Event
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+	impl   backend
+}
+
+// New 创建目录监听器，优先使用inotify，不可用时回退到轮询
+func New(root string, rules *Rules) (*Watcher, error) {
+	w, err := newWatcher(root, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	impl, err := newInotify(w)
+	if err != nil {
+		w.impl = newPoller(w, DefaultPollInterval)
+		w.mode = ModePoll
+		return w, nil
+	}
+	w.impl = impl
+	w.mode = ModeInotify
+	return w, nil
+}
+
+// NewPolling 创建基于轮询的目录监听器
+func NewPolling(root string, rules *Rules, interval time.Duration) (*Watcher, error) {
+	w, err := newWatcher(root, rules)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	w.impl = newPoller(w, interval)
+	w.mode = ModePoll
+	return w, nil
+}
+
+func newWatcher(root string, rules *Rules) (*Watcher, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("解析监听目录失败: %v", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("监听目录不可用: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s 不是目录", abs)
+	}
+
+	if rules == nil {
+		rules = DefaultRules()
+	}
+	if err := rules.Compile(); err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		root:   abs,
+		rules:  rules,
+		events: make(chan Event, 64),
+		errors: make(chan error, 8),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Events 返回文件变更事件通道
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Errors 返回监听错误通道
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Mode 返回当前监听模式（inotify或poll）
+func (w *Watcher) Mode() string {
+	return w.mode
+}
+
+// Root 返回监听根目录
+func (w *Watcher) Root() string {
+	return w.root
+}
+
+// Close 停止监听
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.impl.close()
+	})
+	return err
+}
+
+// rel 返回相对监听根目录的路径
+func (w *Watcher) rel(path string) string {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// emit 发送符合规则的文件事件
+func (w *Watcher) emit(path string, op Op) {
+	if !w.rules.ShouldTrigger(w.rel(path)) {
+		return
+	}
+	select {
+	case w.events <- Event{Path: path, Op: op}:
+	case <-w.done:
+	}
+}
+
+// fail 发送监听错误，通道满时丢弃
+func (w *Watcher) fail(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+// walkDirs 遍历需要监听的目录
+func (w *Watcher) walkDirs(start string, fn func(dir string) error) error {
+	return filepath.Walk(start, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// 遍历过程中目录被删除等情况直接忽略
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if !w.rules.ShouldWatchDir(w.rel(path)) {
+			return filepath.SkipDir
+		}
+		return fn(path)
+	})
+}
@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitEvent 等待指定文件的事件
+func waitEvent(t *testing.T, w *Watcher, path string) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-w.Events():
+			if ev.Path == path {
+				return ev
+			}
+		case err := <-w.Errors():
+			t.Fatalf("监听出错: %v", err)
+		case <-timeout:
+			t.Fatalf("等待 %s 的事件超时", path)
+		}
+	}
+}
+
+// expectNoEvent 确认一段时间内没有事件
+func expectNoEvent(t *testing.T, w *Watcher, wait time.Duration) {
+	t.Helper()
+	select {
+	case ev := <-w.Events():
+		t.Errorf("不应该收到事件: %s %s", ev.Op, ev.Path)
+	case <-time.After(wait):
+	}
+}
+
+// TestNewWatcherMode 测试监听模式选择
+func TestNewWatcherMode(t *testing.T) {
+	w, err := New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("创建监听器失败: %v", err)
+	}
+	defer w.Close()
+
+	if runtime.GOOS == "linux" && w.Mode() != ModeInotify {
+		t.Errorf("Linux下应该使用inotify, 实际得到 %s", w.Mode())
+	}
+}
+
+// TestNewWatcherInvalidRoot 测试无效的监听目录
+func TestNewWatcherInvalidRoot(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing"), nil); err == nil {
+		t.Error("不存在的目录应该返回错误")
+	}
+
+	file := filepath.Join(t.TempDir(), "file.go")
+	os.WriteFile(file, []byte("package main"), 0644)
+	if _, err := New(file, nil); err == nil {
+		t.Error("文件路径应该返回错误")
+	}
+}
+
+// TestWatcherEvents 测试两种监听模式的事件
+func TestWatcherEvents(t *testing.T) {
+	constructors := map[string]func(string) (*Watcher, error){
+		ModeInotify: func(root string) (*Watcher, error) { return New(root, nil) },
+		ModePoll: func(root string) (*Watcher, error) {
+			return NewPolling(root, nil, 50*time.Millisecond)
+		},
+	}
+
+	for mode, newFn := range constructors {
+		t.Run(mode, func(t *testing.T) {
+			root := t.TempDir()
+			w, err := newFn(root)
+			if err != nil {
+				t.Fatalf("创建监听器失败: %v", err)
+			}
+			defer w.Close()
+
+			// 新建文件
+			mainFile := filepath.Join(root, "main.go")
+			os.WriteFile(mainFile, []byte("package main"), 0644)
+			waitEvent(t, w, mainFile)
+
+			// 新建子目录中的文件
+			subDir := filepath.Join(root, "handlers")
+			os.MkdirAll(subDir, 0755)
+			subFile := filepath.Join(subDir, "user.go")
+			os.WriteFile(subFile, []byte("package handlers"), 0644)
+			waitEvent(t, w, subFile)
+
+			// 删除文件
+			os.Remove(mainFile)
+			for {
+				if ev := waitEvent(t, w, mainFile); ev.Op&Remove != 0 {
+					break
+				}
+			}
+		})
+	}
+}
+
+// TestWatcherIgnoresExcluded 测试排除的文件不触发事件
+func TestWatcherIgnoresExcluded(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "tmp"), 0755)
+
+	w, err := NewPolling(root, nil, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("创建监听器失败: %v", err)
+	}
+	defer w.Close()
+
+	os.WriteFile(filepath.Join(root, "tmp", "main"), []byte("binary"), 0755)
+	os.WriteFile(filepath.Join(root, "main_test.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(root, "notes.md"), []byte("# notes"), 0644)
+
+	expectNoEvent(t, w, 300*time.Millisecond)
+}
+
+// TestOpString 测试变更类型名称
+func TestOpString(t *testing.T) {
+	if Create.String() != "CREATE" {
+		t.Errorf("期望 CREATE, 实际得到 %s", Create.String())
+	}
+	if (Write | Remove).String() != "WRITE|REMOVE" {
+		t.Errorf("期望 WRITE|REMOVE, 实际得到 %s", (Write | Remove).String())
+	}
+	if Op(0).String() != "UNKNOWN" {
+		t.Errorf("期望 UNKNOWN, 实际得到 %s", Op(0).String())
+	}
+}